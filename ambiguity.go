@@ -0,0 +1,196 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// VerticalAmbiguity means that two alternatives of the same variable derive the same string.
+	VerticalAmbiguity AmbiguityKind = iota
+	// HorizontalAmbiguity means that the string derived by a production can be split in two ways between the symbols
+	// before and after a position of the production.
+	HorizontalAmbiguity
+)
+
+// concatBounded returns all concatenations of a string of a and one of b that are at most maxLen bytes long.
+func concatBounded(a, b map[string]struct{}, maxLen int) map[string]struct{} {
+	c := make(map[string]struct{})
+	for x := range a {
+		for y := range b {
+			if len(x)+len(y) <= maxLen {
+				c[x+y] = struct{}{}
+			}
+		}
+	}
+	return c
+}
+
+// horizontal searches the shortest string xay with x, xa in the left and ay, y in the right language.
+func horizontal(left, right map[string]struct{}, maxLen int) (Ambiguity, bool) {
+	var found bool
+	var best Ambiguity
+	for x := range left {
+		for xa := range left {
+			if len(xa) <= len(x) || xa[:len(x)] != x {
+				continue
+			}
+			a := xa[len(x):]
+			for y := range right {
+				if len(xa)+len(y) > maxLen {
+					continue
+				}
+				if _, ok := right[a+y]; !ok {
+					continue
+				}
+				candidate := Ambiguity{Kind: HorizontalAmbiguity, Cuts: [2]int{len(x), len(xa)}, Example: xa + y}
+				if !found || less(candidate, best) {
+					found, best = true, candidate
+				}
+			}
+		}
+	}
+	return best, found
+}
+
+// languageOf returns the strings of at most maxLen bytes derived by the symbols, given those of the variables. A
+// category only derives its first rune.
+func languageOf(beta []Beta, languages map[Variable]map[string]struct{}, maxLen int) map[string]struct{} {
+	l := map[string]struct{}{"": {}}
+	for _, b := range beta {
+		switch b := b.(type) {
+		case Terminal:
+			l = concatBounded(l, map[string]struct{}{string(b): {}}, maxLen)
+		case Category:
+			l = concatBounded(l, map[string]struct{}{string(newCategory(b).nth(0)): {}}, maxLen)
+		case Variable:
+			l = concatBounded(l, languages[b], maxLen)
+		}
+	}
+	return l
+}
+
+// less orders horizontal ambiguities by the length of their example, the example itself and the cuts.
+func less(a, b Ambiguity) bool {
+	if len(a.Example) != len(b.Example) {
+		return len(a.Example) < len(b.Example)
+	}
+	if a.Example != b.Example {
+		return a.Example < b.Example
+	}
+	return a.Cuts[0] < b.Cuts[0]
+}
+
+// shortest returns the shortest string, the first in lexical order if there are multiple.
+func shortest(ss []string) string {
+	sort.Slice(ss, func(i, j int) bool {
+		if len(ss[i]) != len(ss[j]) {
+			return len(ss[i]) < len(ss[j])
+		}
+		return ss[i] < ss[j]
+	})
+	return ss[0]
+}
+
+// Ambiguity is a production (or a pair of alternatives) that causes ambiguity, together with the shortest string that
+// exhibits it.
+type Ambiguity struct {
+	Kind AmbiguityKind
+	// Productions are the two alternatives of a vertical ambiguity, or the production of a horizontal ambiguity twice.
+	Productions [2]Production
+	// Split is the position in the production of a horizontal ambiguity, the symbols before it derive both prefixes of
+	// the example (see Cuts) and the symbols after it the remaining suffixes.
+	Split int
+	// Cuts are the two byte offsets at which the example is split by a horizontal ambiguity.
+	Cuts    [2]int
+	Example string
+}
+
+func (a Ambiguity) String() string {
+	if a.Kind == VerticalAmbiguity {
+		return fmt.Sprintf(
+			"vertical ambiguity: %s and %s both derive %q",
+			a.Productions[0].describe(), a.Productions[1].describe(), a.Example,
+		)
+	}
+	return fmt.Sprintf(
+		"horizontal ambiguity: %s at %d derives %q as %q|%q and %q|%q",
+		a.Productions[0].describe(), a.Split, a.Example,
+		a.Example[:a.Cuts[0]], a.Example[a.Cuts[0]:], a.Example[:a.Cuts[1]], a.Example[a.Cuts[1]:],
+	)
+}
+
+// AmbiguityKind distinguishes vertical from horizontal ambiguities.
+type AmbiguityKind int
+
+// AmbiguityReport localizes the sources of ambiguity like the ambiguity checker of Brabrand, Giegerich and Møller:
+// two alternatives of a variable are vertically ambiguous if their languages overlap, and a production `A → αβ` is
+// horizontally ambiguous if a string of αβ can be split in two ways, i.e. there are x, xa in L(α) and ay, y in L(β)
+// for a non-empty a. Instead of an approximation, the languages are enumerated up to strings of maxLen bytes, so every
+// reported ambiguity is real (of the productions, not necessarily of the whole grammar, since the context of the
+// variable is not considered), but ambiguities only visible in longer strings are missed. The report is ordered by the
+// variables and productions of the grammar, only variables that are reachable from the start variable are checked.
+func (g *CFG) AmbiguityReport(maxLen int) []Ambiguity {
+	languages := g.boundedLanguages(maxLen)
+	language := func(beta []Beta) map[string]struct{} {
+		return languageOf(beta, languages, maxLen)
+	}
+
+	var report []Ambiguity
+	reachable := g.reachable()
+	for _, v := range g.Variables {
+		if _, ok := reachable[v]; !ok {
+			continue
+		}
+		rules := g.RulesFor(v)
+		for i := range rules {
+			li := language(rules[i].B)
+			for j := i + 1; j < len(rules); j++ {
+				var overlap []string
+				for s := range language(rules[j].B) {
+					if _, ok := li[s]; ok {
+						overlap = append(overlap, s)
+					}
+				}
+				if len(overlap) != 0 {
+					report = append(report, Ambiguity{
+						Kind:        VerticalAmbiguity,
+						Productions: [2]Production{rules[i], rules[j]},
+						Example:     shortest(overlap),
+					})
+				}
+			}
+		}
+		for _, rule := range rules {
+			for split := 1; split < len(rule.B); split++ {
+				if a, ok := horizontal(language(rule.B[:split]), language(rule.B[split:]), maxLen); ok {
+					a.Productions = [2]Production{rule, rule}
+					a.Split = split
+					report = append(report, a)
+				}
+			}
+		}
+	}
+	return report
+}
+
+// boundedLanguages returns the strings of at most maxLen bytes derived by every variable.
+func (g *CFG) boundedLanguages(maxLen int) map[Variable]map[string]struct{} {
+	languages := make(map[Variable]map[string]struct{})
+	for _, v := range g.Variables {
+		languages[v] = make(map[string]struct{})
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			for s := range languageOf(rule.B, languages, maxLen) {
+				if _, ok := languages[a][s]; !ok {
+					languages[a][s] = struct{}{}
+					changed = true
+				}
+			}
+		}
+	}
+	return languages
+}
@@ -0,0 +1,47 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_AmbiguityReport() {
+	g, _ := cfg.Parse(`
+		S → SpS | A | B
+		A → a
+		B → a
+	`)
+	for _, a := range g.AmbiguityReport(5) {
+		fmt.Println(a)
+	}
+	// Output:
+	// vertical ambiguity: S → A (line 2) and S → B (line 2) both derive "a"
+	// horizontal ambiguity: S → SpS (line 2) at 1 derives "apapa" as "a"|"papa" and "apa"|"pa"
+	// horizontal ambiguity: S → SpS (line 2) at 2 derives "apapa" as "ap"|"apa" and "apap"|"a"
+}
+
+func TestCFG_AmbiguityReport(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		kinds   []cfg.AmbiguityKind
+	}{
+		{"S → aSb | ε\n", nil},
+		{"S → aS | ε\n", nil},
+		{"S → SS | a\n", []cfg.AmbiguityKind{cfg.HorizontalAmbiguity}},
+		{"S → aS | Sa | ε\n", []cfg.AmbiguityKind{cfg.VerticalAmbiguity}},
+		{"S → AB\nA → a | ab\nB → b | ε\n", []cfg.AmbiguityKind{cfg.HorizontalAmbiguity}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var kinds []cfg.AmbiguityKind
+		for _, a := range g.AmbiguityReport(6) {
+			kinds = append(kinds, a.Kind)
+		}
+		if fmt.Sprint(kinds) != fmt.Sprint(test.kinds) {
+			t.Errorf("%q: expected %v, got %v", test.grammar, test.kinds, kinds)
+		}
+	}
+}
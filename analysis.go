@@ -0,0 +1,376 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EndOfInput marks the end of the input in FOLLOW sets.
+const EndOfInput = Terminal("$")
+
+func sortedTerminals(m map[Terminal]struct{}) Alphabet {
+	a := make(Alphabet, 0, len(m))
+	for t := range m {
+		a = append(a, t)
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i] < a[j] })
+	return a
+}
+
+// unitClosure computes for every variable all variables that can be derived using only unit productions, starting
+// with the variable itself.
+func unitClosure(rules R) map[Variable]V {
+	units := make(map[Variable]V)
+	for _, rule := range rules {
+		if isUnit(rule) {
+			a := rule.A.(Variable)
+			units[a] = append(units[a], rule.B[0].(Variable))
+		}
+	}
+	closure := make(map[Variable]V)
+	for _, rule := range rules {
+		a := rule.A.(Variable)
+		if _, ok := closure[a]; ok {
+			continue
+		}
+		seen := map[Variable]bool{a: true}
+		vs := V{a}
+		for i := 0; i < len(vs); i++ {
+			for _, b := range units[vs[i]] {
+				if !seen[b] {
+					seen[b] = true
+					vs = append(vs, b)
+				}
+			}
+		}
+		closure[a] = vs
+	}
+	return closure
+}
+
+// SCC is a strongly connected component of the variables, every variable of it derives a sentential form containing
+// every other one.
+type SCC struct {
+	Variables V
+	// Recursive is true if the variables derive themselves, i.e. the component has more than one variable or a variable
+	// references itself.
+	Recursive bool
+}
+
+// Warning is a non-fatal issue found in a grammar. The position is the one of the offending production, or of the
+// first production of the variable.
+type Warning struct {
+	Variable Variable
+	Position Position
+	Message  string
+}
+
+func (w Warning) String() string {
+	if w.Position.Line == 0 {
+		return fmt.Sprintf("%s: %s", w.Variable, w.Message)
+	}
+	return fmt.Sprintf("%s (line %d): %s", w.Variable, w.Position.Line, w.Message)
+}
+
+// AcceptsEmpty returns true if the language of the grammar contains the empty string, i.e. if the start variable is
+// nullable. Unlike Evaluate it does not depend on the order of the rules or the limits of the search.
+func (g *CFG) AcceptsEmpty() bool {
+	return g.nullable()[g.StartVariable]
+}
+
+// Derives returns true if a sentential form derived from a in one or more steps can contain b, e.g. whether an
+// expression can ever contain a statement. Whether the other symbols of the form derive any string is not checked.
+func (g *CFG) Derives(a, b Variable) bool {
+	_, ok := g.DerivesWitness(a, b)
+	return ok
+}
+
+// DerivesWitness returns a shortest chain of productions that shows that a derives b: the first production is one of
+// a, every following production is one of a variable of the previous production, and the last one contains b.
+func (g *CFG) DerivesWitness(a, b Variable) (R, bool) {
+	// The production through which a variable was first reached.
+	parent := make(map[Variable]Production)
+	queue := []Variable{a}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, rule := range g.RulesFor(v) {
+			for _, beta := range rule.B {
+				w, ok := beta.(Variable)
+				if !ok {
+					continue
+				}
+				if _, ok := parent[w]; ok {
+					continue
+				}
+				parent[w] = rule
+				if w == b {
+					var chain R
+					for p := rule; ; p = parent[p.A.(Variable)] {
+						chain = append(R{p}, chain...)
+						if p.A == a {
+							return chain, true
+						}
+					}
+				}
+				queue = append(queue, w)
+			}
+		}
+	}
+	return nil, false
+}
+
+// First computes the FIRST set of every variable. Since ε is not a terminal, the sets do not contain it, the variables
+// that derive ε are returned by Nullable.
+func (g *CFG) First() map[Variable]Alphabet {
+	if g.tables != nil {
+		return copySets(g.tables.First)
+	}
+	t := g.symbols
+	first := t.first(t.nullable())
+	m := make(map[Variable]Alphabet)
+	for _, v := range g.Variables {
+		m[v] = t.alphabet(first[t.variable[v]], false, "")
+	}
+	return m
+}
+
+// FirstOf computes the FIRST set of a sequence of symbols, and whether the whole sequence is nullable.
+func (g *CFG) FirstOf(beta []Beta) (Alphabet, bool) {
+	first, nullable := g.firstOf(beta, g.first(), g.nullable())
+	return sortedTerminals(first), nullable
+}
+
+// Follow computes the FOLLOW set of every variable. The FOLLOW set of the start variable contains EndOfInput.
+func (g *CFG) Follow() map[Variable]Alphabet {
+	if g.tables != nil {
+		return copySets(g.tables.Follow)
+	}
+	t := g.symbols
+	nullable := t.nullable()
+	follow := t.follow(t.first(nullable), nullable)
+	m := make(map[Variable]Alphabet)
+	for _, v := range g.Variables {
+		i := t.variable[v]
+		m[v] = t.alphabet(follow[i], follow[i].has(len(t.terminals)), EndOfInput)
+	}
+	return m
+}
+
+// Lint reports unreachable variables, variables that can not derive a string of terminals, and duplicate productions.
+// The warnings are sorted by variable.
+func (g *CFG) Lint() []Warning {
+	var warnings []Warning
+	reachable := g.reachable()
+	productive := g.productive()
+	for _, v := range g.Variables {
+		var pos Position
+		if rules := g.RulesFor(v); len(rules) != 0 {
+			pos = rules[0].Position
+		}
+		if _, ok := reachable[v]; !ok {
+			warnings = append(warnings, Warning{Variable: v, Position: pos, Message: "unreachable from the start variable"})
+		}
+		if _, ok := productive[v]; !ok {
+			warnings = append(warnings, Warning{Variable: v, Position: pos, Message: "does not derive any string of terminals"})
+		}
+	}
+	seen := make(map[string]Production)
+	for _, rule := range g.Rules {
+		if first, ok := seen[rule.key()]; ok {
+			warnings = append(warnings, Warning{
+				Variable: rule.A.(Variable),
+				Position: rule.Position,
+				Message:  fmt.Sprintf("duplicate of %s", first.describe()),
+			})
+		} else {
+			seen[rule.key()] = rule
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].Variable < warnings[j].Variable })
+	return warnings
+}
+
+// Nullable returns all variables that can derive the empty string.
+func (g *CFG) Nullable() V {
+	if g.tables != nil {
+		return append(V(nil), g.tables.Nullable...)
+	}
+	nullable := g.nullable()
+	var vs V
+	for _, v := range g.Variables {
+		if nullable[v] {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// RulesProducing returns the productions whose right-hand side contains the symbol, in the order in which they were
+// defined. It is an index built with the grammar, for bottom-up algorithms and refactorings.
+func (g *CFG) RulesProducing(sym Beta) []Production {
+	var rules []Production
+	for _, i := range g.symbols.producing[sym] {
+		rules = append(rules, g.Rules[i])
+	}
+	return rules
+}
+
+// SCCs returns the strongly connected components of the graph in which every variable references the variables of its
+// productions, in the order of TopoOrder: a component only references itself and the components before it.
+func (g *CFG) SCCs() []SCC {
+	var components []SCC
+	for _, c := range g.sccs() {
+		recursive := 1 < len(c)
+		if !recursive {
+			for _, rule := range g.RulesFor(c[0]) {
+				if containsBeta(rule.B, c[0]) {
+					recursive = true
+				}
+			}
+		}
+		components = append(components, SCC{Variables: c, Recursive: recursive})
+	}
+	return components
+}
+
+// TopoOrder returns the variables ordered by their dependencies: a variable comes after all variables it derives,
+// except for mutually recursive variables, which are grouped together. The order only depends on the order of the
+// variables and rules, so it is deterministic.
+func (g *CFG) TopoOrder() V {
+	var order V
+	for _, c := range g.sccs() {
+		order = append(order, c...)
+	}
+	return order
+}
+
+// UnitClosure returns for every variable the variables it can derive using only unit productions (`A → B`). Every
+// variable is part of its own closure.
+func (g *CFG) UnitClosure() map[Variable]V {
+	closure := unitClosure(g.Rules)
+	for _, v := range g.Variables {
+		if _, ok := closure[v]; !ok {
+			closure[v] = V{v}
+		}
+	}
+	return closure
+}
+
+// UsedTerminals returns the terminals of the alphabet that occur in a production of a variable that is reachable from
+// the start variable, in the order of the alphabet.
+func (g *CFG) UsedTerminals() Alphabet {
+	used := make(map[Terminal]bool)
+	for v := range g.reachable() {
+		for _, rule := range g.mappedRules[v] {
+			for _, b := range rule.B {
+				if t, ok := b.(Terminal); ok {
+					used[t] = true
+				}
+			}
+		}
+	}
+	var a Alphabet
+	for _, t := range g.Alphabet {
+		if used[t] {
+			a = append(a, t)
+		}
+	}
+	return a
+}
+
+func (g *CFG) first() map[Variable]map[Terminal]struct{} {
+	t := g.symbols
+	nullable := t.nullable()
+	first := make(map[Variable]map[Terminal]struct{})
+	for v, f := range t.first(nullable) {
+		ts := make(map[Terminal]struct{})
+		for _, a := range t.alphabet(f, false, "") {
+			ts[a] = struct{}{}
+		}
+		first[t.variables[v]] = ts
+	}
+	return first
+}
+
+// firstOf returns the FIRST set of the symbols, and whether they are nullable.
+func (g *CFG) firstOf(beta []Beta, first map[Variable]map[Terminal]struct{}, nullable map[Variable]bool) (map[Terminal]struct{}, bool) {
+	ts := make(map[Terminal]struct{})
+	for _, b := range beta {
+		switch b := b.(type) {
+		case Terminal:
+			ts[b] = struct{}{}
+			return ts, false
+		case Category:
+			// A category is the terminal `\p{L}` in sets of terminals, see symbolTable.name.
+			ts[Terminal(b.String())] = struct{}{}
+			return ts, false
+		case Variable:
+			for t := range first[b] {
+				ts[t] = struct{}{}
+			}
+			if !nullable[b] {
+				return ts, false
+			}
+		}
+	}
+	return ts, true
+}
+
+func (g *CFG) nullable() map[Variable]bool {
+	t := g.symbols
+	nullable := make(map[Variable]bool)
+	set := t.nullable()
+	for i, v := range t.variables {
+		if set.has(i) {
+			nullable[v] = true
+		}
+	}
+	return nullable
+}
+
+func (g *CFG) productive() map[Variable]struct{} {
+	productive := make(map[Variable]struct{})
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			if _, ok := productive[a]; ok {
+				continue
+			}
+			ok := true
+			for _, b := range rule.B {
+				if v, isVariable := b.(Variable); isVariable {
+					if _, p := productive[v]; !p {
+						ok = false
+					}
+				}
+			}
+			if ok {
+				productive[a] = struct{}{}
+				changed = true
+			}
+		}
+	}
+	return productive
+}
+
+func (g *CFG) reachable() map[Variable]struct{} {
+	reachable := map[Variable]struct{}{g.StartVariable: {}}
+	queue := []Variable{g.StartVariable}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, rule := range g.mappedRules[v] {
+			for _, b := range rule.B {
+				if b, ok := b.(Variable); ok {
+					if _, ok := reachable[b]; !ok {
+						reachable[b] = struct{}{}
+						queue = append(queue, b)
+					}
+				}
+			}
+		}
+	}
+	return reachable
+}
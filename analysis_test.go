@@ -0,0 +1,200 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_First() {
+	g, _ := cfg.Parse(`
+		S → AB | c
+		A → aA | ε
+		B → b
+	`)
+	first := g.First()
+	follow := g.Follow()
+	for _, v := range []cfg.Variable{"S", "A", "B"} {
+		fmt.Println(v, first[v], follow[v])
+	}
+	// Output:
+	// S [a b c] [$]
+	// A [a] [b]
+	// B [b] [$]
+}
+
+func TestCFG_AcceptsEmpty(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		expected bool
+	}{
+		{"S → AB\nA → ε\nB → A | b\n", true},
+		{"S → aSb | ε\n", true},
+		{"S → aSb | ab\n", false},
+		{"S → A\nA → ε | A\n", true},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok := g.AcceptsEmpty(); ok != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.grammar, test.expected, ok)
+		}
+	}
+}
+
+func TestCFG_First_large(t *testing.T) {
+	// Vi → Vi+1 ti | Vi+1, Vn-1 → tn-1 | ε: FIRST(Vi) = {ti, ..., tn-1}, FOLLOW(Vi) = {t0, ..., ti-1, $}.
+	const n = 2000
+	var (
+		variables cfg.V
+		alphabet  cfg.Alphabet
+		rules     cfg.R
+	)
+	for i := 0; i < n; i++ {
+		variables = append(variables, cfg.Variable(fmt.Sprintf("V%d", i)))
+		alphabet = append(alphabet, cfg.Terminal(fmt.Sprintf("t%d", i)))
+	}
+	for i := 0; i < n-1; i++ {
+		rules = append(rules,
+			cfg.NewProduction(variables[i], []cfg.Beta{variables[i+1], alphabet[i]}),
+			cfg.NewProduction(variables[i], []cfg.Beta{variables[i+1]}),
+		)
+	}
+	rules = append(rules,
+		cfg.NewProduction(variables[n-1], []cfg.Beta{alphabet[n-1]}),
+		cfg.NewProduction(variables[n-1], []cfg.Beta{cfg.Epsilon}),
+	)
+	g, err := cfg.New(variables, alphabet, rules, variables[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := g.First()
+	follow := g.Follow()
+	for _, i := range []int{0, 1, n / 2, n - 1} {
+		v := variables[i]
+		if l := len(first[v]); l != n-i {
+			t.Errorf("%s: expected FIRST set of size %d, got %d", v, n-i, l)
+		}
+		if l := len(follow[v]); l != i+1 {
+			t.Errorf("%s: expected FOLLOW set of size %d, got %d", v, i+1, l)
+		}
+	}
+	if l := len(g.Nullable()); l != n {
+		t.Errorf("expected %d nullable variables, got %d", n, l)
+	}
+}
+
+func TestCFG_Lint(t *testing.T) {
+	g, err := cfg.Parse("S → a\nS → a\nA → b\nB → B\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings []string
+	for _, w := range g.Lint() {
+		warnings = append(warnings, w.String())
+	}
+	expected := fmt.Sprint([]string{
+		"A (line 3): unreachable from the start variable",
+		"B (line 4): unreachable from the start variable",
+		"B (line 4): does not derive any string of terminals",
+		"S (line 2): duplicate of S → a (line 1)",
+	})
+	if fmt.Sprint(warnings) != expected {
+		t.Errorf("expected %v, got %v", expected, warnings)
+	}
+}
+
+func TestCFG_Nullable(t *testing.T) {
+	g, err := cfg.Parse("S → AB\nA → ε\nB → A | b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := g.Nullable(); len(n) != 3 {
+		t.Errorf("expected S, A and B to be nullable, got %v", n)
+	}
+}
+
+func ExampleCFG_UnitClosure() {
+	g, _ := cfg.Parse("S → A | a\nA → B\nB → A | b\n")
+	closure := g.UnitClosure()
+	for _, v := range g.Variables {
+		fmt.Println(v, closure[v])
+	}
+	// Output:
+	// S [S A B]
+	// A [A B]
+	// B [B A]
+}
+
+func ExampleCFG_DerivesWitness() {
+	g, _ := cfg.Parse(`
+		S → aE | bT
+		E → (T) | x
+		T → SS | ε
+	`)
+	fmt.Println(g.Derives("E", "S"), g.Derives("T", "T"), g.Derives("E", "E"))
+	fmt.Println(g.DerivesWitness("E", "S"))
+	fmt.Println(g.DerivesWitness("T", "T"))
+	// Output:
+	// true true true
+	// E → (T), T → SS true
+	// T → SS, S → bT true
+}
+
+func ExampleCFG_UsedTerminals() {
+	S, A := cfg.Variable("S"), cfg.Variable("A")
+	g, _ := cfg.New(cfg.V{S, A}, cfg.Alphabet{"a", "b", "c"}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{cfg.Terminal("a"), S}),
+		cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+		cfg.NewProduction(A, []cfg.Beta{cfg.Terminal("b")}),
+	}, S)
+	fmt.Println(g.UsedTerminals())
+	// Output:
+	// [a]
+}
+
+func ExampleCFG_RulesProducing() {
+	g, _ := cfg.Parse(`
+		S → AB | aA
+		A → aA | ε
+		B → b
+	`)
+	fmt.Println(g.RulesProducing(cfg.Variable("A")))
+	fmt.Println(g.RulesProducing(cfg.Terminal("a")))
+	fmt.Println(g.RulesProducing(cfg.Epsilon))
+	fmt.Println(g.RulesProducing(cfg.Variable("S")))
+	// Output:
+	// [S → AB S → aA A → aA]
+	// [S → aA A → aA]
+	// [A → ε]
+	// []
+}
+
+func ExampleCFG_TopoOrder() {
+	g, _ := cfg.Parse(`
+		S → AB | c
+		A → aB | Ca
+		B → bA | b
+		C → c
+	`)
+	fmt.Println(g.TopoOrder())
+	// Output:
+	// [C A B S]
+}
+
+func ExampleCFG_SCCs() {
+	g, _ := cfg.Parse(`
+		S → AB | Sc
+		A → aB | Ca
+		B → bA | b
+		C → c
+	`)
+	for _, c := range g.SCCs() {
+		fmt.Println(c.Variables, c.Recursive)
+	}
+	// Output:
+	// [C] false
+	// [A B] true
+	// [S] true
+}
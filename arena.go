@@ -0,0 +1,71 @@
+package cfg
+
+import "sync"
+
+// arenas are the arenas of the depth-first evaluations, they are reused between calls to Evaluate.
+var arenas = sync.Pool{
+	New: func() any {
+		return new(arena)
+	},
+}
+
+// evaluateDepthFirst searches the derivations of the string depth-first, with the sentential forms and the derivation
+// allocated in an arena of the pool. The derivation that is returned does not share memory with the arena.
+func (g *CFG) evaluateDepthFirst(s string, e *evaluation) (Path, bool) {
+	a := arenas.Get().(*arena)
+	defer func() {
+		e.arena = nil
+		a.forms = a.forms[:0]
+		arenas.Put(a)
+	}()
+	e.arena = a
+	_, p, ok := g.evaluate(s, g.symbols.form([]Beta{g.StartVariable}), 0, a.path[:0], e)
+	if !ok {
+		return nil, false
+	}
+	// The derivation may have outgrown the buffer, the larger one is kept for the next call.
+	a.path = p[:0]
+	return append(Path(nil), p...), true
+}
+
+// arena is the memory of the sentential forms and the derivation of a depth-first search. A form is only used by the
+// search of the production it was expanded from, so the forms are released in the reverse order of their allocation,
+// like a stack.
+type arena struct {
+	forms []symbol
+	// generation is incremented whenever the forms move to a larger buffer, the forms in the previous buffers stay
+	// valid until they are released.
+	generation int
+	path       Path
+}
+
+// alloc returns an empty form with the given capacity.
+func (a *arena) alloc(n int) []symbol {
+	if cap(a.forms)-len(a.forms) < n {
+		a.forms = make([]symbol, 0, 2*cap(a.forms)+n)
+		a.generation++
+	}
+	i := len(a.forms)
+	a.forms = a.forms[:i+n]
+	return a.forms[i:i:(i + n)]
+}
+
+// mark returns the position of the next form, see release.
+func (a *arena) mark() arenaMark {
+	return arenaMark{generation: a.generation, n: len(a.forms)}
+}
+
+// release releases all forms allocated since the mark.
+func (a *arena) release(m arenaMark) {
+	if m.generation != a.generation {
+		// All forms of the current buffer were allocated after the mark.
+		a.forms = a.forms[:0]
+		return
+	}
+	a.forms = a.forms[:m.n]
+}
+
+// arenaMark is a position in an arena, see arena.mark.
+type arenaMark struct {
+	generation, n int
+}
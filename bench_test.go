@@ -0,0 +1,172 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/grammars"
+	"testing"
+)
+
+// sizes are the lengths of the inputs of the benchmarks, in bytes.
+var sizes = []int{16, 64, 256}
+
+func BenchmarkCFG_CNF(b *testing.B) {
+	for _, grammar := range grammars.All() {
+		g := grammar.New()
+		b.Run(grammar.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				g.CNF()
+			}
+		})
+	}
+}
+
+func BenchmarkCFG_Evaluate(b *testing.B) {
+	for _, grammar := range grammars.All() {
+		g := grammar.New()
+		for _, n := range sizes {
+			s := grammar.Input(n)
+			b.Run(fmt.Sprintf("%s/%d", grammar.Name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if _, ok := g.Evaluate(s); !ok {
+						b.Fatalf("expected %q to be accepted", s)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkCYK_Recognize(b *testing.B) {
+	for _, grammar := range grammars.All() {
+		c, err := grammar.New().CYK()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range sizes {
+			s := grammar.Input(n)
+			b.Run(fmt.Sprintf("%s/%d", grammar.Name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if !c.Recognize(s) {
+						b.Fatalf("expected %q to be accepted", s)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkCYK_Recognize_splits is the baseline of BenchmarkCYK_Recognize: the textbook CYK algorithm, which loops
+// over all split positions of a span instead of intersecting bitsets.
+func BenchmarkCYK_Recognize_splits(b *testing.B) {
+	for _, grammar := range grammars.All() {
+		c, err := newSplitCYK(grammar.New())
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range sizes {
+			s := grammar.Input(n)
+			b.Run(fmt.Sprintf("%s/%d", grammar.Name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if !c.recognize(s) {
+						b.Fatalf("expected %q to be accepted", s)
+					}
+				}
+			})
+		}
+	}
+}
+
+// splitCYK is a CYK recognizer with a chart of booleans, see BenchmarkCYK_Recognize_splits.
+type splitCYK struct {
+	empty     bool
+	terminals map[rune][]int
+	binary    [][3]int
+	variables int
+}
+
+func newSplitCYK(g *cfg.CFG) (*splitCYK, error) {
+	split := make(map[cfg.Terminal][]cfg.Beta)
+	for _, t := range g.Alphabet {
+		if rs := []rune(string(t)); len(rs) != 1 {
+			for _, r := range rs {
+				split[t] = append(split[t], cfg.Terminal(r))
+			}
+		}
+	}
+	runes, err := g.Substitute(split)
+	if err != nil {
+		return nil, err
+	}
+	c := &splitCYK{empty: g.AcceptsEmpty(), terminals: make(map[rune][]int)}
+	indices := map[cfg.Variable]int{runes.StartVariable: 0}
+	index := func(v cfg.Variable) int {
+		if _, ok := indices[v]; !ok {
+			indices[v] = len(indices)
+		}
+		return indices[v]
+	}
+	for _, rule := range runes.CNF() {
+		a := index(rule.A.(cfg.Variable))
+		switch len(rule.B) {
+		case 1:
+			r := []rune(string(rule.B[0].(cfg.Terminal)))[0]
+			c.terminals[r] = append(c.terminals[r], a)
+		case 2:
+			c.binary = append(c.binary, [3]int{a, index(rule.B[0].(cfg.Variable)), index(rule.B[1].(cfg.Variable))})
+		}
+	}
+	c.variables = len(indices)
+	return c, nil
+}
+
+func (c *splitCYK) recognize(s string) bool {
+	rs := []rune(s)
+	n := len(rs)
+	if n == 0 {
+		return c.empty
+	}
+	// chart[i][j][v] is true if the variable v derives the span (i, j).
+	chart := make([][][]bool, n)
+	for i := range chart {
+		chart[i] = make([][]bool, n+1)
+		for j := i + 1; j <= n; j++ {
+			chart[i][j] = make([]bool, c.variables)
+		}
+		for _, a := range c.terminals[rs[i]] {
+			chart[i][i+1][a] = true
+		}
+	}
+	for length := 2; length <= n; length++ {
+		for i := 0; i+length <= n; i++ {
+			j := i + length
+			for k := i + 1; k < j; k++ {
+				for _, rule := range c.binary {
+					if chart[i][k][rule[1]] && chart[k][j][rule[2]] {
+						chart[i][j][rule[0]] = true
+					}
+				}
+			}
+		}
+	}
+	return chart[0][n][0]
+}
+
+func BenchmarkRecognizer_Accepts(b *testing.B) {
+	for _, grammar := range grammars.All() {
+		r, err := grammar.New().Compile()
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, n := range sizes {
+			s := grammar.Input(n)
+			b.Run(fmt.Sprintf("%s/%d", grammar.Name, n), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if !r.Accepts(s) {
+						b.Fatalf("expected %q to be accepted", s)
+					}
+				}
+			})
+		}
+	}
+}
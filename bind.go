@@ -0,0 +1,74 @@
+package cfg
+
+import "fmt"
+
+// Bind binds the production to a function that builds a value of type T from the values of the children of the nodes
+// to which the production is applied, see EvaluateAs. The label and position of the production are ignored.
+func Bind[T any](production Production, build func(children ...any) T) Binding {
+	return Binding{
+		production: production,
+		build: func(children ...any) any {
+			return build(children...)
+		},
+	}
+}
+
+// EvaluateAs evaluates the string and builds a value of type T from its derivation tree, bottom-up. The value of a
+// terminal is the Terminal itself, ε has no value, and the value of a variable is built by the binding of the applied
+// production. A production without a binding and with a single child has the value of that child, every other
+// production must be bound. The value of the root must be of type T.
+func EvaluateAs[T any](g *CFG, s string, bindings ...Binding) (T, error) {
+	var zero T
+	p, ok := g.Evaluate(s)
+	if !ok {
+		return zero, fmt.Errorf("%q is not accepted", s)
+	}
+	t, err := p.Tree()
+	if err != nil {
+		return zero, err
+	}
+	builders := make(map[string]func(children ...any) any)
+	for _, b := range bindings {
+		builders[b.production.key()] = b.build
+	}
+	v, err := t.build(builders)
+	if err != nil {
+		return zero, err
+	}
+	result, ok := v.(T)
+	if !ok {
+		return zero, fmt.Errorf("expected a value of type %T, got %T", zero, v)
+	}
+	return result, nil
+}
+
+// Binding is a production together with the function that builds its value, see Bind.
+type Binding struct {
+	production Production
+	build      func(children ...any) any
+}
+
+// build builds the value of the tree with the builders of the productions, see EvaluateAs.
+func (t *Tree) build(builders map[string]func(children ...any) any) (any, error) {
+	if t.Production == nil {
+		return t.Symbol, nil
+	}
+	var children []any
+	for _, c := range t.Children {
+		if c.Symbol == Epsilon {
+			continue
+		}
+		v, err := c.build(builders)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, v)
+	}
+	if build, ok := builders[t.Production.key()]; ok {
+		return build(children...), nil
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return nil, fmt.Errorf("no binding for %s", t.Production.describe())
+}
@@ -0,0 +1,49 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+type sum struct {
+	terms []string
+}
+
+func ExampleEvaluateAs() {
+	g, _ := cfg.Parse("S → TpS | T\nT → x | y\n")
+	s, err := cfg.EvaluateAs[sum](g, "xpypx",
+		cfg.Bind(g.Rules[0], func(children ...any) sum {
+			return sum{terms: append([]string{children[0].(cfg.Terminal).String()}, children[2].(sum).terms...)}
+		}),
+		cfg.Bind(g.Rules[1], func(children ...any) sum {
+			return sum{terms: []string{children[0].(cfg.Terminal).String()}}
+		}),
+	)
+	fmt.Println(s.terms, err)
+	// Output:
+	// [x y x] <nil>
+}
+
+func TestEvaluateAs(t *testing.T) {
+	g, err := cfg.Parse("S → aSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth := []cfg.Binding{
+		cfg.Bind(cfg.Prod("S → aSb"), func(children ...any) int { return children[1].(int) + 1 }),
+		cfg.Bind(cfg.Prod("S → ε"), func(children ...any) int { return 0 }),
+	}
+	if n, err := cfg.EvaluateAs[int](g, "aaabbb", depth...); err != nil || n != 3 {
+		t.Errorf("expected 3, got %d (%v)", n, err)
+	}
+	if _, err := cfg.EvaluateAs[int](g, "aab", depth...); err == nil {
+		t.Error("expected an error for a string that is not accepted")
+	}
+	if _, err := cfg.EvaluateAs[string](g, "ab", depth...); err == nil {
+		t.Error("expected an error for a value of another type")
+	}
+	if _, err := cfg.EvaluateAs[int](g, "ab", depth[0]); err == nil {
+		t.Error("expected an error for a production without binding")
+	}
+}
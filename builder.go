@@ -0,0 +1,59 @@
+package cfg
+
+import "fmt"
+
+// Alt returns a production for every alternative of the variable, e.g. `Alt(S, Seq(a, S, a), Seq(Epsilon))`.
+func Alt(v Variable, alternatives ...[]Beta) []Production {
+	ps := make([]Production, len(alternatives))
+	for i, beta := range alternatives {
+		ps[i] = NewProduction(v, beta)
+	}
+	return ps
+}
+
+// Betas converts the given symbols to a sequence of symbols. Strings are converted to terminals, symbols are kept as
+// they are. Any other type panics.
+func Betas(symbols ...any) []Beta {
+	bs := make([]Beta, len(symbols))
+	for i, s := range symbols {
+		switch s := s.(type) {
+		case Beta:
+			bs[i] = s
+		case string:
+			bs[i] = Terminal(s)
+		default:
+			panic(fmt.Sprintf("cfg: can not convert %T to a symbol", s))
+		}
+	}
+	return bs
+}
+
+// Prod parses a single production in the text format read by Parse, e.g. `Prod("S -> a S a")`. It panics if the
+// rule is invalid or has more than one alternative.
+func Prod(rule string) Production {
+	g, err := Parse(rule + "\n")
+	if err != nil {
+		panic(fmt.Sprintf("cfg: invalid production %q: %v", rule, err))
+	}
+	if len(g.Rules) != 1 {
+		panic(fmt.Sprintf("cfg: expected a single production, got %q", rule))
+	}
+	p := g.Rules[0]
+	p.Position = Position{}
+	return p
+}
+
+// Seq returns the given symbols as a sequence.
+func Seq(symbols ...Beta) []Beta {
+	return symbols
+}
+
+// T is a shorthand for Terminal(s).
+func T(s string) Terminal {
+	return Terminal(s)
+}
+
+// Vr is a shorthand for Variable(s).
+func Vr(s string) Variable {
+	return Variable(s)
+}
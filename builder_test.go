@@ -0,0 +1,59 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleBetas() {
+	S := cfg.Vr("S")
+	g, _ := cfg.New(
+		cfg.V{S},
+		cfg.Alphabet{cfg.T("a"), cfg.T("b")},
+		append(
+			cfg.Alt(S, cfg.Betas("a", S, "a"), cfg.Betas("b", S, "b")),
+			cfg.NewProduction(S, cfg.Seq(cfg.Epsilon)),
+		),
+		S,
+	)
+	fmt.Println(g)
+	// Output:
+	// ( { S }, { a, b }, [ S → aSa, S → bSb, S → ε ], S )
+}
+
+func TestBetas(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for an unsupported type")
+		}
+	}()
+	cfg.Betas("a", 1)
+}
+
+func ExampleProd() {
+	S := cfg.Vr("S")
+	g, _ := cfg.New(
+		cfg.V{S},
+		cfg.Alphabet{"a", "b"},
+		cfg.R{cfg.Prod("S -> a S a"), cfg.Prod("S → b #end")},
+		S,
+	)
+	p, ok := g.Evaluate("aba")
+	fmt.Println(p, ok)
+	// Output:
+	// [ S → aSa, S → b #end ] true
+}
+
+func TestProd(t *testing.T) {
+	for _, rule := range []string{"S -> a | b", "S a", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for %q", rule)
+				}
+			}()
+			cfg.Prod(rule)
+		}()
+	}
+}
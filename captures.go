@@ -0,0 +1,89 @@
+package cfg
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Captures returns the substrings of the string that are derived by the named symbols of the derivation, see
+// Tree.Captures.
+func (p Path) Captures(g *CFG, s string) (map[string][]string, error) {
+	t, err := p.Tree()
+	if err != nil {
+		return nil, err
+	}
+	return t.Captures(g, s)
+}
+
+// Captures returns the substrings of the string that are derived by the symbols with a capture name in the productions
+// of the tree (e.g. `body` in `S → (body:S)`), like the submatches of a regular expression. A name that is derived more
+// than once, e.g. in a recursive production, has all its substrings in the order in which they start. The tree must
+// derive the string, in fields mode (see SetFields) the substrings span the whitespace between their fields.
+func (t *Tree) Captures(g *CFG, s string) (map[string][]string, error) {
+	c := &capturing{g: g, s: s, captures: make(map[string][]string)}
+	if err := c.walk(t); err != nil {
+		return nil, err
+	}
+	c.skip()
+	if c.offset != len(s) {
+		return nil, fmt.Errorf("tree derives %q, not %q", s[:c.offset], s)
+	}
+	return c.captures, nil
+}
+
+// capturing is the state of Tree.Captures, offset is the end of the substring derived so far.
+type capturing struct {
+	g        *CFG
+	s        string
+	offset   int
+	captures map[string][]string
+}
+
+func (c *capturing) walk(t *Tree) error {
+	switch symbol := t.Symbol.(type) {
+	case Terminal, Category:
+		c.skip()
+		n, ok := c.g.symbols.match(c.s[c.offset:], symbol)
+		if !ok {
+			return fmt.Errorf("terminal %v does not match at offset %d", symbol, c.offset)
+		}
+		c.offset += n
+	case Variable:
+		if t.Production == nil {
+			return fmt.Errorf("variable %v was not expanded", symbol)
+		}
+		for i, child := range t.Children {
+			name := t.Production.capture(i)
+			if name == "" {
+				if err := c.walk(child); err != nil {
+					return err
+				}
+				continue
+			}
+			// The slot is reserved before the children, so nested captures of the same name follow it.
+			c.skip()
+			start, j := c.offset, len(c.captures[name])
+			c.captures[name] = append(c.captures[name], "")
+			if err := c.walk(child); err != nil {
+				return err
+			}
+			c.captures[name][j] = c.s[start:c.offset]
+		}
+	}
+	return nil
+}
+
+// skip skips the whitespace before a field, in fields mode.
+func (c *capturing) skip() {
+	if !c.g.fields {
+		return
+	}
+	for c.offset < len(c.s) {
+		r, n := utf8.DecodeRuneInString(c.s[c.offset:])
+		if !unicode.IsSpace(r) {
+			return
+		}
+		c.offset += n
+	}
+}
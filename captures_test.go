@@ -0,0 +1,99 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"reflect"
+	"testing"
+)
+
+func ExamplePath_Captures() {
+	g, _ := cfg.Parse("S → key:K\\=value:V\nK → aK | a\nV → bV | b\n")
+	p, _ := g.Evaluate("aa=bbb")
+	captures, _ := p.Captures(g, "aa=bbb")
+	fmt.Println(captures["key"], captures["value"])
+	// Output:
+	// [aa] [bbb]
+}
+
+func TestTree_Captures(t *testing.T) {
+	g, err := cfg.Parse("S → (body:S)S | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.Rules[0].String(); s != "S → (body:S)S" {
+		t.Errorf("unexpected production %s", s)
+	}
+	if h, err := cfg.Parse(g.Text()); err != nil || !reflect.DeepEqual(h.Rules[0].Captures, []string{"", "body", "", ""}) {
+		t.Errorf("expected the captures to survive the text format, got %v", h)
+	}
+	p, ok := g.Evaluate("(())()")
+	if !ok {
+		t.Fatal("expected (())() to be accepted")
+	}
+	captures, err := p.Captures(g, "(())()")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The captures are in the order in which they start.
+	if expected := []string{"()", "", ""}; !reflect.DeepEqual(captures["body"], expected) {
+		t.Errorf("expected %q, got %q", expected, captures["body"])
+	}
+	if _, err := p.Captures(g, "(())(("); err == nil {
+		t.Error("expected an error for a string the tree does not derive")
+	}
+
+	words, err := cfg.Parse("S → x:Ay:A\nA → aA | a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	words.SetFields(true)
+	p, ok = words.Evaluate(" a  a a ")
+	if !ok {
+		t.Fatal("expected the fields to be accepted")
+	}
+	if captures, err = p.Captures(words, " a  a a "); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(captures["x"], captures["y"]) != "[a  a] [a]" {
+		t.Errorf("unexpected captures %q", captures)
+	}
+}
+
+func TestTree_Captures_transform(t *testing.T) {
+	g, err := cfg.Parse("S → (body:B)\nB → x:A y:C z:D\nA → a | ε\nC → b | ε\nD → c | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := g.Transform(cfg.RemoveEpsilon, cfg.Binarize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input    string
+		expected map[string][]string
+	}{
+		{input: "(abc)", expected: map[string][]string{"body": {"abc"}, "x": {"a"}, "y": {"b"}, "z": {"c"}}},
+		{input: "(ac)", expected: map[string][]string{"body": {"ac"}, "x": {"a"}, "z": {"c"}}},
+		{input: "(b)", expected: map[string][]string{"body": {"b"}, "y": {"b"}}},
+	} {
+		p, ok := h.Evaluate(test.input)
+		if !ok {
+			t.Fatalf("expected %q to be accepted", test.input)
+		}
+		captures, err := p.Captures(h, test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(captures, test.expected) {
+			t.Errorf("%q: expected %q, got %q", test.input, test.expected, captures)
+		}
+	}
+	r, err := g.Reverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := r.Rules[1].String(); s != "B → z:Dy:Cx:A" {
+		t.Errorf("unexpected production %s", s)
+	}
+}
@@ -0,0 +1,94 @@
+package cfg
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Category is a symbol that matches any rune of the Unicode category with the given name, e.g. `L` or `Nd`. It is
+// written as `\p{L}`, also in the text format, and matched with unicode.Is. Its length is the length of a single rune.
+// Like Epsilon it is a symbol of its own type, so it is never confused with the terminal "\p{L}", and it is not part of
+// the alphabet.
+type Category string
+
+func (c Category) String() string {
+	return `\p{` + string(c) + `}`
+}
+
+func (Category) b() {}
+
+// valid returns an error if the category is not a Unicode category.
+func (c Category) valid() error {
+	if _, ok := unicode.Categories[string(c)]; !ok {
+		return fmt.Errorf("unknown Unicode category %q", string(c))
+	}
+	return nil
+}
+
+// newCategory returns the runes of the category, or nil if it is not a Unicode category.
+func newCategory(name Category) *category {
+	table, ok := unicode.Categories[string(name)]
+	if !ok {
+		return nil
+	}
+	c := &category{table: table}
+	for _, r := range table.R16 {
+		c.size += int(r.Hi-r.Lo)/int(r.Stride) + 1
+	}
+	for _, r := range table.R32 {
+		c.size += int(r.Hi-r.Lo)/int(r.Stride) + 1
+	}
+	// The ranges are sorted, so the first rune has the shortest encoding.
+	c.minLength = utf8.RuneLen(c.nth(0))
+	return c
+}
+
+// category is a terminal that matches the runes of a Unicode category.
+type category struct {
+	table *unicode.RangeTable
+	// size is the number of runes of the category, minLength the length of the shortest one in bytes.
+	size, minLength int
+}
+
+// match returns the length of the rune at the start of the string, if it is part of the category.
+func (c *category) match(s string) (int, bool) {
+	r, n := utf8.DecodeRuneInString(s)
+	if n == 0 || r == utf8.RuneError || !unicode.Is(c.table, r) {
+		return 0, false
+	}
+	return n, true
+}
+
+// distance returns the edit distance between a single rune of the category and the runes: all runes but one are
+// deleted, and the last one is replaced unless one of them is part of the category.
+func (c *category) distance(rs []rune) int {
+	if len(rs) == 0 {
+		return 1
+	}
+	for _, r := range rs {
+		if unicode.Is(c.table, r) {
+			return len(rs) - 1
+		}
+	}
+	return len(rs)
+}
+
+// nth returns the n-th rune of the category.
+func (c *category) nth(n int) rune {
+	for _, r := range c.table.R16 {
+		if size := int(r.Hi-r.Lo)/int(r.Stride) + 1; n < size {
+			return rune(r.Lo) + rune(n)*rune(r.Stride)
+		} else {
+			n -= size
+		}
+	}
+	for _, r := range c.table.R32 {
+		if size := int(r.Hi-r.Lo)/int(r.Stride) + 1; n < size {
+			return rune(r.Lo) + rune(n)*rune(r.Stride)
+		} else {
+			n -= size
+		}
+	}
+	return utf8.RuneError
+}
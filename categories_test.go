@@ -0,0 +1,148 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+func ExampleCategory() {
+	g, _ := cfg.Parse("S → \\p{L}I\nI → \\p{L}I | \\p{Nd}I | ε\n")
+	fmt.Println(g.Rules[0].B[0] == cfg.Category("L"))
+	for _, s := range []string{"größe2", "Ωmega", "2x"} {
+		_, ok := g.Evaluate(s)
+		fmt.Println(s, ok)
+	}
+	// Output:
+	// true
+	// größe2 true
+	// Ωmega true
+	// 2x false
+}
+
+func TestCategory(t *testing.T) {
+	if _, err := cfg.New(cfg.V{"S"}, nil, cfg.R{
+		cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Category("Xx")}),
+	}, "S"); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+	if _, err := cfg.Parse("S → \\p{Xx}\n"); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+	lu := cfg.Category("Lu")
+	g, err := cfg.New(cfg.V{"S"}, cfg.Alphabet{"-"}, cfg.R{
+		cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{lu, cfg.Terminal("-"), lu}),
+	}, "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "A-B", accepted: true},
+		{input: "Ä-Σ", accepted: true},
+		{input: "a-B", accepted: false},
+		{input: "A-", accepted: false},
+		{input: "AB-C", accepted: false},
+	} {
+		if _, ok := g.Evaluate(test.input); ok != test.accepted {
+			t.Errorf("%q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+	}
+	if ts, err := g.Tokenize("Ä-Σ"); err != nil || fmt.Sprint(ts) != "[Ä - Σ]" {
+		t.Errorf("unexpected tokens %v (%v)", ts, err)
+	}
+	gen := cfg.NewGenerator(g, 1)
+	for i := 0; i < 100; i++ {
+		s, _, err := gen.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, n := utf8.DecodeRuneInString(s)
+		b, _ := utf8.DecodeRuneInString(s[n+1:])
+		if !unicode.IsUpper(a) || s[n] != '-' || !unicode.IsUpper(b) {
+			t.Errorf("unexpected string %q", s)
+		}
+	}
+}
+
+func TestCategory_literal(t *testing.T) {
+	// The terminal `\p{L}` is not the category.
+	g, err := cfg.New(cfg.V{"S"}, cfg.Alphabet{`\p{L}`}, cfg.R{
+		cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Terminal(`\p{L}`)}),
+	}, "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Evaluate(`\p{L}`); !ok {
+		t.Error("expected the terminal to match itself")
+	}
+	if _, ok := g.Evaluate("a"); ok {
+		t.Error("expected the terminal not to match a letter")
+	}
+	if ts, err := g.Tokenize("a"); err == nil {
+		t.Errorf("unexpected tokens %v", ts)
+	}
+	if classes := g.TokenClasses(); len(classes) != 0 {
+		t.Errorf("unexpected classes %v", classes)
+	}
+	h, err := cfg.Parse("S → \\p{L}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.Evaluate(`\p{L}`); ok {
+		t.Error("expected the category not to match the text of its name")
+	}
+	if s := h.ISOEBNF(); s != "S = ? \\p{L} ? ;\n" {
+		t.Errorf("unexpected ISO EBNF %q", s)
+	}
+	if s := h.W3CEBNF(); !strings.HasPrefix(s, "S ::= [#x41-#x5A#x61-#x7A#xAA#xB5") {
+		t.Errorf("unexpected W3C EBNF %q", s)
+	}
+}
+
+func TestCategory_recognizers(t *testing.T) {
+	g, err := cfg.Parse("S → \\p{Lu}S | \\p{Lu}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := g.CYK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	re := g.RegularApprox()
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "A", accepted: true},
+		{input: "AB", accepted: true},
+		{input: "ÄΣZ", accepted: true},
+		{input: "Ab", accepted: false},
+		{input: "\\p{Lu}", accepted: false},
+		{input: "", accepted: false},
+	} {
+		if ok := c.Recognize(test.input); ok != test.accepted {
+			t.Errorf("CYK %q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+		if ok := c.RecognizeParallel(test.input, 2); ok != test.accepted {
+			t.Errorf("CYK parallel %q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+		if ok := g.EvaluateDerivatives(test.input); ok != test.accepted {
+			t.Errorf("derivatives %q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+		if ok := re.MatchString(test.input); ok != test.accepted {
+			t.Errorf("regular approximation %q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+		if ok := g.WithinEdits(test.input, 0); ok != test.accepted {
+			t.Errorf("edits %q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+	}
+	if !g.WithinEdits("Ab", 1) || g.WithinEdits("Abc", 1) {
+		t.Error("expected a distance of 1 for \"Ab\" and 2 for \"Abc\"")
+	}
+}
@@ -0,0 +1,370 @@
+// Package cfgpb encodes grammars and derivations as the protobuf messages of cfg.proto, so they can be exchanged with
+// other languages. The encoding is implemented without generated code, to keep the package free of dependencies.
+package cfgpb
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+)
+
+// MarshalCFG encodes the grammar as a CFG message.
+func MarshalCFG(g *cfg.CFG) []byte {
+	var e encoder
+	for _, v := range g.Variables {
+		e.bytes(1, []byte(v))
+	}
+	for _, t := range g.Alphabet {
+		e.bytes(2, []byte(t))
+	}
+	for _, rule := range g.Rules {
+		e.bytes(3, marshalProduction(rule))
+	}
+	e.string(4, string(g.StartVariable))
+	for _, v := range g.Variables {
+		rejects, follow := g.Filters(v)
+		for _, beta := range rejects {
+			var reject encoder
+			reject.string(1, string(v))
+			for _, b := range beta {
+				reject.bytes(2, marshalSymbol(b))
+			}
+			e.bytes(5, reject)
+		}
+		if len(follow) != 0 {
+			var restriction encoder
+			restriction.string(1, string(v))
+			for _, t := range follow {
+				restriction.bytes(2, []byte(t))
+			}
+			e.bytes(6, restriction)
+		}
+	}
+	return e
+}
+
+// MarshalPath encodes the derivation as a Path message.
+func MarshalPath(p cfg.Path) []byte {
+	var e encoder
+	for _, production := range p {
+		e.bytes(1, marshalProduction(production))
+	}
+	return e
+}
+
+// MarshalTree encodes the derivation tree as a Tree message.
+func MarshalTree(t *cfg.Tree) []byte {
+	var e encoder
+	e.bytes(1, marshalSymbol(t.Symbol))
+	if t.Production != nil {
+		e.bytes(2, marshalProduction(*t.Production))
+	}
+	for _, child := range t.Children {
+		e.bytes(3, MarshalTree(child))
+	}
+	return e
+}
+
+// UnmarshalCFG decodes a CFG message, the grammar is validated by cfg.New and its filters by cfg.CFG.Reject and
+// cfg.CFG.FollowRestriction.
+func UnmarshalCFG(b []byte) (*cfg.CFG, error) {
+	var variables cfg.V
+	var alphabet cfg.Alphabet
+	var rules cfg.R
+	var start cfg.Variable
+	var rejects []filter
+	var follow []filter
+	if err := readFields(b, func(number, wire int, _ uint64, data []byte) error {
+		switch number {
+		case 1, 2, 4:
+			s, err := readString(wire, data)
+			if err != nil {
+				return err
+			}
+			switch number {
+			case 1:
+				variables = append(variables, cfg.Variable(s))
+			case 2:
+				alphabet = append(alphabet, cfg.Terminal(s))
+			case 4:
+				start = cfg.Variable(s)
+			}
+		case 3:
+			p, err := unmarshalProduction(wire, data)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, p)
+		case 5, 6:
+			f, err := unmarshalFilter(wire, data, number == 5)
+			if err != nil {
+				return err
+			}
+			if number == 5 {
+				rejects = append(rejects, f)
+			} else {
+				follow = append(follow, f)
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	g, err := cfg.New(variables, alphabet, rules, start)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range rejects {
+		if err := g.Reject(f.variable, f.symbols); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range follow {
+		var terminals []cfg.Terminal
+		for _, b := range f.symbols {
+			terminals = append(terminals, b.(cfg.Terminal))
+		}
+		if err := g.FollowRestriction(f.variable, terminals...); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// filter is a decoded Reject or FollowRestriction message, the terminals of the latter are stored as symbols.
+type filter struct {
+	variable cfg.Variable
+	symbols  []cfg.Beta
+}
+
+// unmarshalFilter decodes a Reject message, whose symbols are Symbol messages, or a FollowRestriction message, whose
+// terminals are strings.
+func unmarshalFilter(wire int, data []byte, reject bool) (filter, error) {
+	var f filter
+	if wire != wireBytes {
+		return f, fmt.Errorf("expected a filter, got wire type %d", wire)
+	}
+	err := readFields(data, func(number, wire int, _ uint64, data []byte) error {
+		switch {
+		case number == 2 && reject:
+			if wire != wireBytes {
+				return fmt.Errorf("expected a message, got wire type %d", wire)
+			}
+			b, err := unmarshalSymbol(data)
+			if err != nil {
+				return err
+			}
+			f.symbols = append(f.symbols, b)
+		case number == 1 || number == 2:
+			s, err := readString(wire, data)
+			if err != nil {
+				return err
+			}
+			if number == 1 {
+				f.variable = cfg.Variable(s)
+			} else {
+				f.symbols = append(f.symbols, cfg.Terminal(s))
+			}
+		}
+		return nil
+	})
+	if err == nil && f.variable == "" {
+		err = fmt.Errorf("filter without a variable")
+	}
+	return f, err
+}
+
+// UnmarshalPath decodes a Path message.
+func UnmarshalPath(b []byte) (cfg.Path, error) {
+	var p cfg.Path
+	if err := readFields(b, func(number, wire int, _ uint64, data []byte) error {
+		if number != 1 {
+			return nil
+		}
+		production, err := unmarshalProduction(wire, data)
+		if err != nil {
+			return err
+		}
+		p = append(p, production)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// UnmarshalTree decodes a Tree message.
+func UnmarshalTree(b []byte) (*cfg.Tree, error) {
+	t := new(cfg.Tree)
+	if err := readFields(b, func(number, wire int, _ uint64, data []byte) error {
+		if number < 1 || 3 < number {
+			return nil
+		}
+		if wire != wireBytes {
+			return fmt.Errorf("expected a message, got wire type %d", wire)
+		}
+		switch number {
+		case 1:
+			s, err := unmarshalSymbol(data)
+			if err != nil {
+				return err
+			}
+			t.Symbol = s
+		case 2:
+			p, err := unmarshalProduction(wire, data)
+			if err != nil {
+				return err
+			}
+			t.Production = &p
+		case 3:
+			child, err := UnmarshalTree(data)
+			if err != nil {
+				return err
+			}
+			t.Children = append(t.Children, child)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	if t.Symbol == nil {
+		return nil, fmt.Errorf("tree without a symbol")
+	}
+	return t, nil
+}
+
+func marshalProduction(p cfg.Production) []byte {
+	var e encoder
+	e.string(1, p.A.String())
+	for _, b := range p.B {
+		e.bytes(2, marshalSymbol(b))
+	}
+	e.string(3, p.Label)
+	if p.Position != (cfg.Position{}) {
+		var position encoder
+		position.int32(1, p.Position.Line)
+		position.int32(2, p.Position.Column)
+		e.bytes(4, position)
+	}
+	for _, name := range p.Captures {
+		// The names are positional, so the empty ones are encoded as well.
+		e.bytes(5, []byte(name))
+	}
+	return e
+}
+
+func marshalSymbol(b cfg.Beta) []byte {
+	var e encoder
+	switch b := b.(type) {
+	case cfg.Variable:
+		e.bytes(1, []byte(b))
+	case cfg.Terminal:
+		e.bytes(2, []byte(b))
+	case cfg.Category:
+		e.bytes(4, []byte(b))
+	default:
+		if b == cfg.Epsilon {
+			e.varint(3<<3 | wireVarint)
+			e.varint(1)
+		}
+	}
+	return e
+}
+
+func unmarshalPosition(data []byte) (cfg.Position, error) {
+	var p cfg.Position
+	err := readFields(data, func(number, wire int, v uint64, _ []byte) error {
+		if number != 1 && number != 2 {
+			return nil
+		}
+		if wire != wireVarint {
+			return fmt.Errorf("expected an integer, got wire type %d", wire)
+		}
+		if number == 1 {
+			p.Line = int(int32(v))
+		} else {
+			p.Column = int(int32(v))
+		}
+		return nil
+	})
+	return p, err
+}
+
+func unmarshalProduction(wire int, data []byte) (cfg.Production, error) {
+	var p cfg.Production
+	if wire != wireBytes {
+		return p, fmt.Errorf("expected a production, got wire type %d", wire)
+	}
+	err := readFields(data, func(number, wire int, _ uint64, data []byte) error {
+		switch number {
+		case 1, 3, 5:
+			s, err := readString(wire, data)
+			if err != nil {
+				return err
+			}
+			switch number {
+			case 1:
+				p.A = cfg.Variable(s)
+			case 3:
+				p.Label = s
+			default:
+				p.Captures = append(p.Captures, s)
+			}
+		case 2, 4:
+			if wire != wireBytes {
+				return fmt.Errorf("expected a message, got wire type %d", wire)
+			}
+			if number == 2 {
+				b, err := unmarshalSymbol(data)
+				if err != nil {
+					return err
+				}
+				p.B = append(p.B, b)
+				return nil
+			}
+			position, err := unmarshalPosition(data)
+			if err != nil {
+				return err
+			}
+			p.Position = position
+		}
+		return nil
+	})
+	if err == nil && p.A == nil {
+		err = fmt.Errorf("production without a variable")
+	}
+	return p, err
+}
+
+// unmarshalSymbol decodes a Symbol message, the last member of the oneof wins.
+func unmarshalSymbol(data []byte) (cfg.Beta, error) {
+	var b cfg.Beta
+	err := readFields(data, func(number, wire int, v uint64, data []byte) error {
+		switch number {
+		case 1, 2, 4:
+			s, err := readString(wire, data)
+			if err != nil {
+				return err
+			}
+			switch number {
+			case 1:
+				b = cfg.Variable(s)
+			case 2:
+				b = cfg.Terminal(s)
+			case 4:
+				b = cfg.Category(s)
+			}
+		case 3:
+			if wire != wireVarint {
+				return fmt.Errorf("expected a bool, got wire type %d", wire)
+			}
+			if v != 0 {
+				b = cfg.Epsilon
+			}
+		}
+		return nil
+	})
+	if err == nil && b == nil {
+		err = fmt.Errorf("empty symbol")
+	}
+	return b, err
+}
@@ -0,0 +1,117 @@
+package cfgpb_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/cfgpb"
+	"reflect"
+	"testing"
+)
+
+func ExampleMarshalPath() {
+	S := cfg.Variable("S")
+	fmt.Printf("% x\n", cfgpb.MarshalPath(cfg.Path{cfg.NewProduction(S, []cfg.Beta{cfg.Terminal("a")})}))
+	// Output:
+	// 0a 08 0a 01 53 12 03 12 01 61
+}
+
+func TestMarshalCFG(t *testing.T) {
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Rules[0].Label = "a"
+	g.Rules[1].Captures = []string{"", "inner", ""}
+	h, err := cfgpb.UnmarshalCFG(cfgpb.MarshalCFG(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.Variables, h.Variables) || !reflect.DeepEqual(g.Alphabet, h.Alphabet) || !reflect.DeepEqual(g.Rules, h.Rules) || g.StartVariable != h.StartVariable {
+		t.Errorf("expected %v, got %v", g, h)
+	}
+
+	p, ok := g.Evaluate("abba")
+	if !ok {
+		t.Fatal("expected abba to be accepted")
+	}
+	q, err := cfgpb.UnmarshalPath(cfgpb.MarshalPath(p))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, q) {
+		t.Errorf("expected %v, got %v", p, q)
+	}
+
+	tree, err := p.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := cfgpb.UnmarshalTree(cfgpb.MarshalTree(tree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tree, other) {
+		t.Errorf("expected %v, got %v", tree, other)
+	}
+}
+
+func TestMarshalCFG_category(t *testing.T) {
+	g, err := cfg.Parse("S → \\p{L}S | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfgpb.UnmarshalCFG(cfgpb.MarshalCFG(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.Rules, h.Rules) {
+		t.Errorf("expected %v, got %v", g.Rules, h.Rules)
+	}
+	if _, ok := h.Evaluate("äb"); !ok {
+		t.Error("expected äb to be accepted")
+	}
+}
+
+func TestMarshalCFG_filters(t *testing.T) {
+	g, err := cfg.Parse("S → Ib | I\nI → aI | a\nK → aa\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Reject("I", []cfg.Beta{cfg.Variable("K")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.FollowRestriction("I", "b"); err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfgpb.UnmarshalCFG(cfgpb.MarshalCFG(g))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejects, follow := h.Filters("I")
+	if fmt.Sprint(rejects, follow) != "[[K]] [b]" {
+		t.Errorf("unexpected filters %v %v", rejects, follow)
+	}
+	for _, s := range []string{"aa", "ab"} {
+		if _, ok := h.Evaluate(s); ok {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestUnmarshalCFG_invalid(t *testing.T) {
+	g, err := cfg.Parse("S → a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := cfgpb.MarshalCFG(g)
+	for _, input := range [][]byte{
+		b[:len(b)-1],
+		{0x0a, 0x01, 0xff}, // Invalid UTF-8.
+		{0x0b},             // Unsupported wire type.
+		{0x22, 0x01, 0x54}, // The start variable T is not a variable.
+	} {
+		if _, err := cfgpb.UnmarshalCFG(input); err == nil {
+			t.Errorf("% x: expected an error", input)
+		}
+	}
+}
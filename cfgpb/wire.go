@@ -0,0 +1,117 @@
+package cfgpb
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// The wire types of the protobuf encoding.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errTruncated = errors.New("truncated message")
+
+// readFields calls the function for every field of the message, unknown fields are skipped by the caller. The value
+// of a varint is v, the one of a length-delimited field is data.
+func readFields(b []byte, field func(number, wire int, v uint64, data []byte) error) error {
+	for len(b) != 0 {
+		key, n := readVarint(b)
+		if n == 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		number, wire := int(key>>3), int(key&7)
+		if number == 0 {
+			return fmt.Errorf("invalid field number 0")
+		}
+		var v uint64
+		var data []byte
+		switch wire {
+		case wireVarint:
+			if v, n = readVarint(b); n == 0 {
+				return errTruncated
+			}
+		case wireFixed64:
+			if n = 8; len(b) < n {
+				return errTruncated
+			}
+		case wireBytes:
+			length, m := readVarint(b)
+			if m == 0 || uint64(len(b)-m) < length {
+				return errTruncated
+			}
+			data, n = b[m:m+int(length)], m+int(length)
+		case wireFixed32:
+			if n = 4; len(b) < n {
+				return errTruncated
+			}
+		default:
+			return fmt.Errorf("unsupported wire type %d", wire)
+		}
+		b = b[n:]
+		if err := field(number, wire, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readString checks that a length-delimited field is valid UTF-8, as required for strings.
+func readString(wire int, data []byte) (string, error) {
+	if wire != wireBytes {
+		return "", fmt.Errorf("expected a string, got wire type %d", wire)
+	}
+	if !utf8.Valid(data) {
+		return "", fmt.Errorf("invalid UTF-8 in string")
+	}
+	return string(data), nil
+}
+
+// readVarint returns the value and its length in bytes, or a length of zero if it is invalid.
+func readVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7f) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// encoder appends the fields of a message.
+type encoder []byte
+
+func (e *encoder) bytes(number int, data []byte) {
+	e.varint(uint64(number)<<3 | wireBytes)
+	e.varint(uint64(len(data)))
+	*e = append(*e, data...)
+}
+
+// int32 appends a non-zero integer, negative values are sign-extended as in protobuf.
+func (e *encoder) int32(number int, v int) {
+	if v != 0 {
+		e.varint(uint64(number)<<3 | wireVarint)
+		e.varint(uint64(int64(int32(v))))
+	}
+}
+
+// string appends a non-empty string.
+func (e *encoder) string(number int, s string) {
+	if s != "" {
+		e.bytes(number, []byte(s))
+	}
+}
+
+func (e *encoder) varint(v uint64) {
+	for 0x80 <= v {
+		*e = append(*e, byte(v)|0x80)
+		v >>= 7
+	}
+	*e = append(*e, byte(v))
+}
@@ -0,0 +1,105 @@
+// Package cfgtest provides helpers for regression tests of grammars: assertions on derivations, and comparisons of
+// derivations with golden files. The golden files are (re)written by running the tests with `-cfgtest.update`.
+package cfgtest
+
+import (
+	"flag"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("cfgtest.update", false, "update the golden files of cfgtest")
+
+// AssertDerivation checks that the grammar derives the input, and that the derivation replays as want (see
+// cfg.Path.Replay), e.g. `S → aSa → aa`.
+func AssertDerivation(t testing.TB, g *cfg.CFG, input, want string) {
+	t.Helper()
+	p, ok := g.Evaluate(input)
+	if !ok {
+		t.Errorf("%q: not derived", input)
+		return
+	}
+	if got := p.Replay(); got != want {
+		t.Errorf("%q: expected derivation\n\t%s\ngot\n\t%s", input, want, got)
+	}
+}
+
+// AssertRejected checks that the grammar does not derive the input.
+func AssertRejected(t testing.TB, g *cfg.CFG, input string) {
+	t.Helper()
+	if p, ok := g.Evaluate(input); ok {
+		t.Errorf("%q: expected to be rejected, got %s", input, p.Replay())
+	}
+}
+
+// GoldenPath compares the derivation of the input with the golden file, one production per line.
+func GoldenPath(t testing.TB, g *cfg.CFG, input, file string) {
+	t.Helper()
+	p, ok := g.Evaluate(input)
+	if !ok {
+		t.Errorf("%q: not derived", input)
+		return
+	}
+	var b strings.Builder
+	for _, production := range p {
+		fmt.Fprintln(&b, production)
+	}
+	golden(t, file, b.String())
+}
+
+// GoldenTree compares the derivation tree of the input with the golden file, one node per line and indented by its
+// depth. A variable is shown with the production that was applied to it.
+func GoldenTree(t testing.TB, g *cfg.CFG, input, file string) {
+	t.Helper()
+	p, ok := g.Evaluate(input)
+	if !ok {
+		t.Errorf("%q: not derived", input)
+		return
+	}
+	tree, err := p.Tree()
+	if err != nil {
+		t.Fatalf("%q: %v", input, err)
+		return
+	}
+	var b strings.Builder
+	writeTree(&b, tree, 0)
+	golden(t, file, b.String())
+}
+
+// golden compares the content with the golden file, or writes it if the golden files are updated.
+func golden(t testing.TB, file, got string) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatalf("%v", err)
+		}
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatalf("%v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -cfgtest.update to create it)", err)
+		return
+	}
+	if got != string(want) {
+		t.Errorf("%s: mismatch\nexpected:\n%s\ngot:\n%s", file, want, got)
+	}
+}
+
+func writeTree(b *strings.Builder, t *cfg.Tree, depth int) {
+	b.WriteString(strings.Repeat("  ", depth))
+	if t.Production != nil {
+		fmt.Fprintln(b, t.Production)
+	} else {
+		fmt.Fprintln(b, t.Symbol)
+	}
+	for _, c := range t.Children {
+		writeTree(b, c, depth+1)
+	}
+}
@@ -0,0 +1,55 @@
+package cfgtest_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/cfgtest"
+	"testing"
+)
+
+// recorder records the failures instead of failing the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func TestAssertDerivation(t *testing.T) {
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgtest.AssertDerivation(t, g, "abba", "S → aSa → abSba → abba")
+	cfgtest.AssertRejected(t, g, "ab")
+
+	r := &recorder{TB: t}
+	cfgtest.AssertDerivation(r, g, "aa", "S → aa")
+	cfgtest.AssertDerivation(r, g, "ab", "")
+	cfgtest.AssertRejected(r, g, "aa")
+	if len(r.failures) != 3 {
+		t.Errorf("expected 3 failures, got %q", r.failures)
+	}
+}
+
+func TestGoldenPath(t *testing.T) {
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfgtest.GoldenPath(t, g, "abba", "testdata/abba.path")
+	cfgtest.GoldenTree(t, g, "abba", "testdata/abba.tree")
+
+	r := &recorder{TB: t}
+	cfgtest.GoldenPath(r, g, "aa", "testdata/abba.path")
+	cfgtest.GoldenTree(r, g, "aa", "testdata/missing.tree")
+	if len(r.failures) != 2 {
+		t.Errorf("expected 2 failures, got %q", r.failures)
+	}
+}
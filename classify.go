@@ -0,0 +1,205 @@
+package cfg
+
+import "strings"
+
+// Classification describes the forms and properties of a grammar, see Classify.
+type Classification struct {
+	// Linear grammars have at most one variable in every production.
+	Linear bool
+	// RightLinear grammars only have productions of the form `A → w` and `A → wB`, with w a string of terminals.
+	RightLinear bool
+	// LeftLinear grammars only have productions of the form `A → w` and `A → Bw`, with w a string of terminals.
+	LeftLinear bool
+	// CNF grammars are in Chomsky normal form: `A → BC`, `A → a` and `S → ε`, in which case the start variable is not
+	// used in a production.
+	CNF bool
+	// GNF grammars are in Greibach normal form: `A → aB…` and `S → ε`, in which case the start variable is not used
+	// in a production.
+	GNF bool
+	// EpsilonFree grammars have no ε-productions, except for `S → ε` if the start variable is not used in a production.
+	EpsilonFree bool
+	// LL1 grammars have a predictive table without conflicts.
+	LL1 bool
+	// LR0 grammars have an LR(0) automaton without conflicts.
+	LR0 bool
+	// Operator grammars have no ε-productions and no adjacent variables.
+	Operator bool
+	// OperatorPrecedence grammars are operator grammars with at most one precedence relation between two terminals.
+	OperatorPrecedence bool
+}
+
+func (c Classification) String() string {
+	var properties []string
+	for _, p := range []struct {
+		name string
+		ok   bool
+	}{
+		{"linear", c.Linear},
+		{"right-linear", c.RightLinear},
+		{"left-linear", c.LeftLinear},
+		{"CNF", c.CNF},
+		{"GNF", c.GNF},
+		{"ε-free", c.EpsilonFree},
+		{"LL(1)", c.LL1},
+		{"LR(0)", c.LR0},
+		{"operator", c.Operator},
+		{"operator-precedence", c.OperatorPrecedence},
+	} {
+		if p.ok {
+			properties = append(properties, p.name)
+		}
+	}
+	return strings.Join(properties, ", ")
+}
+
+// Classify reports the forms and properties of the grammar.
+func (g *CFG) Classify() Classification {
+	c := Classification{
+		Linear:      true,
+		RightLinear: true,
+		LeftLinear:  true,
+		CNF:         true,
+		GNF:         true,
+		EpsilonFree: true,
+		Operator:    true,
+	}
+	var startUsed, startEpsilon bool
+	for _, rule := range g.Rules {
+		for _, b := range rule.B {
+			if b == g.StartVariable {
+				startUsed = true
+			}
+		}
+		if rule.A == g.StartVariable && len(rule.B) == 1 && rule.B[0] == Epsilon {
+			startEpsilon = true
+		}
+	}
+	// The start variable can only be used in a production of a normal form if it is not nullable.
+	startAllowed := func(b Beta) bool {
+		return !startEpsilon || b != g.StartVariable
+	}
+	for _, rule := range g.Rules {
+		var variables []int
+		for i, b := range rule.B {
+			if _, ok := b.(Variable); ok {
+				variables = append(variables, i)
+			}
+		}
+		epsilon := len(rule.B) == 1 && rule.B[0] == Epsilon
+		startEpsilon := epsilon && rule.A == g.StartVariable && !startUsed
+
+		c.Linear = c.Linear && len(variables) <= 1
+		c.RightLinear = c.RightLinear && (len(variables) == 0 || (len(variables) == 1 && variables[0] == len(rule.B)-1))
+		c.LeftLinear = c.LeftLinear && (len(variables) == 0 || (len(variables) == 1 && variables[0] == 0))
+		c.EpsilonFree = c.EpsilonFree && (!epsilon || startEpsilon)
+
+		terminal := len(rule.B) == 1 && len(variables) == 0 && !epsilon
+		binary := len(rule.B) == 2 && len(variables) == 2 && startAllowed(rule.B[0]) && startAllowed(rule.B[1])
+		c.CNF = c.CNF && (terminal || binary || startEpsilon)
+
+		greibach := !epsilon && len(variables) == len(rule.B)-1 && (len(variables) == 0 || variables[0] == 1)
+		for _, i := range variables {
+			greibach = greibach && startAllowed(rule.B[i])
+		}
+		c.GNF = c.GNF && (greibach || startEpsilon)
+
+		c.Operator = c.Operator && !epsilon
+		for i := 1; i < len(variables); i++ {
+			c.Operator = c.Operator && variables[i-1]+1 != variables[i]
+		}
+	}
+	c.LL1 = len(g.PredictiveTable().Conflicts()) == 0
+	c.LR0 = len(g.LR0().Conflicts()) == 0
+	c.OperatorPrecedence = c.Operator && g.operatorPrecedence()
+	return c
+}
+
+// operatorPrecedence returns true if there is at most one precedence relation between any two terminals, given that
+// the grammar is an operator grammar.
+func (g *CFG) operatorPrecedence() bool {
+	leading := g.operatorSets(func(bs []Beta) []Beta { return bs })
+	trailing := g.operatorSets(reverse)
+
+	// The relations are -1 (⋖), 0 (≐) and 1 (⋗).
+	type key struct{ a, b Beta }
+	relations := make(map[key]int)
+	ok := true
+	relate := func(a, b Beta, relation int) {
+		if r, seen := relations[key{a, b}]; seen && r != relation {
+			ok = false
+		}
+		relations[key{a, b}] = relation
+	}
+	for t := range leading[g.StartVariable] {
+		relate(EndOfInput, t, -1)
+	}
+	for t := range trailing[g.StartVariable] {
+		relate(t, EndOfInput, 1)
+	}
+	for _, rule := range g.Rules {
+		for i, b := range rule.B {
+			if i+1 == len(rule.B) {
+				break
+			}
+			next := rule.B[i+1]
+			if isTerminal(next) {
+				if isTerminal(b) {
+					relate(b, next, 0)
+				} else {
+					for t := range trailing[b.(Variable)] {
+						relate(t, next, 1)
+					}
+				}
+				continue
+			}
+			if !isTerminal(b) {
+				continue
+			}
+			for t := range leading[next.(Variable)] {
+				relate(b, t, -1)
+			}
+			if i+2 < len(rule.B) && isTerminal(rule.B[i+2]) {
+				relate(b, rule.B[i+2], 0)
+			}
+		}
+	}
+	return ok
+}
+
+// operatorSets computes the LEADING sets of the variables, the terminals that can be the first terminal of a
+// sentential form with at most one variable before it. Given reverse, it computes the TRAILING sets. The sets hold
+// terminals and categories.
+func (g *CFG) operatorSets(order func([]Beta) []Beta) map[Variable]map[Beta]struct{} {
+	sets := make(map[Variable]map[Beta]struct{})
+	for _, v := range g.Variables {
+		sets[v] = make(map[Beta]struct{})
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			add := func(t Beta) {
+				if _, ok := sets[a][t]; !ok {
+					sets[a][t] = struct{}{}
+					changed = true
+				}
+			}
+			bs := order(rule.B)
+			if len(bs) == 0 {
+				continue
+			}
+			switch b := bs[0].(type) {
+			case Terminal, Category:
+				add(b)
+			case Variable:
+				for t := range sets[b] {
+					add(t)
+				}
+				if 1 < len(bs) && isTerminal(bs[1]) {
+					add(bs[1])
+				}
+			}
+		}
+	}
+	return sets
+}
@@ -0,0 +1,39 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_Classify() {
+	g, _ := cfg.Parse(`
+		E → EpT | T
+		T → TmF | F
+		F → (E) | a
+	`)
+	fmt.Println(g.Classify())
+	// Output:
+	// ε-free, operator, operator-precedence
+}
+
+func TestCFG_Classify(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		expected string
+	}{
+		{"S → aS | b\n", "linear, right-linear, GNF, ε-free, LL(1), LR(0), operator, operator-precedence"},
+		{"S → Sa | b\n", "linear, left-linear, ε-free, operator, operator-precedence"},
+		{"S → AB | ε\nA → a\nB → b\n", "CNF, ε-free, LL(1)"},
+		{"S → AS | a\nA → a\n", "CNF, ε-free"},
+		{"S → aSb | ε\n", "linear, LL(1)"},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := g.Classify().String(); c != test.expected {
+			t.Errorf("%q: expected %q, got %q", test.grammar, test.expected, c)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cfg
+
+import "sync"
+
+// Completions returns the sequences of at most maxLen terminals that extend the prefix to a prefix of a string in the
+// language, shortest first and in the order of the alphabet. A sequence is only extended if it is a valid extension
+// itself, so every returned sequence can be typed one terminal at a time. The grammar of the prefix language is built
+// once and reused by later calls. It returns an error wrapping ErrResourceLimit if an extension could not be decided
+// within the limits of the grammar (see EvaluateLimited).
+func (g *CFG) Completions(prefix string, maxLen int) ([]string, error) {
+	p, err := g.prefixes.get(g)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok, err := p.EvaluateLimited(prefix); err != nil || !ok {
+		return nil, err
+	}
+	var completions []string
+	extensions := []string{""}
+	for i := 0; i < maxLen; i++ {
+		var next []string
+		for _, e := range extensions {
+			for _, t := range g.Alphabet {
+				_, ok, err := p.EvaluateLimited(prefix + e + string(t))
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					next = append(next, e+string(t))
+				}
+			}
+		}
+		completions = append(completions, next...)
+		extensions = next
+	}
+	return completions, nil
+}
+
+// prefixGrammar is the grammar of the prefix language of a grammar, see Completions.
+type prefixGrammar struct {
+	once sync.Once
+	g    *CFG
+	err  error
+}
+
+// get returns the grammar of the prefix language, with the current settings of the grammar.
+func (p *prefixGrammar) get(g *CFG) (*CFG, error) {
+	p.once.Do(func() {
+		p.g, p.err = g.PrefixLanguage()
+	})
+	if p.err != nil {
+		return nil, p.err
+	}
+	// The settings may have changed since the grammar was built, the copy is not shared with other calls.
+	q := *p.g
+	q.limits = g.limits
+	q.strategy = g.strategy
+	q.fields = g.fields
+	q.logger = g.logger
+	q.metrics = g.metrics
+	q.resources = g.resources
+	return &q, nil
+}
@@ -0,0 +1,33 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_Completions() {
+	g, _ := cfg.Parse("S → (S)S | [S]S | ε\n")
+	fmt.Println(g.Completions("([", 1))
+	fmt.Println(g.Completions("([", 2))
+	fmt.Println(g.Completions("(]", 2))
+	// Output:
+	// [( [ ]] <nil>
+	// [( [ ] (( () ([ [( [[ [] ]( ]) ][] <nil>
+	// [] <nil>
+}
+
+func TestCFG_Completions_limit(t *testing.T) {
+	g, err := cfg.Parse("S → (S)S | [S]S | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := g.Completions("((", 1); err != nil || len(c) != 3 {
+		t.Fatalf("unexpected completions %v (%v)", c, err)
+	}
+	g.SetLimits(cfg.Limits{Steps: 2})
+	if _, err := g.Completions("((", 1); !errors.Is(err, cfg.ErrResourceLimit) {
+		t.Errorf("expected %v, got %v", cfg.ErrResourceLimit, err)
+	}
+}
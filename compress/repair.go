@@ -0,0 +1,92 @@
+// Package compress implements grammar-based compression: a string is represented by a straight-line grammar, a
+// grammar in which every variable has a single production and which derives exactly that string.
+package compress
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+)
+
+// StartVariable is the start variable of the grammars produced by RePair, the other variables are R1, R2, ….
+const StartVariable = cfg.Variable("R0")
+
+// RePair compresses the string with the Re-Pair algorithm of Larsson and Moffat: the most frequent pair of adjacent
+// symbols is replaced by a new variable, until no pair occurs more than once. Every rune of the string is a terminal.
+func RePair(s string) (*cfg.CFG, error) {
+	var alphabet cfg.Alphabet
+	seen := make(map[cfg.Terminal]bool)
+	var sequence []cfg.Beta
+	for _, r := range s {
+		t := cfg.Terminal(r)
+		if !seen[t] {
+			seen[t] = true
+			alphabet = append(alphabet, t)
+		}
+		sequence = append(sequence, t)
+	}
+
+	variables := cfg.V{StartVariable}
+	var rules cfg.R
+	for {
+		p, ok := mostFrequent(sequence)
+		if !ok {
+			break
+		}
+		v := cfg.Variable(fmt.Sprintf("R%d", len(variables)))
+		variables = append(variables, v)
+		rules = append(rules, cfg.NewProduction(v, []cfg.Beta{p.left, p.right}))
+		sequence = replace(sequence, p, v)
+	}
+	if len(sequence) == 0 {
+		sequence = []cfg.Beta{cfg.Epsilon}
+	}
+	rules = append(cfg.R{cfg.NewProduction(StartVariable, sequence)}, rules...)
+	return cfg.New(variables, alphabet, rules, StartVariable)
+}
+
+// mostFrequent returns the pair with the most non-overlapping occurrences, if it occurs at least twice. Ties are
+// broken by the first occurrence.
+func mostFrequent(sequence []cfg.Beta) (pair, bool) {
+	counts := make(map[pair]int)
+	var order []pair
+	// last is the index of the last counted occurrence of every pair, to not count overlapping ones (e.g. in `aaa`).
+	last := make(map[pair]int)
+	for i := 0; i+1 < len(sequence); i++ {
+		p := pair{sequence[i], sequence[i+1]}
+		if j, ok := last[p]; ok && j == i-1 {
+			continue
+		}
+		if _, ok := counts[p]; !ok {
+			order = append(order, p)
+		}
+		counts[p]++
+		last[p] = i
+	}
+	var best pair
+	var max int
+	for _, p := range order {
+		if max < counts[p] {
+			best, max = p, counts[p]
+		}
+	}
+	return best, 2 <= max
+}
+
+// replace replaces the non-overlapping occurrences of the pair by the variable, from left to right.
+func replace(sequence []cfg.Beta, p pair, v cfg.Variable) []cfg.Beta {
+	var replaced []cfg.Beta
+	for i := 0; i < len(sequence); i++ {
+		if i+1 < len(sequence) && (pair{sequence[i], sequence[i+1]}) == p {
+			replaced = append(replaced, v)
+			i++
+			continue
+		}
+		replaced = append(replaced, sequence[i])
+	}
+	return replaced
+}
+
+// pair is a pair of adjacent symbols.
+type pair struct {
+	left, right cfg.Beta
+}
@@ -0,0 +1,30 @@
+package compress_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg/compress"
+	"strings"
+	"testing"
+)
+
+func ExampleRePair() {
+	g, _ := compress.RePair("abcabcabc")
+	fmt.Println(g.Rules)
+	// Output:
+	// R0 → R2R2R2, R1 → ab, R2 → R1c
+}
+
+func TestRePair(t *testing.T) {
+	for _, s := range []string{"", "a", "aaaa", "abracadabra", strings.Repeat("xyz", 50)} {
+		g, err := compress.RePair(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := g.Evaluate(s); !ok {
+			t.Errorf("expected %q to be derived", s)
+		}
+		if 10 < len(s) && len(s) < len(g.Rules)*2 {
+			t.Errorf("expected %q to be compressed, got %d rules", s, len(g.Rules))
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package compress
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"math/bits"
+	"strings"
+)
+
+const (
+	// mersenne61 is the prime 2^61-1, the modulus of the fingerprints.
+	mersenne61 = 1<<61 - 1
+	// base is the base of the polynomial fingerprints.
+	base = 1_000_003
+)
+
+// add returns a+b mod 2^61-1.
+func add(a, b uint64) uint64 {
+	s := a + b
+	if mersenne61 <= s {
+		s -= mersenne61
+	}
+	return s
+}
+
+// mul returns a*b mod 2^61-1.
+func mul(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	// a*b = hi*2^64 + lo, and 2^61 ≡ 1, so a*b ≡ hi*2^3 + lo>>61 + lo&(2^61-1).
+	return add(hi<<3|lo>>61, lo&mersenne61)
+}
+
+// SLP is a straight-line program: a grammar in which every variable has exactly one production and no variable derives
+// itself, so it derives exactly one string. Its operations work on the grammar, without expanding the string.
+type SLP struct {
+	g *cfg.CFG
+	// lengths are the number of runes derived by each variable.
+	lengths map[cfg.Variable]int
+	// fingerprints are the polynomial hashes of the strings derived by each variable, powers are base^length.
+	fingerprints map[cfg.Variable]uint64
+	powers       map[cfg.Variable]uint64
+}
+
+// NewSLP checks that the grammar is a straight-line program.
+func NewSLP(g *cfg.CFG) (*SLP, error) {
+	s := &SLP{
+		g:            g,
+		lengths:      make(map[cfg.Variable]int),
+		fingerprints: make(map[cfg.Variable]uint64),
+		powers:       make(map[cfg.Variable]uint64),
+	}
+	for _, v := range g.Variables {
+		if n := len(g.RulesFor(v)); n != 1 {
+			return nil, fmt.Errorf("variable %v has %d productions, expected 1", v, n)
+		}
+	}
+	// visiting marks the variables of the current path, to detect recursion.
+	visiting := make(map[cfg.Variable]bool)
+	var visit func(v cfg.Variable) error
+	visit = func(v cfg.Variable) error {
+		if _, ok := s.lengths[v]; ok {
+			return nil
+		}
+		if visiting[v] {
+			return fmt.Errorf("variable %v derives itself", v)
+		}
+		visiting[v] = true
+		defer delete(visiting, v)
+		var length int
+		fingerprint, power := uint64(0), uint64(1)
+		for _, b := range g.RulesFor(v)[0].B {
+			switch b := b.(type) {
+			case cfg.Terminal:
+				for _, r := range string(b) {
+					length++
+					fingerprint = add(mul(fingerprint, base), uint64(r))
+					power = mul(power, base)
+				}
+			case cfg.Variable:
+				if err := visit(b); err != nil {
+					return err
+				}
+				length += s.lengths[b]
+				fingerprint = add(mul(fingerprint, s.powers[b]), s.fingerprints[b])
+				power = mul(power, s.powers[b])
+			case cfg.Category:
+				return fmt.Errorf("variable %v derives the category %v, which is not a single string", v, b)
+			}
+		}
+		s.lengths[v], s.fingerprints[v], s.powers[v] = length, fingerprint, power
+		return nil
+	}
+	for _, v := range g.Variables {
+		if err := visit(v); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// At returns the i-th rune of the string, in time proportional to the depth of the grammar. It panics if i is out of
+// range, like indexing a slice.
+func (s *SLP) At(i int) rune {
+	if i < 0 || s.Len() <= i {
+		panic(fmt.Sprintf("compress: index %d out of range [0:%d]", i, s.Len()))
+	}
+	v := s.g.StartVariable
+	for {
+	symbols:
+		for _, b := range s.g.RulesFor(v)[0].B {
+			switch b := b.(type) {
+			case cfg.Terminal:
+				for _, r := range string(b) {
+					if i == 0 {
+						return r
+					}
+					i--
+				}
+			case cfg.Variable:
+				if i < s.lengths[b] {
+					v = b
+					break symbols
+				}
+				i -= s.lengths[b]
+			}
+		}
+	}
+}
+
+// Equal checks whether both programs derive the same string, by comparing their lengths and polynomial fingerprints.
+// Different strings have the same fingerprint with a probability of about length/2^61.
+func (s *SLP) Equal(other *SLP) bool {
+	a, b := s.g.StartVariable, other.g.StartVariable
+	return s.lengths[a] == other.lengths[b] && s.fingerprints[a] == other.fingerprints[b]
+}
+
+// Expand returns the derived string.
+func (s *SLP) Expand() string {
+	var sb strings.Builder
+	var expand func(v cfg.Variable)
+	expand = func(v cfg.Variable) {
+		for _, b := range s.g.RulesFor(v)[0].B {
+			switch b := b.(type) {
+			case cfg.Terminal:
+				sb.WriteString(string(b))
+			case cfg.Variable:
+				expand(b)
+			}
+		}
+	}
+	expand(s.g.StartVariable)
+	return sb.String()
+}
+
+// Grammar returns the grammar of the program.
+func (s *SLP) Grammar() *cfg.CFG {
+	return s.g
+}
+
+// Len returns the number of runes of the derived string.
+func (s *SLP) Len() int {
+	return s.lengths[s.g.StartVariable]
+}
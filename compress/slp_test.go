@@ -0,0 +1,65 @@
+package compress_test
+
+import (
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/compress"
+	"strings"
+	"testing"
+)
+
+func TestSLP(t *testing.T) {
+	s := strings.Repeat("abcab", 40) + "é"
+	g, err := compress.RePair(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slp, err := compress.NewSLP(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slp.Expand() != s {
+		t.Errorf("expected %q, got %q", s, slp.Expand())
+	}
+	rs := []rune(s)
+	if slp.Len() != len(rs) {
+		t.Errorf("expected length %d, got %d", len(rs), slp.Len())
+	}
+	for i, r := range rs {
+		if c := slp.At(i); c != r {
+			t.Errorf("expected %q at %d, got %q", r, i, c)
+		}
+	}
+
+	// The same string, written as a different program.
+	h, err := cfg.Parse("S → AAAAAAAAB\nA → CCCCC\nB → abcab\nC → abcab\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := compress.NewSLP(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, err := compress.RePair(strings.Repeat("abcab", 41))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, _ := compress.NewSLP(prefix)
+	if !other.Equal(p) {
+		t.Error("expected both programs to be equal")
+	}
+	if slp.Equal(p) {
+		t.Error("expected both programs to be different")
+	}
+}
+
+func TestNewSLP(t *testing.T) {
+	for _, grammar := range []string{"S → a | b\n", "S → aA\nA → S\n"} {
+		g, err := cfg.Parse(grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := compress.NewSLP(g); err == nil {
+			t.Errorf("expected %q to be rejected", grammar)
+		}
+	}
+}
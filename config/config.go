@@ -0,0 +1,114 @@
+// Package config loads grammars from structured YAML and TOML documents, as an alternative to the text format.
+//
+// A rule lists the alternatives of a variable as arrays of symbols, an empty array is ε. Every symbol that is the
+// variable of a rule is a variable, all other symbols are terminals.
+//
+//	start: S
+//	rules:
+//	  - variable: S
+//	    alternatives: [[a, S, a], [b, S, b], []]
+package config
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// Config is the structured representation of a grammar.
+type Config struct {
+	// Start is the start variable, defaults to the variable of the first rule.
+	Start string `yaml:"start,omitempty" toml:"start,omitempty"`
+	// Alphabet optionally declares the terminals, defaults to all terminals used in the rules.
+	Alphabet []string `yaml:"alphabet,omitempty" toml:"alphabet,omitempty"`
+	Rules    []Rule   `yaml:"rules" toml:"rules"`
+}
+
+// LoadTOML reads a grammar from a TOML document.
+func LoadTOML(r io.Reader) (*cfg.CFG, error) {
+	var c Config
+	if _, err := toml.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	return c.Grammar()
+}
+
+// LoadYAML reads a grammar from a YAML document.
+func LoadYAML(r io.Reader) (*cfg.CFG, error) {
+	var c Config
+	if err := yaml.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+	return c.Grammar()
+}
+
+// Grammar converts the configuration into a grammar.
+func (c Config) Grammar() (*cfg.CFG, error) {
+	if len(c.Rules) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+	var variables cfg.V
+	vm := make(map[string]bool)
+	for _, rule := range c.Rules {
+		if rule.Variable == "" {
+			return nil, fmt.Errorf("rule without variable")
+		}
+		if !vm[rule.Variable] {
+			vm[rule.Variable] = true
+			variables = append(variables, cfg.Variable(rule.Variable))
+		}
+	}
+
+	var alphabet cfg.Alphabet
+	tm := make(map[string]bool)
+	for _, t := range c.Alphabet {
+		if !tm[t] {
+			tm[t] = true
+			alphabet = append(alphabet, cfg.Terminal(t))
+		}
+	}
+	var rules cfg.R
+	for _, rule := range c.Rules {
+		if len(rule.Labels) != 0 && len(rule.Labels) != len(rule.Alternatives) {
+			return nil, fmt.Errorf("rule %s has %d labels for %d alternatives", rule.Variable, len(rule.Labels), len(rule.Alternatives))
+		}
+		for i, alternative := range rule.Alternatives {
+			beta := []cfg.Beta{cfg.Epsilon}
+			if len(alternative) != 0 {
+				beta = nil
+			}
+			for _, s := range alternative {
+				if vm[s] {
+					beta = append(beta, cfg.Variable(s))
+					continue
+				}
+				if len(c.Alphabet) == 0 && !tm[s] {
+					tm[s] = true
+					alphabet = append(alphabet, cfg.Terminal(s))
+				}
+				beta = append(beta, cfg.Terminal(s))
+			}
+			p := cfg.NewProduction(cfg.Variable(rule.Variable), beta)
+			if len(rule.Labels) != 0 {
+				p.Label = rule.Labels[i]
+			}
+			rules = append(rules, p)
+		}
+	}
+
+	start := cfg.Variable(c.Start)
+	if start == "" {
+		start = variables[0]
+	}
+	return cfg.New(variables, alphabet, rules, start)
+}
+
+// Rule contains all alternatives of a variable.
+type Rule struct {
+	Variable     string     `yaml:"variable" toml:"variable"`
+	Alternatives [][]string `yaml:"alternatives" toml:"alternatives"`
+	// Labels optionally labels the alternatives, in the same order.
+	Labels []string `yaml:"labels,omitempty" toml:"labels,omitempty"`
+}
@@ -0,0 +1,52 @@
+package config_test
+
+import (
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/config"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	yaml := `
+start: S
+rules:
+  - variable: S
+    alternatives: [[a, S, a], [b, S, b], []]
+    labels: [a, b, empty]
+`
+	toml := `
+start = "S"
+
+[[rules]]
+variable = "S"
+alternatives = [["a", "S", "a"], ["b", "S", "b"], []]
+labels = ["a", "b", "empty"]
+`
+	for doc, load := range map[string]func(io.Reader) (*cfg.CFG, error){
+		yaml: config.LoadYAML,
+		toml: config.LoadTOML,
+	} {
+		g, err := load(strings.NewReader(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := g.String(); s != "( { S }, { a, b }, [ S → aSa #a, S → bSb #b, S → ε #empty ], S )" {
+			t.Errorf("unexpected grammar %s", s)
+		}
+	}
+}
+
+func TestConfig_Grammar(t *testing.T) {
+	for _, c := range []config.Config{
+		{},
+		{Rules: []config.Rule{{Alternatives: [][]string{{"a"}}}}},
+		{Rules: []config.Rule{{Variable: "S", Alternatives: [][]string{{"a"}}, Labels: []string{"a", "b"}}}},
+		{Alphabet: []string{"a"}, Rules: []config.Rule{{Variable: "S", Alternatives: [][]string{{"b"}}}}},
+	} {
+		if _, err := c.Grammar(); err == nil {
+			t.Errorf("expected an error for %v", c)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package cfg
+
+import "fmt"
+
+// fresh returns a variable with the given name that is not yet used, primes are appended until it is unique.
+func fresh(name string, used map[Variable]bool) Variable {
+	v := Variable(name)
+	for used[v] {
+		v += "'"
+	}
+	used[v] = true
+	return v
+}
+
+// orEpsilon returns a copy of the symbols, or ε if there are none.
+func orEpsilon(bs []Beta) []Beta {
+	if len(bs) == 0 {
+		return []Beta{Epsilon}
+	}
+	return append([]Beta{}, bs...)
+}
+
+func reverse(bs []Beta) []Beta {
+	r := make([]Beta, len(bs))
+	for i, b := range bs {
+		r[len(bs)-1-i] = b
+	}
+	return r
+}
+
+// LeftQuotient returns the grammar of the left quotient of the language by the given string of terminals, i.e. of
+// all strings x for which wx is in the language.
+func (g *CFG) LeftQuotient(w ...Terminal) (*CFG, error) {
+	q := g
+	for _, t := range w {
+		var err error
+		if q, err = q.leftQuotient(t); err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// PrefixLanguage returns the grammar of all prefixes of the strings in the language, including the empty string.
+// Every productive variable A gets a variable A' that derives the prefixes of A, the start variable is S'.
+func (g *CFG) PrefixLanguage() (*CFG, error) {
+	productive := g.productive()
+	used := make(map[Variable]bool)
+	for _, v := range g.Variables {
+		used[v] = true
+	}
+	prefixes := make(map[Variable]Variable)
+	variables := append(V{}, g.Variables...)
+	prefix := func(v Variable) Variable {
+		if p, ok := prefixes[v]; ok {
+			return p
+		}
+		p := fresh(string(v)+"'", used)
+		prefixes[v] = p
+		variables = append(variables, p)
+		return p
+	}
+	// A production with an unproductive symbol derives no string, so none of its prefixes are prefixes of the
+	// language.
+	isProductive := func(p Production) bool {
+		for _, b := range p.B {
+			if v, ok := b.(Variable); ok {
+				if _, ok := productive[v]; !ok {
+					return false
+				}
+			}
+		}
+		return true
+	}
+
+	rules := append(R{}, g.Rules...)
+	seen := make(map[string]bool)
+	add := func(p Production) {
+		if !seen[p.key()] {
+			seen[p.key()] = true
+			rules = append(rules, p)
+		}
+	}
+	start := prefix(g.StartVariable)
+	for _, v := range g.Variables {
+		if _, ok := productive[v]; ok {
+			add(NewProduction(prefix(v), []Beta{Epsilon}))
+		}
+	}
+	for _, rule := range g.Rules {
+		if !isProductive(rule) {
+			continue
+		}
+		for i, b := range rule.B {
+			if b == Epsilon {
+				continue
+			}
+			beta := append([]Beta{}, rule.B[:i]...)
+			if v, ok := b.(Variable); ok {
+				beta = append(beta, prefix(v))
+			} else {
+				beta = append(beta, b)
+			}
+			add(NewProduction(prefix(rule.A.(Variable)), beta))
+		}
+	}
+	p, err := New(variables, g.Alphabet, rules, start)
+	if err != nil {
+		return nil, err
+	}
+	p.limits = g.limits
+	p.strategy = g.strategy
+	p.fields = g.fields
+	p.logger = g.logger
+	p.metrics = g.metrics
+	p.resources = g.resources
+	return p, nil
+}
+
+// Reverse returns the grammar of the reversed strings of the language.
+func (g *CFG) Reverse() (*CFG, error) {
+	rules := make(R, len(g.Rules))
+	for i, rule := range g.Rules {
+		positions := make([]int, len(rule.B))
+		for j := range positions {
+			positions[j] = len(rule.B) - 1 - j
+		}
+		rule.B, rule.Captures = reverse(rule.B), rule.capturesAt(positions)
+		rules[i] = rule
+	}
+	r, err := New(g.Variables, g.Alphabet, rules, g.StartVariable)
+	if err != nil {
+		return nil, err
+	}
+	r.limits = g.limits
+	r.strategy = g.strategy
+	r.fields = g.fields
+	r.logger = g.logger
+	r.metrics = g.metrics
+	r.resources = g.resources
+	return r, nil
+}
+
+// RightQuotient returns the grammar of the right quotient of the language by the given string of terminals, i.e. of
+// all strings x for which xw is in the language.
+func (g *CFG) RightQuotient(w ...Terminal) (*CFG, error) {
+	r, err := g.Reverse()
+	if err != nil {
+		return nil, err
+	}
+	reversed := make([]Terminal, len(w))
+	for i, t := range w {
+		reversed[len(w)-1-i] = t
+	}
+	q, err := r.LeftQuotient(reversed...)
+	if err != nil {
+		return nil, err
+	}
+	return q.Reverse()
+}
+
+// MapTerminals renames the terminals of the grammar, e.g. to change its alphabet. Terminals that are mapped to the
+// same terminal are merged.
+func (g *CFG) MapTerminals(f func(Terminal) Terminal) (*CFG, error) {
+	m := make(map[Terminal][]Beta)
+	for _, t := range g.Alphabet {
+		m[t] = []Beta{f(t)}
+	}
+	return g.Substitute(m)
+}
+
+// Substitute replaces every terminal in the productions by the given symbols, terminals without substitution are
+// kept. The symbols can contain variables of the grammar, so a terminal can be substituted by a language, and an empty
+// substitution erases the terminal. Terminals of the substitutions are added to the alphabet.
+func (g *CFG) Substitute(substitution map[Terminal][]Beta) (*CFG, error) {
+	var alphabet Alphabet
+	seen := make(map[Terminal]bool)
+	addTerminal := func(t Terminal) {
+		if !seen[t] {
+			seen[t] = true
+			alphabet = append(alphabet, t)
+		}
+	}
+	for _, t := range g.Alphabet {
+		bs, ok := substitution[t]
+		if !ok {
+			addTerminal(t)
+			continue
+		}
+		for _, b := range bs {
+			switch b := b.(type) {
+			case Terminal:
+				addTerminal(b)
+			case Variable:
+				if _, ok := g.symbols.variable[b]; !ok {
+					return nil, fmt.Errorf("variable %v in substitution of %v not in variables", b, t)
+				}
+			}
+		}
+	}
+	rules := make(R, len(g.Rules))
+	for i, rule := range g.Rules {
+		var b []Beta
+		// positions are the positions of the symbols in the original production, -1 for a substituted terminal, whose
+		// capture name is dropped.
+		var positions []int
+		for j, beta := range rule.B {
+			if t, ok := beta.(Terminal); ok {
+				if bs, ok := substitution[t]; ok {
+					for _, s := range bs {
+						if s != Epsilon {
+							b = append(b, s)
+							positions = append(positions, -1)
+						}
+					}
+					continue
+				}
+			}
+			b = append(b, beta)
+			positions = append(positions, j)
+		}
+		if len(b) == 0 {
+			b = []Beta{Epsilon}
+		}
+		rule.B, rule.Captures = b, rule.capturesAt(positions)
+		rules[i] = rule
+	}
+	s, err := New(g.Variables, alphabet, rules, g.StartVariable)
+	if err != nil {
+		return nil, err
+	}
+	s.limits = g.limits
+	s.strategy = g.strategy
+	s.fields = g.fields
+	s.logger = g.logger
+	s.metrics = g.metrics
+	s.resources = g.resources
+	return s, nil
+}
+
+// leftQuotient returns the grammar of the strings x for which tx is in the language. Every variable A gets a variable
+// A_t that derives the strings x for which tx is derived by A.
+func (g *CFG) leftQuotient(t Terminal) (*CFG, error) {
+	nullable := g.nullable()
+	used := make(map[Variable]bool)
+	for _, v := range g.Variables {
+		used[v] = true
+	}
+	quotients := make(map[Variable]Variable)
+	variables := append(V{}, g.Variables...)
+	quotient := func(v Variable) Variable {
+		if q, ok := quotients[v]; ok {
+			return q
+		}
+		q := fresh(fmt.Sprintf("%s_%s", v, t), used)
+		quotients[v] = q
+		variables = append(variables, q)
+		return q
+	}
+	start := quotient(g.StartVariable)
+	rules := append(R{}, g.Rules...)
+	for _, rule := range g.Rules {
+		for i, b := range rule.B {
+			rest := rule.B[i+1:]
+			if b == t {
+				rules = append(rules, NewProduction(quotient(rule.A.(Variable)), orEpsilon(rest)))
+			}
+			if v, ok := b.(Variable); ok {
+				rules = append(rules, NewProduction(quotient(rule.A.(Variable)), append([]Beta{quotient(v)}, rest...)))
+				if !nullable[v] {
+					break
+				}
+			} else if b != Epsilon {
+				break
+			}
+		}
+	}
+	q, err := New(variables, g.Alphabet, rules, start)
+	if err != nil {
+		return nil, err
+	}
+	q.limits = g.limits
+	q.strategy = g.strategy
+	q.fields = g.fields
+	q.logger = g.logger
+	q.metrics = g.metrics
+	q.resources = g.resources
+	return q, nil
+}
@@ -0,0 +1,115 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func ExampleCFG_Substitute() {
+	g, _ := cfg.Parse("S → aSb | A\nA → c\n")
+	h, _ := g.Substitute(map[cfg.Terminal][]cfg.Beta{
+		"a": {cfg.Terminal("x"), cfg.Terminal("y")},
+		"b": {cfg.Variable("A")},
+		"c": {},
+	})
+	fmt.Println(h)
+	// Output:
+	// ( { S, A }, { x, y }, [ S → xySA, S → A, A → ε ], S )
+}
+
+func TestCFG_MapTerminals(t *testing.T) {
+	g, err := cfg.Parse("S → aSb | ab\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := g.MapTerminals(func(t cfg.Terminal) cfg.Terminal {
+		return cfg.Terminal(strings.ToUpper(string(t)))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := h.Evaluate("AABB"); !ok {
+		t.Error("expected AABB to be accepted")
+	}
+	if _, err := g.MapTerminals(func(cfg.Terminal) cfg.Terminal { return "S" }); err == nil {
+		t.Error("expected an error if terminals and variables are not disjoint")
+	}
+	if _, err := g.Substitute(map[cfg.Terminal][]cfg.Beta{"a": {cfg.Variable("X")}}); err == nil {
+		t.Error("expected an error for an unknown variable")
+	}
+}
+
+func TestCFG_PrefixLanguage(t *testing.T) {
+	g, err := cfg.Parse("S → aSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, err := g.PrefixLanguage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	left, err := g.LeftQuotient("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	right, err := g.RightQuotient("b", "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		g        *cfg.CFG
+		accepted []string
+		rejected []string
+	}{
+		{prefix, []string{"", "a", "aab", "aabb"}, []string{"b", "abb", "aba"}},
+		{left, []string{"b", "abb", "aabbb"}, []string{"", "ab", "a"}},
+		{right, []string{"aa", "aaab"}, []string{"", "a", "aab"}},
+	} {
+		for _, s := range test.accepted {
+			if _, ok := test.g.Evaluate(s); !ok {
+				t.Errorf("expected %q to be accepted by %v", s, test.g)
+			}
+		}
+		for _, s := range test.rejected {
+			if _, ok := test.g.Evaluate(s); ok {
+				t.Errorf("expected %q to be rejected by %v", s, test.g)
+			}
+		}
+	}
+}
+
+func TestCFG_PrefixLanguage_unproductive(t *testing.T) {
+	g, err := cfg.Parse("S → aB | cS | c\nB → bB\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefix, err := g.PrefixLanguage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "", accepted: true},
+		{input: "c", accepted: true},
+		{input: "cc", accepted: true},
+		{input: "a", accepted: false},
+		{input: "ca", accepted: false},
+		{input: "cab", accepted: false},
+	} {
+		if _, ok := prefix.Evaluate(test.input); ok != test.accepted {
+			t.Errorf("%q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+	}
+}
+
+func ExampleCFG_LeftQuotient() {
+	g, _ := cfg.Parse("S → aSb | ε\n")
+	q, _ := g.LeftQuotient("a")
+	fmt.Println(q)
+	// Output:
+	// ( { S, S_a }, { a, b }, [ S → aSb, S → ε, S_a → Sb ], S_a )
+}
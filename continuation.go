@@ -0,0 +1,122 @@
+package cfg
+
+import "sync"
+
+// Continuation returns the initial state of the backtracking search of Evaluate for the string, see Continuation.
+func (g *CFG) Continuation(s string) *Continuation {
+	return &Continuation{
+		g:    g,
+		s:    g.input(s),
+		form: g.symbols.form([]Beta{g.StartVariable}),
+		e:    g.newEvaluation(s),
+		mu:   new(sync.Mutex),
+	}
+}
+
+// Continuation is a state of the backtracking search of Evaluate: the sentential form that still has to derive the
+// remaining string, and the leftmost derivation so far. Next returns the states that Evaluate would try next, so a
+// search on top of it, e.g. one that interleaves evaluation with IO, is free to choose the order in which the states
+// are explored. A depth-first search that explores the states in the order of Next finds the derivation of Evaluate.
+// The states of a string share the limits of the grammar: the steps of all of them count towards the same step limit.
+// The states are safe for concurrent use, e.g. to explore the alternatives in parallel, but the calls of Next on the
+// states of a string are serialized.
+type Continuation struct {
+	g       *CFG
+	s       string
+	form    []symbol
+	matched int
+	path    Path
+	e       *evaluation
+	// mu guards the evaluation, which is shared by all states of the string.
+	mu *sync.Mutex
+}
+
+// Accepted returns true if the whole string was derived, the derivation is then complete.
+func (c *Continuation) Accepted() bool {
+	return len(c.form) == 0 && c.s == ""
+}
+
+// Err returns the resource limit that aborted the search, after which Next returns no states.
+func (c *Continuation) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.e.err
+}
+
+// Form returns the symbols that still have to derive the remaining string.
+func (c *Continuation) Form() []Beta {
+	var form []Beta
+	for _, s := range c.form {
+		if _, ok := s.beta.(checkpoint); !ok {
+			form = append(form, s.beta)
+		}
+	}
+	return form
+}
+
+// Next returns the states that follow from the state, in the order in which Evaluate tries them: the remaining string
+// without the terminal at the start of the form, or the form with the variable at its start replaced by each of its
+// productions. A state without successors is a dead end, or Accepted.
+func (c *Continuation) Next() []*Continuation {
+	g, e := c.g, c.e
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.form) == 0 || e.err != nil {
+		return nil
+	}
+	next := func(s string, form []symbol, matched int, path Path) *Continuation {
+		return &Continuation{g: g, s: s, form: form, matched: matched, path: path, e: e, mu: c.mu}
+	}
+	switch beta := c.form[0].beta.(type) {
+	case Terminal, Category:
+		if n, ok := g.match(c.s, beta, e); ok {
+			return []*Continuation{next(c.s[n:], c.form[1:], c.matched+1, c.path)}
+		}
+	case Variable:
+		if 0 < e.depth && e.depth < c.form[0].depth+1 {
+			return nil
+		}
+		alternatives := g.symbols.alternatives[c.form[0].id]
+		if rc := g.runeClass(c.form[0]); rc != nil {
+			i, n, ok := rc.match(c.s)
+			if ok && e.fields {
+				n, ok = fieldEnd(c.s, n)
+			}
+			if !ok || !g.step(e) || 0 < g.limits.Length && g.limits.Length < c.matched+1+formLength(c.form[1:]) {
+				return nil
+			}
+			return []*Continuation{next(c.s[n:], c.form[1:], c.matched+1, c.extend(alternatives[i].production))}
+		}
+		var cs []*Continuation
+		for _, alternative := range alternatives {
+			if !g.step(e) {
+				return cs
+			}
+			if form, ok := g.expand(c.s, c.form, c.matched, alternative, e); ok {
+				cs = append(cs, next(c.s, form, c.matched, c.extend(alternative.production)))
+			}
+		}
+		return cs
+	case checkpoint:
+		if g.allowed(beta.variable, beta.start[:len(beta.start)-len(c.s)], c.s, e) {
+			return []*Continuation{next(c.s, c.form[1:], c.matched, c.path)}
+		}
+	}
+	return nil
+}
+
+// Path returns the leftmost derivation so far.
+func (c *Continuation) Path() Path {
+	return append(Path(nil), c.path...)
+}
+
+// Remaining returns the part of the string that is not yet derived.
+func (c *Continuation) Remaining() string {
+	return c.s
+}
+
+// extend returns the derivation with the production appended, without sharing memory with the derivations of other
+// states.
+func (c *Continuation) extend(p Production) Path {
+	return append(c.path[:len(c.path):len(c.path)], p)
+}
@@ -0,0 +1,163 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func ExampleContinuation() {
+	g, _ := cfg.Parse("S → aSa | bSb | ε\n")
+	// A breadth-first search on top of the states of Evaluate.
+	queue := []*cfg.Continuation{g.Continuation("abba")}
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c.Accepted() {
+			fmt.Println(c.Path())
+			break
+		}
+		queue = append(queue, c.Next()...)
+	}
+	// Output:
+	// [ S → aSa, S → bSb, S → ε ]
+}
+
+// depthFirst explores the states in the order of Next.
+func depthFirst(c *cfg.Continuation) (cfg.Path, bool) {
+	if c.Accepted() {
+		return c.Path(), true
+	}
+	for _, next := range c.Next() {
+		if p, ok := depthFirst(next); ok {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+func TestContinuation(t *testing.T) {
+	h, err := cfg.Parse("S → AB | \\pS\nA → aA | ε\nB → bB | b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"", "abba", "aa", "aabb", "abab", "b", "aab", "ppab", "ab!"} {
+		for _, grammar := range []*cfg.CFG{g, h} {
+			expected, ok := grammar.Evaluate(s)
+			p, found := depthFirst(grammar.Continuation(s))
+			if ok != found || !reflect.DeepEqual(expected, p) {
+				t.Errorf("%q: expected %v (%v), got %v (%v)", s, expected, ok, p, found)
+			}
+		}
+	}
+
+	c := g.Continuation("abba")
+	if c.Remaining() != "abba" || fmt.Sprint(c.Form()) != "[S]" || len(c.Path()) != 0 {
+		t.Errorf("unexpected initial state %q %v %v", c.Remaining(), c.Form(), c.Path())
+	}
+	next := c.Next()
+	if len(next) != 3 || fmt.Sprint(next[0].Form()) != "[a S a]" {
+		t.Fatalf("unexpected states %v", next)
+	}
+	if next = next[0].Next(); len(next) != 1 || next[0].Remaining() != "bba" {
+		t.Fatalf("expected the terminal to be matched, got %v", next)
+	}
+
+	limited, err := cfg.Parse("S → aS | a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	limited.SetLimits(cfg.Limits{Steps: 3})
+	if _, ok := depthFirst(limited.Continuation("aaaa")); ok {
+		t.Error("expected the step limit to abort the search")
+	}
+	c = limited.Continuation("aaaa")
+	for next := c.Next(); len(next) != 0; next = c.Next() {
+		c = next[0]
+	}
+	if !errors.Is(c.Err(), cfg.ErrResourceLimit) {
+		t.Errorf("expected a resource limit, got %v", c.Err())
+	}
+}
+
+// The states of the alternatives of a variable do not share their forms or derivations, all derivations stay valid
+// after the other alternatives have been explored.
+func TestContinuation_aliasing(t *testing.T) {
+	// The productions of different lengths leave spare capacity in the derivations of the alternatives.
+	h, err := cfg.Parse("S → SS | a | A | aA\nA → a | aa\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Depth(cfg.AutoDepth)
+	var accepted []*cfg.Continuation
+	var explore func(c *cfg.Continuation)
+	explore = func(c *cfg.Continuation) {
+		if c.Accepted() {
+			accepted = append(accepted, c)
+		}
+		for _, next := range c.Next() {
+			explore(next)
+		}
+	}
+	explore(h.Continuation("aaaa"))
+	if n := len(h.EvaluateAll("aaaa")); len(accepted) != n {
+		t.Errorf("expected %d derivations, got %d", n, len(accepted))
+	}
+	unique := make(map[string]bool)
+	for _, c := range accepted {
+		tree, err := c.Path().Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := tree.Unparse(h); err != nil || u != "aaaa" {
+			t.Errorf("unexpected derivation %v", c.Path())
+		}
+		unique[fmt.Sprint(c.Path())] = true
+	}
+	if len(unique) != len(accepted) {
+		t.Errorf("expected %d distinct derivations, got %d", len(accepted), len(unique))
+	}
+}
+
+// The states can be explored concurrently, they only share the step limit.
+func TestContinuation_concurrent(t *testing.T) {
+	h, err := cfg.Parse("S → SS | a | A | aA\nA → a | aa\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Depth(cfg.AutoDepth)
+	var mu sync.Mutex
+	var accepted []cfg.Path
+	var wg sync.WaitGroup
+	var explore func(c *cfg.Continuation)
+	explore = func(c *cfg.Continuation) {
+		defer wg.Done()
+		if c.Accepted() {
+			mu.Lock()
+			accepted = append(accepted, c.Path())
+			mu.Unlock()
+		}
+		for _, next := range c.Next() {
+			wg.Add(1)
+			go explore(next)
+		}
+	}
+	wg.Add(1)
+	explore(h.Continuation("aaaa"))
+	wg.Wait()
+	if n := len(h.EvaluateAll("aaaa")); len(accepted) != n {
+		t.Errorf("expected %d derivations, got %d", n, len(accepted))
+	}
+	for _, p := range accepted {
+		tree, err := p.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := tree.Unparse(h); err != nil || u != "aaaa" {
+			t.Errorf("unexpected derivation %v", p)
+		}
+	}
+}
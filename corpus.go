@@ -0,0 +1,174 @@
+package cfg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrMismatch is returned by Corpus.Verify if samples of the corpus do not match the grammar.
+var ErrMismatch = errors.New("corpus does not match the grammar")
+
+// manifestName is the name of the manifest in a corpus directory, and the suffix of the manifest of a corpus file.
+const manifestName = "manifest.json"
+
+// ReadCorpus reads a corpus from a directory or from a newline-delimited file, together with its manifest.
+func ReadCorpus(path string) (*Corpus, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Corpus)
+	manifest := path + "." + manifestName
+	if info.IsDir() {
+		manifest = filepath.Join(path, manifestName)
+	}
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &c.Manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifest, err)
+	}
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) != 0 {
+			c.Samples = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		}
+		return c, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.Type().IsRegular() && e.Name() != manifestName {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(path, name))
+		if err != nil {
+			return nil, err
+		}
+		c.Samples = append(c.Samples, string(data))
+	}
+	return c, nil
+}
+
+// Corpus is a set of generated strings, e.g. test inputs of a parser, together with the manifest to reproduce them.
+type Corpus struct {
+	Manifest Manifest
+	Samples  []string
+}
+
+// Verify checks every sample against the grammar and returns the samples that are not in the language, or that are
+// in the language if the corpus consists of near misses, together with an error wrapping ErrMismatch if there are
+// any. The grammar hash of the manifest is not checked, compare it with Hash to detect changes of the grammar.
+func (c *Corpus) Verify(g *CFG) ([]string, error) {
+	recognizer, err := g.CYK()
+	if err != nil {
+		return nil, err
+	}
+	var mismatches []string
+	for _, s := range c.Samples {
+		if recognizer.Recognize(s) == c.Manifest.Negative {
+			mismatches = append(mismatches, s)
+		}
+	}
+	if len(mismatches) != 0 {
+		return mismatches, fmt.Errorf("%w: %d of %d samples, e.g. %q", ErrMismatch, len(mismatches), len(c.Samples), mismatches[0])
+	}
+	return nil, nil
+}
+
+// WriteDir writes every sample to its own file in the directory, which is created if it does not exist, together with
+// the manifest.
+func (c *Corpus) WriteDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := c.writeManifest(filepath.Join(dir, manifestName)); err != nil {
+		return err
+	}
+	for i, s := range c.Samples {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%06d.txt", i)), []byte(s), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFile writes the samples to a single file, one sample per line, and the manifest next to it (with the suffix
+// `.manifest.json`). Samples can not contain newlines.
+func (c *Corpus) WriteFile(name string) error {
+	var b strings.Builder
+	for _, s := range c.Samples {
+		if strings.Contains(s, "\n") {
+			return fmt.Errorf("sample %q contains a newline", s)
+		}
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(name, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return c.writeManifest(name + "." + manifestName)
+}
+
+func (c *Corpus) writeManifest(name string) error {
+	data, err := json.MarshalIndent(c.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, append(data, '\n'), 0o644)
+}
+
+// Hash returns the hex encoded SHA-256 hash of the variables, alphabet, rules and start variable of the grammar.
+func (g *CFG) Hash() string {
+	h := sha256.Sum256([]byte(g.String()))
+	return hex.EncodeToString(h[:])
+}
+
+// Corpus generates a corpus of n distinct strings of the language (see GenerateN), or of n near misses if negative is
+// set (see NearMisses). The manifest records the seed, the grammar hash and the target length.
+func (gen *Generator) Corpus(n int, negative bool) (*Corpus, error) {
+	generate := gen.GenerateN
+	if negative {
+		generate = gen.NearMisses
+	}
+	samples, err := generate(n)
+	if err != nil {
+		return nil, err
+	}
+	m := Manifest{Seed: gen.seed, Grammar: gen.g.Hash(), Negative: negative}
+	if 0 <= gen.target {
+		m.Options = map[string]string{
+			"target":    strconv.Itoa(gen.target),
+			"tolerance": strconv.Itoa(gen.tolerance),
+		}
+	}
+	return &Corpus{Manifest: m, Samples: samples}, nil
+}
+
+// Manifest describes how a corpus was generated.
+type Manifest struct {
+	Seed int64 `json:"seed"`
+	// Grammar is the hash of the grammar (see Hash).
+	Grammar string `json:"grammar"`
+	// Negative is set if the samples are near misses, strings that are not in the language.
+	Negative bool              `json:"negative,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+}
@@ -0,0 +1,73 @@
+package cfg_test
+
+import (
+	"errors"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/grammars"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCorpus(t *testing.T) {
+	g := grammars.Dyck()
+	gen := cfg.NewGenerator(g, 9)
+	gen.TargetLength(12, 4)
+	for _, negative := range []bool{false, true} {
+		c, err := gen.Corpus(10, negative)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Manifest.Grammar != g.Hash() || c.Manifest.Seed != 9 || c.Manifest.Options["target"] != "12" {
+			t.Errorf("unexpected manifest %+v", c.Manifest)
+		}
+		dir := t.TempDir()
+		if err := c.WriteDir(filepath.Join(dir, "corpus")); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.WriteFile(filepath.Join(dir, "corpus.txt")); err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{"corpus", "corpus.txt"} {
+			read, err := cfg.ReadCorpus(filepath.Join(dir, path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(c, read) {
+				t.Errorf("%s: expected %+v, got %+v", path, c, read)
+			}
+			mismatches, err := read.Verify(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mismatches) != 0 {
+				t.Errorf("%s: unexpected mismatches %q", path, mismatches)
+			}
+		}
+	}
+
+	gen = cfg.NewGenerator(g, 1)
+	gen.TargetLength(6, 2)
+	c, err := gen.Corpus(5, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Samples = append(c.Samples, "(]")
+	mismatches, err := c.Verify(g)
+	if !errors.Is(err, cfg.ErrMismatch) {
+		t.Errorf("expected %v, got %v", cfg.ErrMismatch, err)
+	}
+	if !reflect.DeepEqual(mismatches, []string{"(]"}) {
+		t.Errorf("expected the mismatch (], got %q", mismatches)
+	}
+
+	// A corpus of near misses fails if one of them is in the language.
+	c, err = gen.Corpus(5, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Samples = append(c.Samples, "([])", "()")
+	if mismatches, err := c.Verify(g); !errors.Is(err, cfg.ErrMismatch) || !reflect.DeepEqual(mismatches, []string{"([])", "()"}) {
+		t.Errorf("expected the mismatches ([]) and (), got %q (%v)", mismatches, err)
+	}
+}
@@ -0,0 +1,182 @@
+package cfg
+
+import (
+	"runtime"
+	"sync"
+	"unicode"
+)
+
+// CYK is a recognizer based on the Cocke–Younger–Kasami algorithm, for bulk validation of (long) strings. The grammar
+// is converted to Chomsky normal form once, the terminals are split into runes.
+type CYK struct {
+	empty bool
+	start int
+	// terminals are the variables of the productions `A → a` of every rune a.
+	terminals map[rune][]int
+	// categories are the productions `A → \p{X}` of the Unicode categories, which match a single rune each.
+	categories []cykCategory
+	binary     []cykRule
+	variables  int
+}
+
+// CYK prepares a CYK recognizer of the grammar.
+func (g *CFG) CYK() (*CYK, error) {
+	runes := g
+	split := make(map[Terminal][]Beta)
+	for _, t := range g.Alphabet {
+		if rs := []rune(string(t)); len(rs) != 1 {
+			for _, r := range rs {
+				split[t] = append(split[t], Terminal(r))
+			}
+		}
+	}
+	if len(split) != 0 {
+		var err error
+		if runes, err = g.Substitute(split); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &CYK{empty: g.AcceptsEmpty(), terminals: make(map[rune][]int)}
+	indices := map[Variable]int{runes.StartVariable: 0}
+	index := func(v Variable) int {
+		if i, ok := indices[v]; ok {
+			return i
+		}
+		indices[v] = len(indices)
+		return indices[v]
+	}
+	for _, rule := range runes.CNF() {
+		a := index(rule.A.(Variable))
+		switch len(rule.B) {
+		case 1:
+			if category, ok := rule.B[0].(Category); ok {
+				c.categories = append(c.categories, cykCategory{table: runes.symbols.category(category).table, variable: a})
+				continue
+			}
+			r := []rune(string(rule.B[0].(Terminal)))[0]
+			c.terminals[r] = append(c.terminals[r], a)
+		case 2:
+			c.binary = append(c.binary, cykRule{a: a, b: index(rule.B[0].(Variable)), c: index(rule.B[1].(Variable))})
+		}
+	}
+	c.variables = len(indices)
+	return c, nil
+}
+
+// Recognize checks whether the string is in the language. The chart is stored as bitsets: for every variable B and
+// start position i the end positions of the spans derived by B, and for every variable C and end position j the start
+// positions. A production `A → BC` derives the span (i, j) if both bitsets intersect, which replaces the loop over
+// all split positions by a few word operations. The recognizer is still cubic, unlike Valiant's reduction to matrix
+// multiplication, only with a smaller constant, see BenchmarkCYK_Recognize_splits.
+func (c *CYK) Recognize(s string) bool {
+	rs := []rune(s)
+	n := len(rs)
+	if n == 0 {
+		return c.empty
+	}
+	chart := c.newChart(n)
+	c.leaves(chart, rs)
+	for length := 2; length <= n; length++ {
+		for i := 0; i+length <= n; i++ {
+			c.span(chart, i, i+length)
+		}
+	}
+	return chart.ends[c.start][0].has(n)
+}
+
+// RecognizeParallel is Recognize, with the spans of the same length divided among the given number of goroutines. The
+// spans of the same length only depend on shorter ones, and every span writes to its own start and end positions. A
+// non-positive number of workers uses GOMAXPROCS.
+func (c *CYK) RecognizeParallel(s string, workers int) bool {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	rs := []rune(s)
+	n := len(rs)
+	if n == 0 {
+		return c.empty
+	}
+	chart := c.newChart(n)
+	c.leaves(chart, rs)
+	for length := 2; length <= n; length++ {
+		spans := n - length + 1
+		chunk := (spans + workers - 1) / workers
+		var wg sync.WaitGroup
+		for start := 0; start < spans; start += chunk {
+			end := start + chunk
+			if spans < end {
+				end = spans
+			}
+			wg.Add(1)
+			go func(start, end, length int) {
+				defer wg.Done()
+				for i := start; i < end; i++ {
+					c.span(chart, i, i+length)
+				}
+			}(start, end, length)
+		}
+		wg.Wait()
+	}
+	return chart.ends[c.start][0].has(n)
+}
+
+func (c *CYK) newChart(n int) *cykChart {
+	chart := &cykChart{ends: make([][]bitset, c.variables), starts: make([][]bitset, c.variables)}
+	for v := 0; v < c.variables; v++ {
+		chart.ends[v] = make([]bitset, n+1)
+		chart.starts[v] = make([]bitset, n+1)
+		for i := 0; i <= n; i++ {
+			chart.ends[v][i] = newBitset(n + 1)
+			chart.starts[v][i] = newBitset(n + 1)
+		}
+	}
+	return chart
+}
+
+// leaves derives the variables of the spans of a single rune.
+func (c *CYK) leaves(chart *cykChart, rs []rune) {
+	for i, r := range rs {
+		for _, a := range c.terminals[r] {
+			chart.add(a, i, i+1)
+		}
+		for _, category := range c.categories {
+			if unicode.Is(category.table, r) {
+				chart.add(category.variable, i, i+1)
+			}
+		}
+	}
+}
+
+// span derives the variables of the span (i, j), given all shorter spans.
+func (c *CYK) span(chart *cykChart, i, j int) {
+	for _, rule := range c.binary {
+		if chart.ends[rule.a][i].has(j) {
+			continue
+		}
+		if chart.ends[rule.b][i].intersects(chart.starts[rule.c][j]) {
+			chart.add(rule.a, i, j)
+		}
+	}
+}
+
+// cykChart stores for every variable and position the positions at which its spans end and start.
+type cykChart struct {
+	ends, starts [][]bitset
+}
+
+func (c *cykChart) add(v, i, j int) {
+	c.ends[v][i].set(j)
+	c.starts[v][j].set(i)
+}
+
+// cykRule is a production `A → BC` of variable indices.
+type cykRule struct {
+	a, b, c int
+}
+
+// cykCategory is a production `A → \p{X}` of a variable index.
+type cykCategory struct {
+	table    *unicode.RangeTable
+	variable int
+}
@@ -0,0 +1,63 @@
+package cfg_test
+
+import (
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func TestCFG_CYK(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		accepted []string
+		rejected []string
+	}{
+		{"S → aSa | bSb | ε\n", []string{"", "aa", "abba", "aabbaabbaa"}, []string{"a", "ab", "abab"}},
+		{"S → SS | (S) | ()\n", []string{"()", "(())()", strings.Repeat("(()())", 100)}, []string{"", "(", "())"}},
+		{"S → Sa | A\nA → Ab | ε\n", []string{"", "b", "bba", "bbbaaa"}, []string{"ab", "c"}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := g.CYK()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range test.accepted {
+			if !c.Recognize(s) {
+				t.Errorf("%q: expected %q to be accepted", test.grammar, s)
+			}
+			if !c.RecognizeParallel(s, 4) {
+				t.Errorf("%q: expected %q to be accepted in parallel", test.grammar, s)
+			}
+		}
+		for _, s := range test.rejected {
+			if c.Recognize(s) {
+				t.Errorf("%q: expected %q to be rejected", test.grammar, s)
+			}
+			if c.RecognizeParallel(s, 0) {
+				t.Errorf("%q: expected %q to be rejected in parallel", test.grammar, s)
+			}
+		}
+	}
+}
+
+func TestCFG_CYK_multiRune(t *testing.T) {
+	g, err := cfg.New(
+		cfg.V{"S"},
+		cfg.Alphabet{"if", "x"},
+		cfg.R{cfg.NewProduction(cfg.Variable("S"), cfg.Betas("if", cfg.Variable("S"))), cfg.NewProduction(cfg.Variable("S"), cfg.Betas("x"))},
+		"S",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := g.CYK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Recognize("ififx") || c.Recognize("ifix") {
+		t.Error("expected only ififx to be accepted")
+	}
+}
@@ -0,0 +1,199 @@
+package cfg
+
+import "unicode"
+
+const (
+	derivativeEmpty derivativeKind = iota
+	derivativeEpsilon
+	derivativeRune
+	derivativeCategory
+	derivativeConcat
+	derivativeAlternative
+)
+
+var (
+	// emptyNode is the language without any string, epsilonNode the language of only the empty string.
+	emptyNode   = &derivativeNode{kind: derivativeEmpty}
+	epsilonNode = &derivativeNode{kind: derivativeEpsilon}
+)
+
+func alternativeNode(a, b *derivativeNode) *derivativeNode {
+	switch {
+	case a == emptyNode:
+		return b
+	case b == emptyNode:
+		return a
+	}
+	return &derivativeNode{kind: derivativeAlternative, left: a, right: b}
+}
+
+func concatNode(a, b *derivativeNode) *derivativeNode {
+	switch {
+	case a == emptyNode || b == emptyNode:
+		return emptyNode
+	case a == epsilonNode:
+		return b
+	case b == epsilonNode:
+		return a
+	}
+	return &derivativeNode{kind: derivativeConcat, left: a, right: b}
+}
+
+// derive returns the derivative of the language with respect to the rune: the strings w for which rw is in the
+// language. Derivatives are memoized, and a node is memoized before its children are derived, so recursive languages
+// result in cyclic graphs instead of infinite recursion.
+func derive(n *derivativeNode, r rune) *derivativeNode {
+	switch n.kind {
+	case derivativeEmpty, derivativeEpsilon:
+		return emptyNode
+	case derivativeRune:
+		if n.r == r {
+			return epsilonNode
+		}
+		return emptyNode
+	case derivativeCategory:
+		if unicode.Is(n.category, r) {
+			return epsilonNode
+		}
+		return emptyNode
+	}
+	if d, ok := n.derivatives[r]; ok {
+		return d
+	}
+	if n.derivatives == nil {
+		n.derivatives = make(map[rune]*derivativeNode)
+	}
+	switch n.kind {
+	case derivativeAlternative:
+		d := &derivativeNode{kind: derivativeAlternative}
+		n.derivatives[r] = d
+		d.left, d.right = derive(n.left, r), derive(n.right, r)
+		return d
+	default:
+		if nullable(n.left) {
+			// D(ab) = D(a)b | D(b) if a derives the empty string.
+			d := &derivativeNode{kind: derivativeAlternative}
+			n.derivatives[r] = d
+			d.left, d.right = concatNode(derive(n.left, r), n.right), derive(n.right, r)
+			return d
+		}
+		d := &derivativeNode{kind: derivativeConcat, right: n.right}
+		n.derivatives[r] = d
+		d.left = derive(n.left, r)
+		return d
+	}
+}
+
+// nullable returns true if the language of the node contains the empty string. It computes the least fixed point over
+// all nodes reachable from the node whose nullability is not yet known.
+func nullable(n *derivativeNode) bool {
+	if n.nullable != nil {
+		return *n.nullable
+	}
+	var nodes []*derivativeNode
+	values := make(map[*derivativeNode]bool)
+	var collect func(n *derivativeNode)
+	collect = func(n *derivativeNode) {
+		if n.nullable != nil {
+			return
+		}
+		if _, ok := values[n]; ok {
+			return
+		}
+		values[n] = false
+		nodes = append(nodes, n)
+		if n.kind == derivativeConcat || n.kind == derivativeAlternative {
+			collect(n.left)
+			collect(n.right)
+		}
+	}
+	collect(n)
+	value := func(n *derivativeNode) bool {
+		if n.nullable != nil {
+			return *n.nullable
+		}
+		return values[n]
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, n := range nodes {
+			var v bool
+			switch n.kind {
+			case derivativeEpsilon:
+				v = true
+			case derivativeConcat:
+				v = value(n.left) && value(n.right)
+			case derivativeAlternative:
+				v = value(n.left) || value(n.right)
+			}
+			if v != values[n] {
+				values[n] = v
+				changed = true
+			}
+		}
+	}
+	for _, n := range nodes {
+		v := values[n]
+		n.nullable = &v
+	}
+	return *n.nullable
+}
+
+// EvaluateDerivatives checks whether the string is in the language by taking the derivative of the grammar with
+// respect to every rune of the string ("parsing with derivatives" by Might, Darais and Spiewak). Unlike Evaluate it
+// handles left recursion and ε without any limits, but it only recognizes the string and does not return a path.
+func (g *CFG) EvaluateDerivatives(s string) bool {
+	n := g.derivativeGraph()
+	for _, r := range s {
+		n = derive(n, r)
+		if n == emptyNode {
+			return false
+		}
+	}
+	return nullable(n)
+}
+
+// derivativeGraph returns the language of the start variable as graph of derivative nodes. Every variable is an
+// alternative node, so recursive variables are cycles in the graph.
+func (g *CFG) derivativeGraph() *derivativeNode {
+	variables := make(map[Variable]*derivativeNode)
+	for _, v := range g.Variables {
+		variables[v] = &derivativeNode{kind: derivativeAlternative, left: emptyNode, right: emptyNode}
+	}
+	for _, v := range g.Variables {
+		n := emptyNode
+		for _, rule := range g.RulesFor(v) {
+			p := epsilonNode
+			for i := len(rule.B) - 1; 0 <= i; i-- {
+				switch b := rule.B[i].(type) {
+				case Category:
+					p = concatNode(&derivativeNode{kind: derivativeCategory, category: g.symbols.category(b).table}, p)
+				case Terminal:
+					rs := []rune(string(b))
+					for j := len(rs) - 1; 0 <= j; j-- {
+						p = concatNode(&derivativeNode{kind: derivativeRune, r: rs[j]}, p)
+					}
+				case Variable:
+					p = concatNode(variables[b], p)
+				}
+			}
+			n = alternativeNode(n, p)
+		}
+		variables[v].left = n
+	}
+	return variables[g.StartVariable]
+}
+
+type derivativeKind int
+
+// derivativeNode is a node of a language: the empty language, the empty string, a single rune, a rune of a Unicode
+// category, or the concatenation or alternative of two languages.
+type derivativeNode struct {
+	kind        derivativeKind
+	r           rune
+	category    *unicode.RangeTable
+	left, right *derivativeNode
+	derivatives map[rune]*derivativeNode
+	// nullable is nil as long as it is not known.
+	nullable *bool
+}
@@ -0,0 +1,34 @@
+package cfg_test
+
+import (
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func TestCFG_EvaluateDerivatives(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		accepted []string
+		rejected []string
+	}{
+		{"S → aSa | bSb | ε\n", []string{"", "aa", "abba", "aabbaabbaa"}, []string{"a", "ab", "abab"}},
+		{"S → SS | (S) | ()\n", []string{"()", "(())()", "((()()))"}, []string{"", "(", "())"}},
+		{"S → Sa | A\nA → Ab | ε\n", []string{"", "b", "bba", "bbbaaa"}, []string{"ab", "c"}},
+		{"S → AB\nA → aA | ε\nB → Bb | ε\n", []string{"", "aab", "abbb"}, []string{"ba"}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range test.accepted {
+			if !g.EvaluateDerivatives(s) {
+				t.Errorf("%q: expected %q to be accepted", test.grammar, s)
+			}
+		}
+		for _, s := range test.rejected {
+			if g.EvaluateDerivatives(s) {
+				t.Errorf("%q: expected %q to be rejected", test.grammar, s)
+			}
+		}
+	}
+}
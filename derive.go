@@ -0,0 +1,69 @@
+package cfg
+
+import "sync"
+
+// Derive returns true if the variable derives the string, independent of the strategy and the limits of the grammar.
+// All variables are recognized at once and the result is memoized per string, so checking the same string against
+// many variables, e.g. the fields of a record against the variables of one grammar, only parses it once. The memo is
+// shared by all calls and is safe for concurrent use, it holds up to deriveMemoSize strings and is cleared whenever the
+// disambiguation filters or the fields mode of the grammar change.
+func (g *CFG) Derive(v Variable, s string) bool {
+	i, ok := g.symbols.variable[v]
+	if !ok {
+		return false
+	}
+	return g.memo.derived(g, s).has(i)
+}
+
+// deriveMemoSize is the maximum number of strings of a deriveMemo, an arbitrary one is evicted to make room for more.
+const deriveMemoSize = 4096
+
+func newDeriveMemo() *deriveMemo {
+	return &deriveMemo{strings: make(map[string]bitset)}
+}
+
+// deriveMemo are the variables that derive a string, see Derive.
+type deriveMemo struct {
+	mu      sync.Mutex
+	strings map[string]bitset
+}
+
+// derived returns the variables that derive the whole string.
+func (m *deriveMemo) derived(g *CFG, s string) bitset {
+	m.mu.Lock()
+	vs, ok := m.strings[s]
+	m.mu.Unlock()
+	g.metrics.lookedUp(ok)
+	if ok {
+		return vs
+	}
+	t := g.symbols
+	input := g.input(s)
+	search := g.spanSearch(input, func(int) float64 { return 1 }, nil)
+	vs = newBitset(len(t.variables))
+	for i := range t.variables {
+		if search.final[chartItem{variable: i, rule: -1, i: 0, j: len(input)}] {
+			vs.set(i)
+		}
+	}
+	if search.exceeded {
+		return vs
+	}
+	m.mu.Lock()
+	if deriveMemoSize <= len(m.strings) {
+		for evicted := range m.strings {
+			delete(m.strings, evicted)
+			break
+		}
+	}
+	m.strings[s] = vs
+	m.mu.Unlock()
+	return vs
+}
+
+// reset forgets all strings, it is called whenever the grammar changes in a way that affects Derive.
+func (m *deriveMemo) reset() {
+	m.mu.Lock()
+	m.strings = make(map[string]bitset)
+	m.mu.Unlock()
+}
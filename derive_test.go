@@ -0,0 +1,64 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"sync"
+	"testing"
+)
+
+func ExampleCFG_Derive() {
+	g, _ := cfg.Parse(`S → N\=W
+N → D | DN
+D → \0 | \1
+W → a | aW
+`)
+	for _, field := range []string{"101", "aa", "1=a"} {
+		fmt.Println(field, g.Derive("N", field), g.Derive("W", field), g.Derive("S", field))
+	}
+	// Output:
+	// 101 true false false
+	// aa false true false
+	// 1=a false false true
+}
+
+func TestCFG_Derive(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, s := range []string{"", "aa", "abba", "ab"} {
+				if g.Derive("S", s) != (s != "ab") {
+					t.Errorf("%q: unexpected result", s)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if g.Derive("X", "") {
+		t.Error("unknown variable derives ε")
+	}
+}
+
+func TestCFG_Derive_reject(t *testing.T) {
+	g, err := cfg.Parse("S → Ib | I\nI → aI | a\nK → aa\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Derive("S", "aa") || !g.Derive("S", "ab") {
+		t.Fatal("expected aa and ab to be derived")
+	}
+	if err := g.Reject("I", []cfg.Beta{cfg.Variable("K")}); err != nil {
+		t.Fatal(err)
+	}
+	if g.Derive("S", "aa") || !g.Derive("S", "a") {
+		t.Error("expected only aa to be rejected")
+	}
+	if err := g.FollowRestriction("I", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Derive("S", "ab") {
+		t.Error("expected ab to be rejected, I is followed by b")
+	}
+}
@@ -0,0 +1,160 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DiffTest compares the language of the grammar with an oracle, e.g. a handwritten parser, on random strings of the
+// language, near misses (see NearMisses) and optionally all short sequences of terminals. It returns the strings on
+// which both disagree, together with a minimized counterexample of each. The grammar is decided exactly by CYK. If the
+// language has too few strings of the target length, strings of other lengths are tested as well. If it has fewer
+// distinct strings or near misses than requested, the ones that were found are tested, and the disagreements are
+// returned together with an error wrapping ErrExhausted.
+func DiffTest(g *CFG, oracle func(string) bool, opts DiffOptions) ([]Disagreement, error) {
+	recognizer, err := g.CYK()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Samples == 0 {
+		opts.Samples = 100
+	}
+	if opts.Length == 0 {
+		opts.Length, opts.Tolerance = 16, 8
+	}
+	gen := NewGenerator(g, opts.Seed)
+	var inputs []string
+	var shortfalls []error
+	for _, negative := range []bool{false, true} {
+		samples, err := diffSamples(gen, opts, negative)
+		if errors.Is(err, ErrExhausted) {
+			shortfalls = append(shortfalls, err)
+		} else if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, samples...)
+	}
+	inputs = append(inputs, sequences(g.Alphabet, opts.Exhaustive)...)
+
+	seen := make(map[string]bool)
+	var disagreements []Disagreement
+	for _, s := range inputs {
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		accepted := recognizer.Recognize(s)
+		if accepted == oracle(s) {
+			continue
+		}
+		disagree := func(s string) bool {
+			return recognizer.Recognize(s) == accepted && oracle(s) != accepted
+		}
+		disagreements = append(disagreements, Disagreement{
+			Input:     s,
+			Minimized: minimize(s, disagree),
+			Grammar:   accepted,
+		})
+	}
+	return disagreements, errors.Join(shortfalls...)
+}
+
+// diffSamples returns distinct strings of the language, or near misses, of the target length of the options. If there
+// are not enough of them, strings of any length make up the rest.
+func diffSamples(gen *Generator, opts DiffOptions, negative bool) ([]string, error) {
+	generate := gen.GenerateN
+	if negative {
+		generate = gen.NearMisses
+	}
+	gen.TargetLength(opts.Length, opts.Tolerance)
+	samples, err := generate(opts.Samples)
+	if !errors.Is(err, ErrExhausted) {
+		return samples, err
+	}
+	gen.TargetLength(-1, 0)
+	more, err := generate(opts.Samples)
+	if err != nil && !errors.Is(err, ErrExhausted) {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, s := range samples {
+		seen[s] = true
+	}
+	for _, s := range more {
+		if !seen[s] && len(samples) < opts.Samples {
+			seen[s] = true
+			samples = append(samples, s)
+		}
+	}
+	if len(samples) < opts.Samples {
+		kind := "strings of the language"
+		if negative {
+			kind = "near misses"
+		}
+		return samples, fmt.Errorf("%w: found only %d of %d %s", ErrExhausted, len(samples), opts.Samples, kind)
+	}
+	return samples, nil
+}
+
+// minimize removes runes from the string as long as the disagreement persists, first in large chunks, then in smaller
+// ones. The result is 1-minimal: removing any single rune resolves the disagreement.
+func minimize(s string, disagree func(string) bool) string {
+	rs := []rune(s)
+	for n := len(rs) / 2; 0 < n; {
+		removed := false
+		for i := 0; i+n <= len(rs); {
+			candidate := append(append([]rune{}, rs[:i]...), rs[i+n:]...)
+			if disagree(string(candidate)) {
+				rs, removed = candidate, true
+				continue
+			}
+			i += n
+		}
+		if !removed || len(rs) < n {
+			n /= 2
+		}
+	}
+	return string(rs)
+}
+
+// sequences returns all sequences of at most n terminals, ordered by length.
+func sequences(alphabet Alphabet, n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	strs := []string{""}
+	last := strs
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, s := range last {
+			for _, t := range alphabet {
+				next = append(next, s+string(t))
+			}
+		}
+		strs = append(strs, next...)
+		last = next
+	}
+	return strs
+}
+
+// DiffOptions configures DiffTest.
+type DiffOptions struct {
+	// Seed is the seed of the generator.
+	Seed int64
+	// Samples is the number of strings of the language and of near misses, defaults to 100 of each.
+	Samples int
+	// Length and Tolerance are the target length of the generated strings, default to 16 ± 8.
+	Length, Tolerance int
+	// Exhaustive is the maximal number of terminals of the sequences of terminals that are all tested, 0 tests none.
+	Exhaustive int
+}
+
+// Disagreement is a string on which the grammar and the oracle disagree.
+type Disagreement struct {
+	Input string
+	// Minimized is a subsequence of the input on which both still disagree in the same way, removing any single rune of
+	// it resolves the disagreement.
+	Minimized string
+	// Grammar is true if the string is in the language of the grammar, and the oracle rejected it.
+	Grammar bool
+}
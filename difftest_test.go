@@ -0,0 +1,76 @@
+package cfg_test
+
+import (
+	"errors"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/grammars"
+	"strings"
+	"testing"
+)
+
+func TestDiffTest(t *testing.T) {
+	g := grammars.Dyck()
+	// A balanced checker that does not distinguish the kinds of brackets.
+	oracle := func(s string) bool {
+		depth := 0
+		for _, r := range s {
+			switch r {
+			case '(', '[':
+				depth++
+			case ')', ']':
+				if depth--; depth < 0 {
+					return false
+				}
+			}
+		}
+		return depth == 0
+	}
+	correct := func(s string) bool {
+		for strings.Contains(s, "()") || strings.Contains(s, "[]") {
+			s = strings.ReplaceAll(strings.ReplaceAll(s, "()", ""), "[]", "")
+		}
+		return s == ""
+	}
+	disagreements, err := cfg.DiffTest(g, oracle, cfg.DiffOptions{Seed: 1, Samples: 20, Exhaustive: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disagreements) == 0 {
+		t.Fatal("expected disagreements")
+	}
+	shorter := false
+	for _, d := range disagreements {
+		shorter = shorter || len(d.Minimized) < len(d.Input)
+		if d.Grammar {
+			t.Errorf("expected the grammar to reject %q", d.Input)
+		}
+		if m := d.Minimized; len(m) > len(d.Input) || !oracle(m) || correct(m) {
+			t.Errorf("expected a counterexample of %q, got %q", d.Input, m)
+		}
+	}
+	if !shorter {
+		t.Error("expected minimized counterexamples")
+	}
+
+	disagreements, err = cfg.DiffTest(g, correct, cfg.DiffOptions{Seed: 1, Samples: 20, Exhaustive: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(disagreements) != 0 {
+		t.Errorf("unexpected disagreements %v", disagreements)
+	}
+}
+
+func TestDiffTest_finite(t *testing.T) {
+	g, err := cfg.Parse("S → a | b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	disagreements, err := cfg.DiffTest(g, func(s string) bool { return s == "a" }, cfg.DiffOptions{Seed: 1, Samples: 20})
+	if !errors.Is(err, cfg.ErrExhausted) {
+		t.Errorf("expected %v, got %v", cfg.ErrExhausted, err)
+	}
+	if len(disagreements) != 1 || disagreements[0].Input != "b" || !disagreements[0].Grammar {
+		t.Errorf("expected a disagreement on b, got %v", disagreements)
+	}
+}
@@ -0,0 +1,144 @@
+package cfg
+
+var (
+	// LeftAssociative prefers the tree in which the leftmost operand is larger, e.g. `(a-b)-c` over `a-(b-c)`. It is
+	// the same as LongestMatch, but only if both trees applied the same production at the node where they differ, so
+	// it does not decide between different operators.
+	LeftAssociative Disambiguator = DisambiguatorFunc(leftAssociative)
+	// LongestMatch prefers the tree in which the leftmost child that differs derives the longer substring, at the
+	// first node at which the trees differ, e.g. a dangling else binds to the innermost if.
+	LongestMatch Disambiguator = DisambiguatorFunc(longestMatch)
+)
+
+// Chain combines the disambiguators, the first one with a preference decides.
+func Chain(ds ...Disambiguator) Disambiguator {
+	return DisambiguatorFunc(func(a, b *Tree) int {
+		for _, d := range ds {
+			if c := d.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// RulePriority prefers the tree that applies the production that comes first in the given rules, at the first node at
+// which the trees apply different productions. Listing the productions of operators with a lower precedence first puts
+// them closer to the root, e.g. `a+(b*c)` over `(a+b)*c`.
+func RulePriority(rules R) Disambiguator {
+	priority := make(map[string]int)
+	for i, rule := range rules {
+		if _, ok := priority[rule.key()]; !ok {
+			priority[rule.key()] = i
+		}
+	}
+	return DisambiguatorFunc(func(a, b *Tree) int {
+		x, y, ok := firstDifference(a, b)
+		if !ok || x.Production.Equal(*y.Production) {
+			return 0
+		}
+		px, okX := priority[x.Production.key()]
+		py, okY := priority[y.Production.key()]
+		if !okX || !okY {
+			return 0
+		}
+		return px - py
+	})
+}
+
+// compareSpans compares the lengths of the substrings derived by the children, the first longer child is preferred.
+func compareSpans(a, b *Tree) int {
+	for i := 0; i < len(a.Children) && i < len(b.Children); i++ {
+		if la, lb := a.Children[i].yieldLength(), b.Children[i].yieldLength(); la != lb {
+			if la > lb {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// firstDifference returns the first nodes, in pre-order, at which both trees apply different productions or their
+// children derive different substrings. Both trees must derive the same string.
+func firstDifference(a, b *Tree) (*Tree, *Tree, bool) {
+	if a.Production == nil || b.Production == nil {
+		return nil, nil, false
+	}
+	if !a.Production.Equal(*b.Production) || compareSpans(a, b) != 0 {
+		return a, b, true
+	}
+	for i := range a.Children {
+		if x, y, ok := firstDifference(a.Children[i], b.Children[i]); ok {
+			return x, y, true
+		}
+	}
+	return nil, nil, false
+}
+
+func leftAssociative(a, b *Tree) int {
+	x, y, ok := firstDifference(a, b)
+	if !ok || !x.Production.Equal(*y.Production) {
+		return 0
+	}
+	return compareSpans(x, y)
+}
+
+func longestMatch(a, b *Tree) int {
+	x, y, ok := firstDifference(a, b)
+	if !ok {
+		return 0
+	}
+	return compareSpans(x, y)
+}
+
+// Disambiguator chooses between two derivation trees of the same string. Compare returns a negative number if a is
+// preferred, a positive number if b is preferred, and zero if there is no preference.
+type Disambiguator interface {
+	Compare(a, b *Tree) int
+}
+
+// DisambiguatorFunc is a function that implements Disambiguator.
+type DisambiguatorFunc func(a, b *Tree) int
+
+func (f DisambiguatorFunc) Compare(a, b *Tree) int {
+	return f(a, b)
+}
+
+// EvaluateWith returns the derivation of the given string that is preferred by the disambiguator, among all
+// derivations found by EvaluateAll. If there is no preference, the derivation that is found first is returned, which
+// is the one of Evaluate.
+func (g *CFG) EvaluateWith(s string, d Disambiguator) (Path, bool) {
+	var best *Tree
+	for _, p := range g.EvaluateAll(s) {
+		t, err := p.Tree()
+		if err != nil {
+			continue
+		}
+		if best == nil || d.Compare(t, best) < 0 {
+			best = t
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+	return best.Path(), true
+}
+
+// yieldLength returns the length (in bytes) of the string derived by the tree, a category counts as a single byte.
+func (t *Tree) yieldLength() int {
+	if len(t.Children) == 0 {
+		switch t := t.Symbol.(type) {
+		case Terminal:
+			return len(t)
+		case Category:
+			return 1
+		}
+		return 0
+	}
+	var n int
+	for _, c := range t.Children {
+		n += c.yieldLength()
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_EvaluateWith() {
+	g, _ := cfg.Parse("E → EpE | EmE | x\n")
+	p, _ := g.EvaluateWith("xpxpx", cfg.LeftAssociative)
+	t, _ := p.Tree()
+	fmt.Println(t)
+	// The productions of p come first, so p has a lower precedence than m.
+	p, _ = g.EvaluateWith("xmxpx", cfg.RulePriority(g.Rules))
+	t, _ = p.Tree()
+	fmt.Println(t)
+	// Output:
+	// E(E(E(x) p E(x)) p E(x))
+	// E(E(E(x) m E(x)) p E(x))
+}
+
+func TestCFG_EvaluateAll(t *testing.T) {
+	g, err := cfg.Parse("E → EpE | x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The number of binary trees with 4 leaves.
+	if n := len(g.EvaluateAll("xpxpxpx")); n != 5 {
+		t.Errorf("expected 5 derivations, got %d", n)
+	}
+	if n := len(g.EvaluateAll("xp")); n != 0 {
+		t.Errorf("expected no derivations, got %d", n)
+	}
+}
+
+func TestLongestMatch(t *testing.T) {
+	// The dangling else: i(f) S | i(f) S e S, with s a statement.
+	g, err := cfg.Parse("S → iS | iSeS | s\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(g.EvaluateAll("iisese")); n != 0 {
+		t.Fatalf("expected no derivations, got %d", n)
+	}
+	p, ok := g.EvaluateWith("iises", cfg.LongestMatch)
+	if !ok {
+		t.Fatal("expected a derivation")
+	}
+	tree, _ := p.Tree()
+	if s := tree.String(); s != "S(i S(i S(s) e S(s)))" {
+		t.Errorf("expected the else to bind to the inner if, got %s", s)
+	}
+	p, _ = g.EvaluateWith("iises", cfg.Chain(cfg.LeftAssociative, cfg.RulePriority(cfg.R{g.Rules[1], g.Rules[0]})))
+	tree, _ = p.Tree()
+	if s := tree.String(); s != "S(i S(i S(s)) e S(s))" {
+		t.Errorf("expected the else to bind to the outer if, got %s", s)
+	}
+}
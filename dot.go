@@ -0,0 +1,77 @@
+package cfg
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// dotEscape escapes a string for use in a double-quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// DOT renders the automaton in the Graphviz DOT language. States with conflicts are colored red.
+func (a *LR0) DOT() string {
+	conflicts := make(map[int]bool)
+	for _, i := range a.Conflicts() {
+		conflicts[i] = true
+	}
+	var s strings.Builder
+	s.WriteString("digraph LR0 {\n\trankdir=LR;\n\tnode [shape=box, fontname=monospace];\n")
+	for i, items := range a.States {
+		var label strings.Builder
+		fmt.Fprintf(&label, "I%d\\l", i)
+		for _, item := range items {
+			label.WriteString(dotEscape(item.String()) + `\l`)
+		}
+		var color string
+		if conflicts[i] {
+			color = ", color=red"
+		}
+		fmt.Fprintf(&s, "\tI%d [label=\"%s\"%s];\n", i, label.String(), color)
+	}
+	for i, transitions := range a.Transitions {
+		// Iterate the items to get a deterministic order of the edges.
+		seen := make(map[Beta]bool)
+		for _, item := range a.States[i] {
+			next := item.Next()
+			if next == nil || seen[next] {
+				continue
+			}
+			seen[next] = true
+			fmt.Fprintf(&s, "\tI%d -> I%d [label=\"%s\"];\n", i, transitions[next], dotEscape(next.String()))
+		}
+	}
+	s.WriteString("}\n")
+	return s.String()
+}
+
+// DOT renders the table in the Graphviz DOT language as an HTML-like matrix. Conflicting cells are colored red.
+func (t *PredictiveTable) DOT() string {
+	var s strings.Builder
+	s.WriteString("digraph LL1 {\n\tnode [shape=plaintext, fontname=monospace];\n")
+	s.WriteString("\ttable [label=<<TABLE BORDER=\"0\" CELLBORDER=\"1\" CELLSPACING=\"0\">\n\t\t<TR><TD></TD>")
+	for _, terminal := range t.Terminals {
+		fmt.Fprintf(&s, "<TD><B>%s</B></TD>", html.EscapeString(terminal.String()))
+	}
+	s.WriteString("</TR>\n")
+	for _, v := range t.Variables {
+		fmt.Fprintf(&s, "\t\t<TR><TD><B>%s</B></TD>", html.EscapeString(v.String()))
+		for _, terminal := range t.Terminals {
+			ps := t.Cells[v][terminal]
+			var cells []string
+			for _, p := range ps {
+				cells = append(cells, html.EscapeString(p.String()))
+			}
+			if 1 < len(ps) {
+				fmt.Fprintf(&s, "<TD BGCOLOR=\"red\">%s</TD>", strings.Join(cells, "<BR/>"))
+				continue
+			}
+			fmt.Fprintf(&s, "<TD>%s</TD>", strings.Join(cells, "<BR/>"))
+		}
+		s.WriteString("</TR>\n")
+	}
+	s.WriteString("\t</TABLE>>];\n}\n")
+	return s.String()
+}
@@ -0,0 +1,239 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrUnbalanced is returned if a string is not balanced in a bracket-matching grammar, see Dyck.
+var ErrUnbalanced = errors.New("unbalanced brackets")
+
+// Dyck returns the brackets of the grammar if it is a bracket-matching grammar, i.e. its language is the Dyck language
+// of its pairs of brackets. The grammar must have a single variable S with an ε-production, and every pair of
+// brackets must be enclosed by one of `S → (S)`, `S → (S)S` or `S → S(S)`. Unless the grammar has `S → SS`, all pairs
+// must use the form `(S)S`, or all the form `S(S)`. Such grammars can be evaluated in linear time, see Brackets.
+func (g *CFG) Dyck() (*Dyck, bool) {
+	return g.dyck, g.dyck != nil
+}
+
+// newDyck detects whether the grammar is a bracket-matching grammar, see CFG.Dyck.
+func newDyck(rules R, start Variable) *Dyck {
+	d := &Dyck{start: start}
+	pair := func(open, close Beta) (int, bool) {
+		// A Unicode category matches many runes, it is not a Terminal and so never a bracket.
+		o, ok := open.(Terminal)
+		c, isTerminal := close.(Terminal)
+		if !ok || !isTerminal || o == c {
+			return 0, false
+		}
+		for i, p := range d.Pairs {
+			if p[0] == o && p[1] == c {
+				return i, true
+			}
+			if p[0] == o || p[0] == c || p[1] == o || p[1] == c {
+				return 0, false
+			}
+		}
+		d.Pairs = append(d.Pairs, [2]Terminal{o, c})
+		d.wraps = append(d.wraps, [3]*Production{})
+		return len(d.Pairs) - 1, true
+	}
+	var redundant [][2]Beta
+	for i, rule := range rules {
+		if rule.A != start {
+			return nil
+		}
+		var b []Beta
+		for _, beta := range rule.B {
+			if beta == Epsilon {
+				continue
+			}
+			if _, ok := beta.(Variable); ok && beta != start {
+				return nil
+			}
+			b = append(b, beta)
+		}
+		shape := make([]byte, len(b))
+		for j, beta := range b {
+			shape[j] = 't'
+			if beta == start {
+				shape[j] = 'S'
+			}
+		}
+		p := &rules[i]
+		switch string(shape) {
+		case "":
+			if d.empty == nil {
+				d.empty = p
+			}
+		case "S":
+		case "SS":
+			if d.concat == nil {
+				d.concat = p
+			}
+		case "tSt", "tStS", "StSt":
+			k := map[string]int{"tSt": 0, "tStS": 1, "StSt": 2}[string(shape)]
+			open := strings.IndexByte(string(shape), 't')
+			i, ok := pair(b[open], b[open+2])
+			if !ok {
+				return nil
+			}
+			if d.wraps[i][k] == nil {
+				d.wraps[i][k] = p
+			}
+		case "tt", "ttS", "Stt":
+			// Adjacent brackets are derived by the other forms, since S is nullable.
+			open := strings.IndexByte(string(shape), 't')
+			redundant = append(redundant, [2]Beta{b[open], b[open+1]})
+		default:
+			return nil
+		}
+	}
+	if d.empty == nil || len(d.Pairs) == 0 {
+		return nil
+	}
+	for _, r := range redundant {
+		if i, ok := pair(r[0], r[1]); !ok || d.wraps[i] == [3]*Production{} {
+			return nil
+		}
+	}
+	prefix, suffix := true, true
+	for _, w := range d.wraps {
+		if w == [3]*Production{} {
+			return nil
+		}
+		prefix = prefix && w[1] != nil
+		suffix = suffix && w[2] != nil
+	}
+	if d.concat == nil && !prefix && !suffix {
+		return nil
+	}
+	// The brackets are matched by their prefixes, so none may be the prefix of another.
+	for i, p := range d.Pairs {
+		for j, q := range d.Pairs {
+			for _, a := range p {
+				for _, b := range q {
+					if (i != j || a != b) && strings.HasPrefix(string(b), string(a)) {
+						return nil
+					}
+				}
+			}
+		}
+	}
+	d.prefix, d.suffix = prefix, suffix
+	return d
+}
+
+// Dyck validates the strings of a bracket-matching grammar with a stack, see CFG.Dyck.
+type Dyck struct {
+	// Pairs are the opening and closing brackets.
+	Pairs [][2]Terminal
+
+	start Variable
+	// empty and concat are the productions `S → ε` and `S → SS`.
+	empty, concat *Production
+	// wraps are the productions `S → (S)`, `S → (S)S` and `S → S(S)` of every pair.
+	wraps [][3]*Production
+	// prefix and suffix are true if all pairs have the form `(S)S`, or the form `S(S)`.
+	prefix, suffix bool
+}
+
+// Path returns a leftmost derivation of the string.
+func (d *Dyck) Path(s string) (Path, error) {
+	root, err := d.parse(s)
+	if err != nil {
+		return nil, err
+	}
+	var p Path
+	d.derive(root, &p)
+	return p, nil
+}
+
+// Validate returns an error wrapping ErrUnbalanced if the brackets of the string do not match, or a
+// *ForeignSymbolError if it contains anything else.
+func (d *Dyck) Validate(s string) error {
+	_, err := d.parse(s)
+	return err
+}
+
+// derive appends the leftmost derivation of the sequence of bracketed groups.
+func (d *Dyck) derive(groups []*dyckGroup, p *Path) {
+	switch {
+	case len(groups) == 0:
+		*p = append(*p, *d.empty)
+	case d.prefix:
+		*p = append(*p, *d.wraps[groups[0].pair][1])
+		d.derive(groups[0].children, p)
+		d.derive(groups[1:], p)
+	case d.suffix:
+		last := groups[len(groups)-1]
+		*p = append(*p, *d.wraps[last.pair][2])
+		d.derive(groups[:len(groups)-1], p)
+		d.derive(last.children, p)
+	case 1 < len(groups):
+		*p = append(*p, *d.concat)
+		d.derive(groups[:1], p)
+		d.derive(groups[1:], p)
+	default:
+		group := groups[0]
+		w := d.wraps[group.pair]
+		switch {
+		case w[0] != nil:
+			*p = append(*p, *w[0])
+			d.derive(group.children, p)
+		case w[1] != nil:
+			*p = append(*p, *w[1])
+			d.derive(group.children, p)
+			*p = append(*p, *d.empty)
+		default:
+			*p = append(*p, *w[2], *d.empty)
+			d.derive(group.children, p)
+		}
+	}
+}
+
+// parse matches the brackets with a stack and returns the top-level groups.
+func (d *Dyck) parse(s string) ([]*dyckGroup, error) {
+	root := &dyckGroup{pair: -1}
+	stack := []*dyckGroup{root}
+	for i := 0; i < len(s); {
+		matched := false
+		for j, pair := range d.Pairs {
+			top := stack[len(stack)-1]
+			switch {
+			case strings.HasPrefix(s[i:], string(pair[0])):
+				group := &dyckGroup{pair: j, offset: i}
+				top.children = append(top.children, group)
+				stack = append(stack, group)
+				i += len(pair[0])
+			case strings.HasPrefix(s[i:], string(pair[1])):
+				if top.pair != j {
+					return nil, fmt.Errorf("%w: unmatched %s at offset %d", ErrUnbalanced, pair[1], i)
+				}
+				stack = stack[:len(stack)-1]
+				i += len(pair[1])
+			default:
+				continue
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			r, _ := utf8.DecodeRuneInString(s[i:])
+			return nil, &ForeignSymbolError{Offset: i, Symbol: r}
+		}
+	}
+	if top := stack[len(stack)-1]; top != root {
+		return nil, fmt.Errorf("%w: unclosed %s at offset %d", ErrUnbalanced, d.Pairs[top.pair][0], top.offset)
+	}
+	return root.children, nil
+}
+
+// dyckGroup is a pair of matching brackets and the groups enclosed by them.
+type dyckGroup struct {
+	pair     int
+	offset   int
+	children []*dyckGroup
+}
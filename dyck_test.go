@@ -0,0 +1,96 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func ExampleCFG_Dyck() {
+	g, _ := cfg.Parse("S → (S)S | [S]S | ε\n")
+	d, _ := g.Dyck()
+	fmt.Println(d.Pairs)
+	fmt.Println(d.Validate("([]())"))
+	fmt.Println(d.Validate("([)]"))
+	g.SetStrategy(cfg.Brackets)
+	p, _ := g.Evaluate("[]()")
+	fmt.Println(p.Replay())
+	// Output:
+	// [[( )] [[ ]]]
+	// <nil>
+	// unbalanced brackets: unmatched ) at offset 2
+	// S → [S]S → []S → [](S)S → []()S → []()
+}
+
+func TestCFG_Dyck(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		dyck    bool
+	}{
+		{"S → (S)S | ε\n", true},
+		{"S → S(S) | S[S] | ε\n", true},
+		{"S → SS | (S) | [S]S | ε\n", true},
+		{"S → SS | (S) | () | ε\n", true},
+		// Not closed under concatenation.
+		{"S → (S) | ε\n", false},
+		{"S → (S)S | S[S] | ε\n", false},
+		// The empty string is missing.
+		{"S → SS | (S) | ()\n", false},
+		{"S → (S)S | (S] | ε\n", false},
+		{"S → (A)S | ε\nA → a\n", false},
+		{"S → aSa | bSb | ε\n", false},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, ok := g.Dyck()
+		if ok != test.dyck {
+			t.Errorf("%s: expected %v, got %v", test.grammar, test.dyck, ok)
+		}
+		if !ok {
+			continue
+		}
+		g.SetStrategy(cfg.Brackets)
+		for _, s := range []string{"", "()", "(()())()", "([])[]", "(", ")(", "([)]", "(a)"} {
+			p, ok := g.Evaluate(s)
+			exact := g.Derive(g.StartVariable, s)
+			if ok != exact || (d.Validate(s) == nil) != exact {
+				t.Errorf("%s: %q: expected %v, got %v", test.grammar, s, exact, ok)
+			}
+			if !ok {
+				continue
+			}
+			tree, err := p.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u, err := tree.Unparse(g); err != nil || u != s {
+				t.Errorf("%s: %q: invalid derivation %s", test.grammar, s, p.Replay())
+			}
+		}
+	}
+}
+
+func TestDyck_Validate(t *testing.T) {
+	g, err := cfg.Parse("S → (S)S | [S]S | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, _ := g.Dyck()
+	for _, test := range []struct {
+		input string
+		err   error
+	}{
+		{strings.Repeat("([]", 10000) + strings.Repeat(")", 10000), nil},
+		{"(()", cfg.ErrUnbalanced},
+		{"())", cfg.ErrUnbalanced},
+		{"(x)", cfg.ErrForeignSymbol},
+	} {
+		if err := d.Validate(test.input); !errors.Is(err, test.err) {
+			t.Errorf("expected %v, got %v", test.err, err)
+		}
+	}
+}
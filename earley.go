@@ -0,0 +1,190 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DerivesSentential checks whether the sentential form, a sequence of terminals and variables, can be derived from
+// the start variable. Unlike Evaluate it is exact, it does not depend on the order of the rules or any limits.
+func (g *CFG) DerivesSentential(form []Beta) bool {
+	var tokens []Beta
+	for _, b := range form {
+		if b != Epsilon {
+			tokens = append(tokens, b)
+		}
+	}
+	chart := g.earley(tokens)
+	for _, item := range chart[len(tokens)] {
+		if item.production < 0 && item.dot == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// EarleyChart returns the Earley chart of the sentential form (see DerivesSentential), to debug why a form is
+// accepted or rejected. The start item is the augmented production `S' → S`.
+func (g *CFG) EarleyChart(form []Beta) *EarleyChart {
+	c := new(EarleyChart)
+	for _, b := range form {
+		if b != Epsilon {
+			c.Tokens = append(c.Tokens, b)
+		}
+	}
+	chart := g.earley(c.Tokens)
+	production := g.earleyProductions()
+	for k, set := range chart {
+		items := make([]EarleyItem, len(set))
+		for i, item := range set {
+			if k == len(chart)-1 && item.production < 0 && item.dot == 1 {
+				c.accepted = true
+			}
+			items[i] = EarleyItem{Production: production(item.production), Dot: item.dot, Origin: item.origin}
+		}
+		c.Sets = append(c.Sets, items)
+	}
+	return c
+}
+
+// earley builds the Earley chart of the given symbols. Next to terminals, the symbols can contain variables that are
+// matched by the same variable in a production, so the chart recognizes sentential forms. The augmented start item
+// has production -1.
+func (g *CFG) earley(tokens []Beta) [][]earleyItem {
+	nullable := g.nullable()
+	rules := make(map[Variable][]int)
+	for i, rule := range g.Rules {
+		rules[rule.A.(Variable)] = append(rules[rule.A.(Variable)], i)
+	}
+	production := g.earleyProductions()
+
+	chart := make([][]earleyItem, len(tokens)+1)
+	seen := make([]map[earleyItem]bool, len(tokens)+1)
+	for k := range seen {
+		seen[k] = make(map[earleyItem]bool)
+	}
+	// items counts the items of the chart, for the resource limits.
+	var items int
+	var exceeded bool
+	limit := g.resources.ChartItems
+	add := func(k int, item earleyItem) {
+		if !seen[k][item] {
+			if 0 < limit && limit <= items {
+				exceeded = true
+				return
+			}
+			items++
+			seen[k][item] = true
+			chart[k] = append(chart[k], item)
+		}
+	}
+	add(0, earleyItem{production: -1})
+	for k := range chart {
+		for i := 0; i < len(chart[k]); i++ {
+			item := chart[k][i]
+			p := production(item.production)
+			if item.dot == len(p.B) {
+				// Complete: advance all items of the origin that wait for the variable.
+				for _, other := range chart[item.origin] {
+					if o := production(other.production); other.dot < len(o.B) && o.B[other.dot] == p.A.(Variable) {
+						add(k, earleyItem{production: other.production, dot: other.dot + 1, origin: other.origin})
+					}
+				}
+				continue
+			}
+			next := item
+			next.dot++
+			b := p.B[item.dot]
+			if b == Epsilon {
+				add(k, next)
+				continue
+			}
+			// Scan: the symbol itself is the next token.
+			if k < len(tokens) && tokens[k] == b {
+				add(k+1, next)
+			}
+			if v, ok := b.(Variable); ok {
+				// Predict: the variable is derived.
+				for _, j := range rules[v] {
+					add(k, earleyItem{production: j, origin: k})
+				}
+				if nullable[v] {
+					// A nullable variable is completed within the same set, before the item is added.
+					add(k, next)
+				}
+			}
+		}
+	}
+	if exceeded {
+		// The chart is incomplete, so the tokens are rejected.
+		chart[len(tokens)] = nil
+	}
+	return chart
+}
+
+// earleyItem is a production of the grammar with a dot, and the index of the chart in which it was predicted.
+type earleyItem struct {
+	production int
+	dot        int
+	origin     int
+}
+
+// earleyProductions returns the productions of the Earley items, -1 is the augmented start production.
+func (g *CFG) earleyProductions() func(i int) Production {
+	start := Production{A: g.augmentedStart(), B: []Beta{g.StartVariable}}
+	return func(i int) Production {
+		if i < 0 {
+			return start
+		}
+		return g.Rules[i]
+	}
+}
+
+// EarleyChart is the chart of the Earley recognizer: for every position in the tokens, the set of items that were
+// predicted, scanned or completed at that position, in the order they were added.
+type EarleyChart struct {
+	Tokens []Beta
+	Sets   [][]EarleyItem
+
+	accepted bool
+}
+
+// Accepted returns true if the tokens are derived from the start variable, i.e. the start item is completed in the
+// last set.
+func (c *EarleyChart) Accepted() bool {
+	return c.accepted
+}
+
+// String dumps the chart, one set per position together with the token that was scanned to reach it, e.g.
+//
+//	0:
+//	  S' → •S (0)
+//	1: a
+//	  S → a•Sb (0)
+func (c *EarleyChart) String() string {
+	var b strings.Builder
+	for k, set := range c.Sets {
+		if k == 0 {
+			b.WriteString("0:\n")
+		} else {
+			fmt.Fprintf(&b, "%d: %v\n", k, c.Tokens[k-1])
+		}
+		for _, item := range set {
+			fmt.Fprintf(&b, "  %v\n", item)
+		}
+	}
+	return b.String()
+}
+
+// EarleyItem is a production with a dot before the next symbol to be matched, and the position at which it was
+// predicted.
+type EarleyItem struct {
+	Production Production
+	Dot        int
+	Origin     int
+}
+
+// String returns the item in dotted notation, e.g. `S → a•Sb (0)`.
+func (i EarleyItem) String() string {
+	return fmt.Sprintf("%v → %s•%s (%d)", i.Production.A, join(i.Production.B[:i.Dot], ""), join(i.Production.B[i.Dot:], ""), i.Origin)
+}
@@ -0,0 +1,57 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func TestCFG_DerivesSentential(t *testing.T) {
+	g, err := cfg.Parse("S → aSb | A\nA → cA | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	S, A := cfg.Variable("S"), cfg.Variable("A")
+	a, b, c := cfg.Terminal("a"), cfg.Terminal("b"), cfg.Terminal("c")
+	for _, test := range []struct {
+		form     []cfg.Beta
+		expected bool
+	}{
+		{[]cfg.Beta{S}, true},
+		{[]cfg.Beta{}, true},
+		{[]cfg.Beta{cfg.Epsilon}, true},
+		{[]cfg.Beta{a, S, b}, true},
+		{[]cfg.Beta{a, a, A, b, b}, true},
+		{[]cfg.Beta{a, c, A, b}, true},
+		{[]cfg.Beta{a, c, c, b}, true},
+		{[]cfg.Beta{A, S}, false},
+		{[]cfg.Beta{a, S}, false},
+		{[]cfg.Beta{c, a}, false},
+	} {
+		if ok := g.DerivesSentential(test.form); ok != test.expected {
+			t.Errorf("%v: expected %v, got %v", test.form, test.expected, ok)
+		}
+	}
+}
+
+func ExampleCFG_EarleyChart() {
+	g, _ := cfg.Parse("S → aS | ε\n")
+	c := g.EarleyChart([]cfg.Beta{cfg.Terminal("a")})
+	fmt.Print(c)
+	fmt.Println(c.Accepted())
+	// Output:
+	// 0:
+	//   S' → •S (0)
+	//   S → •aS (0)
+	//   S → •ε (0)
+	//   S' → S• (0)
+	//   S → ε• (0)
+	// 1: a
+	//   S → a•S (0)
+	//   S → •aS (1)
+	//   S → •ε (1)
+	//   S → aS• (0)
+	//   S → ε• (1)
+	//   S' → S• (0)
+	// true
+}
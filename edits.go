@@ -0,0 +1,116 @@
+package cfg
+
+// levenshtein returns the edit distance between both strings, counting insertions, deletions and substitutions of
+// runes.
+func levenshtein(a, b []rune) int {
+	row := make([]int, len(b)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		diagonal := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := diagonal
+			if a[i-1] != b[j-1] {
+				cost++
+			}
+			if row[j]+1 < cost {
+				cost = row[j] + 1
+			}
+			if row[j-1]+1 < cost {
+				cost = row[j-1] + 1
+			}
+			diagonal, row[j] = row[j], cost
+		}
+	}
+	return row[len(b)]
+}
+
+// WithinEdits returns true if the string can be turned into a string of the language with at most k insertions,
+// deletions or substitutions of runes. It computes, for every variable and substring, the least number of edits
+// that turn a string derived by the variable into the substring, which is the intersection of the grammar with the
+// Levenshtein automaton of the string.
+func (g *CFG) WithinEdits(s string, k int) bool {
+	rs := []rune(s)
+	n := len(rs)
+	// Any cost above k is the same as no derivation at all.
+	infinite := k + 1
+	cost := make(map[Variable][][]int)
+	for _, v := range g.Variables {
+		cost[v] = make([][]int, n+1)
+		for i := range cost[v] {
+			cost[v][i] = make([]int, n+1)
+			for j := range cost[v][i] {
+				cost[v][i][j] = infinite
+			}
+		}
+	}
+	terminals := make(map[Beta][][]int)
+	symbolCost := func(b Beta, i, j int) int {
+		switch b := b.(type) {
+		case emptyString:
+			if i == j {
+				return 0
+			}
+			return levenshtein(nil, rs[i:j])
+		case Terminal, Category:
+			if _, ok := terminals[b]; !ok {
+				terminals[b] = make([][]int, n+1)
+				for i := range terminals[b] {
+					terminals[b][i] = make([]int, n+1)
+					for j := i; j <= n; j++ {
+						if c, ok := b.(Category); ok {
+							terminals[b][i][j] = g.symbols.category(c).distance(rs[i:j])
+						} else {
+							terminals[b][i][j] = levenshtein([]rune(b.String()), rs[i:j])
+						}
+					}
+				}
+			}
+			return terminals[b][i][j]
+		case Variable:
+			return cost[b][i][j]
+		}
+		return infinite
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			for i := 0; i <= n; i++ {
+				// current[j] is the least cost of the symbols so far to derive rs[i:j].
+				current := make([]int, n+1)
+				for j := range current {
+					current[j] = infinite
+				}
+				current[i] = 0
+				for _, b := range rule.B {
+					next := make([]int, n+1)
+					for j := range next {
+						next[j] = infinite
+					}
+					for j1 := i; j1 <= n; j1++ {
+						if current[j1] == infinite {
+							continue
+						}
+						for j2 := j1; j2 <= n; j2++ {
+							if c := current[j1] + symbolCost(b, j1, j2); c < next[j2] {
+								next[j2] = c
+							}
+						}
+					}
+					current = next
+				}
+				for j := i; j <= n; j++ {
+					if current[j] < cost[a][i][j] {
+						cost[a][i][j] = current[j]
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return cost[g.StartVariable][0][n] <= k
+}
@@ -0,0 +1,28 @@
+package cfg_test
+
+import (
+	"testing"
+)
+
+func TestCFG_WithinEdits(t *testing.T) {
+	for _, test := range []struct {
+		s        string
+		k        int
+		expected bool
+	}{
+		{"abba", 0, true},
+		{"aab", 0, false},
+		{"aab", 1, true},
+		{"abc", 1, false},
+		{"abc", 2, true},
+		{"ab", 1, true},
+		{"abcd", 1, false},
+		{"abcd", 2, true},
+		{"c", 1, true},
+		{"ccc", 2, false},
+	} {
+		if ok := g.WithinEdits(test.s, test.k); ok != test.expected {
+			t.Errorf("%q within %d edits: expected %v, got %v", test.s, test.k, test.expected, ok)
+		}
+	}
+}
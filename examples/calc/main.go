@@ -0,0 +1,196 @@
+// Calc evaluates arithmetic expressions, given as arguments or line by line on the standard input. It shows how the
+// derivation tree of a grammar with labeled productions is turned into an abstract syntax tree that is evaluated.
+//
+//	$ go run ./examples/calc "1 + 2 * (3 - 4) / 8"
+//	0.75
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var grammar = newGrammar()
+
+// build converts a derivation tree of the grammar to an abstract syntax tree. The actions are selected by the label of
+// the production that was applied to the node.
+func build(t *cfg.Tree) expression {
+	switch t.Production.Label {
+	case "expression", "term":
+		return tail(build(t.Children[0]), t.Children[1])
+	case "group":
+		return build(t.Children[1])
+	case "negate":
+		return negation{build(t.Children[1])}
+	case "number":
+		var digits strings.Builder
+		for _, c := range leaves(t) {
+			digits.WriteString(c.String())
+		}
+		v, _ := strconv.ParseFloat(digits.String(), 64)
+		return number(v)
+	}
+	panic(fmt.Sprintf("unexpected production %v", t.Production))
+}
+
+// evaluate parses and evaluates the expression.
+func evaluate(s string) (float64, error) {
+	s, err := lex(s)
+	if err != nil {
+		return 0, err
+	}
+	p, ok := grammar.Evaluate(s)
+	if !ok {
+		return 0, fmt.Errorf("invalid expression %q", s)
+	}
+	t, err := p.Tree()
+	if err != nil {
+		return 0, err
+	}
+	return build(t).value(), nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9' || c == '.'
+}
+
+// leaves returns the terminals derived by the tree, from left to right.
+func leaves(t *cfg.Tree) []cfg.Beta {
+	if len(t.Children) == 0 {
+		if t.Symbol == cfg.Epsilon {
+			return nil
+		}
+		return []cfg.Beta{t.Symbol}
+	}
+	var ls []cfg.Beta
+	for _, c := range t.Children {
+		ls = append(ls, leaves(c)...)
+	}
+	return ls
+}
+
+// lex removes the whitespace of the expression, the grammar works on single characters. Whitespace can not separate
+// the digits of a number.
+func lex(s string) (string, error) {
+	fields := strings.Fields(s)
+	for i := 1; i < len(fields); i++ {
+		previous, next := fields[i-1], fields[i]
+		if isDigit(previous[len(previous)-1]) && isDigit(next[0]) {
+			return "", fmt.Errorf("unexpected number %q", next)
+		}
+	}
+	return strings.Join(fields, ""), nil
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		run(strings.Join(os.Args[1:], " "))
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			run(scanner.Text())
+		}
+	}
+}
+
+// newGrammar returns the grammar of the expressions. The precedence of the operators is encoded in the variables
+// (E, T and F), the repetitions are right-recursive (E' and T') so the grammar can be evaluated top-down, and the
+// operators are folded to the left when the syntax tree is built.
+func newGrammar() *cfg.CFG {
+	var (
+		e, ep = cfg.Vr("E"), cfg.Vr("E'")
+		t, tp = cfg.Vr("T"), cfg.Vr("T'")
+		f, n  = cfg.Vr("F"), cfg.Vr("N")
+		m, d  = cfg.Vr("M"), cfg.Vr("D")
+	)
+	alphabet := cfg.Alphabet{"+", "-", "*", "/", "(", ")", "."}
+	rules := cfg.R{
+		cfg.NewProduction(e, cfg.Seq(t, ep)).WithLabel("expression"),
+		cfg.NewProduction(ep, cfg.Betas("+", t, ep)).WithLabel("add"),
+		cfg.NewProduction(ep, cfg.Betas("-", t, ep)).WithLabel("subtract"),
+		cfg.NewProduction(ep, cfg.Seq(cfg.Epsilon)).WithLabel("end"),
+		cfg.NewProduction(t, cfg.Seq(f, tp)).WithLabel("term"),
+		cfg.NewProduction(tp, cfg.Betas("*", f, tp)).WithLabel("multiply"),
+		cfg.NewProduction(tp, cfg.Betas("/", f, tp)).WithLabel("divide"),
+		cfg.NewProduction(tp, cfg.Seq(cfg.Epsilon)).WithLabel("end"),
+		cfg.NewProduction(f, cfg.Betas("(", e, ")")).WithLabel("group"),
+		cfg.NewProduction(f, cfg.Betas("-", f)).WithLabel("negate"),
+		cfg.NewProduction(f, cfg.Seq(n)).WithLabel("number"),
+		cfg.NewProduction(n, cfg.Seq(d, n)),
+		cfg.NewProduction(n, cfg.Betas(d, ".", m)),
+		cfg.NewProduction(n, cfg.Seq(d)),
+		cfg.NewProduction(m, cfg.Seq(d, m)),
+		cfg.NewProduction(m, cfg.Seq(d)),
+	}
+	for c := '0'; c <= '9'; c++ {
+		alphabet = append(alphabet, cfg.T(string(c)))
+		rules = append(rules, cfg.NewProduction(d, cfg.Betas(string(c))))
+	}
+	g, err := cfg.New(cfg.V{e, ep, t, tp, f, n, m, d}, alphabet, rules, e)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func run(s string) {
+	v, err := evaluate(s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Println(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+// tail folds the operators of the tail (E' or T') to the left, starting with the given left operand.
+func tail(left expression, t *cfg.Tree) expression {
+	for t.Production.Label != "end" {
+		op := t.Children[0].Symbol.String()
+		left = binary{op: op, left: left, right: build(t.Children[1])}
+		t = t.Children[2]
+	}
+	return left
+}
+
+type binary struct {
+	op          string
+	left, right expression
+}
+
+func (b binary) value() float64 {
+	l, r := b.left.value(), b.right.value()
+	switch b.op {
+	case "+":
+		return l + r
+	case "-":
+		return l - r
+	case "*":
+		return l * r
+	}
+	return l / r
+}
+
+// expression is a node of the abstract syntax tree.
+type expression interface {
+	value() float64
+}
+
+type negation struct {
+	operand expression
+}
+
+func (n negation) value() float64 {
+	return -n.operand.value()
+}
+
+type number float64
+
+func (n number) value() float64 {
+	return float64(n)
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestEvaluate(t *testing.T) {
+	for _, test := range []struct {
+		expression string
+		expected   float64
+	}{
+		{"42", 42},
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"8 - 4 - 2", 2},
+		{"8 / 4 / 2", 1},
+		{"-(2 - 5) * -1", -3},
+		{"1 + 2 * (3 - 4) / 8", 0.75},
+		{"1.5 * 4", 6},
+		{"12.25 * 4", 49},
+	} {
+		v, err := evaluate(test.expression)
+		if err != nil {
+			t.Errorf("%s: %v", test.expression, err)
+			continue
+		}
+		if v != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.expression, test.expected, v)
+		}
+	}
+	for _, test := range []string{"", "1 +", "(1", "1 2", "*3"} {
+		if _, err := evaluate(test); err == nil {
+			t.Errorf("%q: expected an error", test)
+		}
+	}
+}
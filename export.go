@@ -0,0 +1,181 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// export writes every variable with its alternatives on a single line, in the order of the variables.
+func (g *CFG) export(rule func(v Variable, alternatives []Production) string) string {
+	var s strings.Builder
+	for _, v := range g.Variables {
+		if rules := g.RulesFor(v); len(rules) != 0 {
+			s.WriteString(rule(v, rules) + "\n")
+		}
+	}
+	return s.String()
+}
+
+// isoTerminal quotes a terminal for ISO 14977 EBNF, which has no escape sequences. A terminal with both kinds of
+// quotes is split into a concatenation of parts that each contain one kind.
+func isoTerminal(t Terminal) string {
+	var parts []string
+	for s := string(t); s != ""; {
+		i := strings.IndexAny(s, `'"`)
+		if i < 0 {
+			parts = append(parts, fmt.Sprintf("'%s'", s))
+			break
+		}
+		quote := byte('\'')
+		if s[i] == '\'' {
+			quote = '"'
+		}
+		end := len(s)
+		if j := strings.IndexByte(s[i+1:], quote); 0 <= j {
+			end = i + 1 + j
+		}
+		parts = append(parts, string(quote)+s[:end]+string(quote))
+		s = s[end:]
+	}
+	return strings.Join(parts, ", ")
+}
+
+// textTerminal escapes a terminal for the text format, unless it is a lowercase letter or a bracket.
+func textTerminal(t Terminal) string {
+	if len([]rune(string(t))) != 1 || strings.ContainsAny(string(t), "()[]") || ('a' <= t[0] && t[0] <= 'z') {
+		return string(t)
+	}
+	return `\` + string(t)
+}
+
+// w3cTerminal quotes a terminal for W3C EBNF, falling back to character codes if it contains both kinds of quotes.
+func w3cTerminal(t Terminal) string {
+	s := string(t)
+	switch {
+	case !strings.Contains(s, "'"):
+		return fmt.Sprintf("'%s'", s)
+	case !strings.Contains(s, `"`):
+		return fmt.Sprintf("\"%s\"", s)
+	}
+	var codes []string
+	for _, r := range s {
+		codes = append(codes, fmt.Sprintf("#x%X", r))
+	}
+	return strings.Join(codes, " ")
+}
+
+// w3cCategory writes a category as a W3C EBNF character class of its runes.
+func w3cCategory(c Category) string {
+	var s strings.Builder
+	s.WriteString("[")
+	add := func(lo, hi, stride uint32) {
+		switch {
+		case lo == hi:
+			fmt.Fprintf(&s, "#x%X", lo)
+		case stride == 1:
+			fmt.Fprintf(&s, "#x%X-#x%X", lo, hi)
+		default:
+			for r := lo; r <= hi; r += stride {
+				fmt.Fprintf(&s, "#x%X", r)
+			}
+		}
+	}
+	table := unicode.Categories[string(c)]
+	for _, r := range table.R16 {
+		add(uint32(r.Lo), uint32(r.Hi), uint32(r.Stride))
+	}
+	for _, r := range table.R32 {
+		add(r.Lo, r.Hi, r.Stride)
+	}
+	s.WriteString("]")
+	return s.String()
+}
+
+// ISOEBNF exports the grammar in ISO/IEC 14977 EBNF notation, ε is written as the empty sequence and a category as a
+// special sequence, e.g. `? \p{L} ?`.
+func (g *CFG) ISOEBNF() string {
+	return g.export(func(v Variable, alternatives []Production) string {
+		var as []string
+		for _, p := range alternatives {
+			var ss []string
+			for _, b := range p.B {
+				switch b := b.(type) {
+				case Terminal:
+					ss = append(ss, isoTerminal(b))
+				case Category:
+					ss = append(ss, fmt.Sprintf("? %s ?", b))
+				case Variable:
+					ss = append(ss, b.String())
+				}
+			}
+			as = append(as, strings.Join(ss, ", "))
+		}
+		if rhs := strings.TrimPrefix(strings.Join(as, " | "), " "); rhs != "" {
+			return fmt.Sprintf("%s = %s ;", v, rhs)
+		}
+		return fmt.Sprintf("%s = ;", v)
+	})
+}
+
+// Text exports the grammar in the text format read by Parse, one line per variable. Terminals that are not lowercase
+// letters or brackets are escaped. Only grammars with single character symbols can be parsed again.
+func (g *CFG) Text() string {
+	return g.export(func(v Variable, alternatives []Production) string {
+		var as []string
+		for _, p := range alternatives {
+			var a string
+			for i, b := range p.B {
+				if name := p.capture(i); name != "" {
+					a += name + ":"
+				}
+				if t, ok := b.(Terminal); ok {
+					a += textTerminal(t)
+				} else {
+					a += b.String()
+				}
+			}
+			if p.Label != "" {
+				a += " #" + p.Label
+			}
+			as = append(as, a)
+		}
+		return fmt.Sprintf("%s → %s", v, strings.Join(as, " | "))
+	})
+}
+
+// W3CEBNF exports the grammar in the EBNF notation of the W3C XML specification. Since the notation has no symbol
+// for ε, the other alternatives of a nullable variable are made optional. A category is written as the character class
+// of its runes.
+func (g *CFG) W3CEBNF() string {
+	return g.export(func(v Variable, alternatives []Production) string {
+		var as []string
+		var epsilon bool
+		for _, p := range alternatives {
+			var ss []string
+			for _, b := range p.B {
+				switch b := b.(type) {
+				case Terminal:
+					ss = append(ss, w3cTerminal(b))
+				case Category:
+					ss = append(ss, w3cCategory(b))
+				case Variable:
+					ss = append(ss, b.String())
+				}
+			}
+			if len(ss) == 0 {
+				epsilon = true
+				continue
+			}
+			as = append(as, strings.Join(ss, " "))
+		}
+		switch {
+		case !epsilon:
+			return fmt.Sprintf("%s ::= %s", v, strings.Join(as, " | "))
+		case len(as) == 0:
+			return fmt.Sprintf("%s ::= ''", v)
+		default:
+			return fmt.Sprintf("%s ::= ( %s )?", v, strings.Join(as, " | "))
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_Text() {
+	g, _ := cfg.Parse("S → aSa #a\nS → bSb | A\nA → ε | ()\n")
+	fmt.Print(g.Text())
+	fmt.Print(g.W3CEBNF())
+	fmt.Print(g.ISOEBNF())
+	// Output:
+	// S → aSa #a | bSb | A
+	// A → ε | ()
+	// S ::= 'a' S 'a' | 'b' S 'b' | A
+	// A ::= ( '(' ')' )?
+	// S = 'a', S, 'a' | 'b', S, 'b' | A ;
+	// A = | '(', ')' ;
+}
+
+func TestCFG_Text(t *testing.T) {
+	g, err := cfg.Parse("S → aSa #a\nS → bSb | A\nA → ε | ()\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfg.Parse(g.Text())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.String() != h.String() {
+		t.Errorf("expected %v, got %v", g, h)
+	}
+}
+
+func TestCFG_ISOEBNF_quotes(t *testing.T) {
+	g, err := cfg.New(cfg.V{"S"}, cfg.Alphabet{`a'b"c`, `"'`, "x"}, cfg.R{
+		cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Terminal(`a'b"c`), cfg.Terminal(`"'`), cfg.Terminal("x")}),
+	}, "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, expected := g.ISOEBNF(), `S = "a'b", '"c', '"', "'", 'x' ;`+"\n"; s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+}
@@ -0,0 +1,50 @@
+package cfg
+
+import "strings"
+
+// SetFields sets whether Evaluate splits the input on whitespace, every field must then match exactly one terminal,
+// e.g. "if x then y" for a grammar with the terminals `if` and `then`. A terminal is no longer matched as a prefix of a
+// longer word, so grammars of multi-character words are not ambiguous about where a terminal ends. Terminals that
+// contain whitespace never match. The mode applies to Evaluate, EvaluateAll, EvaluateLimited, EvaluateShortest, Derive,
+// Spans, KBest and Continuation.
+func (g *CFG) SetFields(fields bool) {
+	g.fields = fields
+	g.memo.reset()
+}
+
+// Fields returns true if Evaluate splits the input on whitespace, see SetFields.
+func (g *CFG) Fields() bool {
+	return g.fields
+}
+
+// input returns the string that is evaluated: in fields mode every field followed by a single space, so the end of a
+// terminal can be checked without knowing the original separators.
+func (g *CFG) input(s string) string {
+	if !g.fields {
+		return s
+	}
+	var b strings.Builder
+	for _, field := range strings.Fields(s) {
+		b.WriteString(field)
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+// match returns the length of the terminal or category at the start of the string, in fields mode together with the space after
+// it, so only a whole field is matched.
+func (g *CFG) match(s string, a Beta, e *evaluation) (int, bool) {
+	n, ok := g.symbols.match(s, a)
+	if !ok || !e.fields {
+		return n, ok
+	}
+	return fieldEnd(s, n)
+}
+
+// fieldEnd returns n plus the space after it, if the first n bytes of the string are a whole field.
+func fieldEnd(s string, n int) (int, bool) {
+	if n == 0 || len(s) <= n || s[n] != ' ' || strings.IndexByte(s[:n], ' ') >= 0 {
+		return 0, false
+	}
+	return n + 1, true
+}
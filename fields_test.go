@@ -0,0 +1,74 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_SetFields() {
+	g, _ := cfg.Parse("S → aSa | bSb | ε\n")
+	g.SetFields(true)
+	_, ok := g.Evaluate("a b  b\ta")
+	fmt.Println(ok)
+	_, ok = g.Evaluate("ab ba")
+	fmt.Println(ok)
+	// Output:
+	// true
+	// false
+}
+
+func TestCFG_SetFields(t *testing.T) {
+	S, W := cfg.Variable("S"), cfg.Variable("W")
+	a, ab, b, in := cfg.Terminal("a"), cfg.Terminal("ab"), cfg.Terminal("b"), cfg.Terminal("in")
+	g, err := cfg.New(
+		[]cfg.Variable{S, W},
+		[]cfg.Terminal{a, ab, b, in},
+		[]cfg.Production{
+			cfg.NewProduction(S, []cfg.Beta{W, S}),
+			cfg.NewProduction(S, []cfg.Beta{W}),
+			cfg.NewProduction(W, []cfg.Beta{a}),
+			cfg.NewProduction(W, []cfg.Beta{ab}),
+			cfg.NewProduction(W, []cfg.Beta{b, in}),
+		},
+		S,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without fields, `abin` is split in terminals regardless of the words.
+	if _, ok := g.Evaluate("abin"); !ok {
+		t.Error("expected abin to be accepted")
+	}
+	g.SetFields(true)
+	for _, strategy := range []cfg.Strategy{cfg.DepthFirst, cfg.BreadthFirst, cfg.Parallel} {
+		g.SetStrategy(strategy)
+		for _, test := range []struct {
+			s        string
+			expected bool
+		}{
+			{"a", true},
+			{" ab  a\n", true},
+			{"b in", true},
+			{"abin", false},
+			{"ab in", false},
+			{"a b", false},
+			{"aa", false},
+			{"", false},
+		} {
+			if _, ok := g.Evaluate(test.s); ok != test.expected {
+				t.Errorf("%v %q: expected %v, got %v", strategy, test.s, test.expected, ok)
+			}
+		}
+	}
+
+	g.SetStrategy(cfg.DepthFirst)
+	p, ok := g.Evaluate("ab a")
+	if !ok || fmt.Sprint(p) != "[ S → WS, W → ab, S → W, W → a ]" {
+		t.Errorf("unexpected derivation %v", p)
+	}
+	c := g.Continuation("b in")
+	if _, ok := depthFirst(c); !ok {
+		t.Error("expected the continuation to accept the fields")
+	}
+}
@@ -1,22 +1,88 @@
 package cfg
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
-// Epsilon is the empty string.
-const Epsilon = Terminal("ε")
+// AutoDepth is a depth limit that is derived from the grammar and the length of the input, see DepthBound.
+const AutoDepth = -1
 
-func indices[T fmt.Stringer](ts []T, t string) []int {
-	var indices []int
-	for i, v := range ts {
-		if v.String() == t {
-			indices = append(indices, i)
+var (
+	// Epsilon is the empty string. It is a symbol of its own type, so it is never confused with a terminal.
+	Epsilon = emptyString{}
+
+	// ErrEmptyTerminal is returned if a terminal is the empty string, Epsilon should be used instead.
+	ErrEmptyTerminal = errors.New("empty terminal")
+
+	// DefaultLimits are the limits of a new grammar.
+	DefaultLimits = Limits{Depth: AutoDepth, Steps: 1 << 20}
+)
+
+// compareJoined compares the concatenations of the symbols, without concatenating them.
+func compareJoined(a, b []Beta) int {
+	var i, j int // The offsets in the current symbols.
+	for {
+		for len(a) != 0 && i == len(a[0].String()) {
+			a, i = a[1:], 0
 		}
+		for len(b) != 0 && j == len(b[0].String()) {
+			b, j = b[1:], 0
+		}
+		switch {
+		case len(a) == 0 && len(b) == 0:
+			return 0
+		case len(a) == 0:
+			return -1
+		case len(b) == 0:
+			return 1
+		}
+		x, y := a[0].String()[i], b[0].String()[j]
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+}
+
+// formLength returns the number of symbols of a sentential form, ignoring ε and markers.
+func formLength(form []symbol) int {
+	var n int
+	for _, s := range form {
+		switch s.beta.(type) {
+		case Terminal, Category, Variable:
+			n++
+		}
+	}
+	return n
+}
+
+// isTerminal returns true if the symbol is a Terminal or a Category, which both match a non-empty string.
+func isTerminal(b Beta) bool {
+	switch b.(type) {
+	case Terminal, Category:
+		return true
 	}
-	return indices
+	return false
+}
+
+// isUnit checks whether the production is a unit production (`A → B`).
+func isUnit(p Production) bool {
+	if len(p.B) != 1 {
+		return false
+	}
+	_, ok := p.B[0].(Variable)
+	return ok
 }
 
 func join[T fmt.Stringer](ts []T, sep string) string {
@@ -40,6 +106,15 @@ func powerSet(i []int) [][]int {
 	return ps[1:] // Remove the empty set.
 }
 
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // Alpha is the wrapper for `α` in production rules (variables).
 type Alpha interface {
 	fmt.Stringer
@@ -62,16 +137,45 @@ type CFG struct {
 	Rules         R
 	StartVariable Variable
 
-	depth       int
+	limits   Limits
+	strategy Strategy
+	// fields is true if the input is split on whitespace, see SetFields.
+	fields      bool
+	logger      *slog.Logger
+	metrics     *Metrics
+	resources   ResourceLimits
 	mappedRules map[Alpha][]Production
-
-	lastIndex int
+	// symbols interns the symbols of the grammar, it is used by the evaluation and the analyses.
+	symbols *symbolTable
+	// memo are the variables that derive a string, see Derive.
+	memo *deriveMemo
+	// tables are stored analyses of the grammar, see UseTables.
+	tables *Tables
+	// dyck is the stack-based validator of a bracket-matching grammar, nil for other grammars.
+	dyck *Dyck
+	// history is the chain of transforms the grammar was derived with, see Transform.
+	history *History
+	// prefixes is the grammar of the prefix language, built by the first call of Completions.
+	prefixes *prefixGrammar
+
+	// rejects and followRestrictions are the disambiguation filters of the variables, see Reject.
+	rejects            map[Variable][][]Beta
+	followRestrictions map[Variable][]Terminal
 }
 
 // New creates a new context-free grammar from the given variables, alphabet, rules, and start symbol. The order of the
 // rules is important, since the first rule that matches will be used. Infinite loops can be prevented by using the
-// repeat flag.
-func New(variables V, alphabet Alphabet, rules R, start Variable) (*CFG, error) {
+// repeat flag. The empty string must be written as Epsilon, it is neither part of the alphabet nor a Terminal(""). A
+// Category is not part of the alphabet either.
+func New(variables V, alphabet Alphabet, rules R, start Variable, opts ...Option) (*CFG, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.resources.checkSize(rules); err != nil {
+		return nil, err
+	}
+
 	var containsStart bool
 	for _, v := range variables {
 		if v == start {
@@ -83,323 +187,418 @@ func New(variables V, alphabet Alphabet, rules R, start Variable) (*CFG, error)
 		return nil, fmt.Errorf("start symbol %v not in variables", start)
 	}
 
-	var disjoint = true
+	vs := make(map[string]bool)
 	for _, v := range variables {
-		for _, t := range alphabet {
-			if string(v) == string(t) {
-				disjoint = false
-				break
-			}
-		}
-	}
-	if !disjoint {
-		return nil, fmt.Errorf("variables and alphabet are not disjoint")
+		vs[v.String()] = true
 	}
 
 	a := make(map[Terminal]bool)
 	for _, v := range alphabet {
+		if v == "" {
+			return nil, fmt.Errorf("%w in alphabet", ErrEmptyTerminal)
+		}
+		if vs[string(v)] {
+			return nil, fmt.Errorf("variables and alphabet are not disjoint")
+		}
 		a[v] = true
 	}
 	for _, v := range rules {
-		for _, v := range v.B {
-			switch v := v.(type) {
+		for _, b := range v.B {
+			switch b := b.(type) {
 			case Terminal:
-				if v == Epsilon {
-					continue
+				if b == "" {
+					return nil, fmt.Errorf("%w in %s", ErrEmptyTerminal, v.describe())
 				}
-				if _, ok := a[v]; !ok {
-					return nil, fmt.Errorf("terminal %v not in alphabet", v)
+				if _, ok := a[b]; !ok {
+					return nil, fmt.Errorf("terminal %v not in alphabet: %s", b, v.describe())
+				}
+			case Category:
+				if err := b.valid(); err != nil {
+					return nil, fmt.Errorf("%w: %s", err, v.describe())
 				}
 			}
 		}
 	}
 
-	vs := make(map[string]bool)
-	for _, v := range variables {
-		vs[v.String()] = true
-	}
 	for _, v := range rules {
 		if _, ok := vs[v.A.String()]; !ok {
-			return nil, fmt.Errorf("variable %v not in variables", v.A)
+			return nil, fmt.Errorf("variable %v not in variables: %s", v.A, v.describe())
 		}
-		for _, v := range v.B {
-			switch v := v.(type) {
+		for _, b := range v.B {
+			switch b := b.(type) {
 			case Variable:
-				if _, ok := vs[v.String()]; !ok {
-					return nil, fmt.Errorf("variable %v not in variables", v)
+				if _, ok := vs[b.String()]; !ok {
+					return nil, fmt.Errorf("variable %v not in variables: %s", b, v.describe())
 				}
 			}
 		}
 	}
 
 	var mappedRules = make(map[Alpha][]Production)
-	var mappedEpsilon = make(map[Alpha]bool)
+	var mappedEpsilon = make(map[Alpha]Production)
 	for _, rule := range rules {
 		if len(rule.B) == 1 && rule.B[0] == Epsilon {
-			mappedEpsilon[rule.A] = true
+			mappedEpsilon[rule.A] = rule
 			continue
 		}
 		mappedRules[rule.A] = append(mappedRules[rule.A], rule)
 	}
 	// Make sure that the epsilon rules is always the last rule, since the production rules are evaluated in order.
 	// Otherwise, the epsilon rule will always be evaluated first.
-	for k := range mappedEpsilon {
-		mappedRules[k] = append(mappedRules[k], NewProduction(k, []Beta{Epsilon}))
+	for k, rule := range mappedEpsilon {
+		mappedRules[k] = append(mappedRules[k], rule)
 	}
 
-	return &CFG{
+	g := &CFG{
 		Variables:     variables,
 		Alphabet:      alphabet,
 		Rules:         rules,
 		StartVariable: start,
 
-		depth:       10,
+		limits:      DefaultLimits,
 		mappedRules: mappedRules,
-	}, nil
+		symbols:     newSymbolTable(variables, alphabet, rules, start, mappedRules),
+		memo:        newDeriveMemo(),
+		prefixes:    new(prefixGrammar),
+		dyck:        newDyck(rules, start),
+		logger:      o.logger,
+		metrics:     o.metrics,
+		resources:   o.resources,
+	}
+	if g.logger != nil {
+		for _, w := range g.Lint() {
+			g.debug("grammar warning", "warning", w.String())
+		}
+		if o.unusedTerminals {
+			g.warnUnusedTerminals()
+		}
+	}
+	if o.strict {
+		if err := g.strict(); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// Alternatives returns the productions of the given variable in the order in which they are evaluated, the
+// ε-production (if any) is always the last alternative.
+func (g *CFG) Alternatives(v Variable) []Production {
+	return append([]Production(nil), g.mappedRules[v]...)
 }
 
-// CNF converts a context-free grammar to Chomsky Normal Form.
+// CNF converts a context-free grammar to Chomsky Normal Form, see Transform. The resulting rules are sorted and
+// deterministic, and converting a grammar that is already in Chomsky Normal Form returns the same rules.
 func (g *CFG) CNF() R {
-	rules := make(R, len(g.Rules))
-	copy(rules, g.Rules)
+	h, _, err := g.Transform(RemoveEpsilon, RemoveUnits, Binarize, LiftTerminals)
+	if err != nil {
+		// The fresh variables of the transforms collide with neither variables nor terminals, so the grammars are
+		// always valid.
+		panic(err)
+	}
+	cnf := append(R(nil), h.Rules...)
+	cnf.Sort()
+	return cnf
+}
 
-	// 1. Remove ε-productions.
-	var nullable = make(map[string]bool)
-	for _, rule := range rules {
-		if len(rule.B) == 1 && rule.B[0] == Epsilon {
-			nullable[rule.A.String()] = true
-		}
+// Depth sets the maximum depth of the derivation tree, see Limits.
+func (g *CFG) Depth(depth int) {
+	g.limits.Depth = depth
+}
+
+// DepthBound returns a depth of the derivation tree that is sufficient to derive any accepted string of the given
+// length (in bytes). A string with a derivation tree has a derivation tree in which no variable repeats along a path
+// without the derived substring getting shorter, and every terminal derives at least one byte.
+func (g *CFG) DepthBound(n int) int {
+	return (n + 1) * len(g.Variables)
+}
+
+// Evaluate searches for a leftmost derivation of the given string, within the limits of the grammar and in the order
+// of its strategy.
+func (g *CFG) Evaluate(s string) (Path, bool) {
+	e := g.newEvaluation(s)
+	p, ok := g.evaluateWith(s, e)
+	g.evaluated(s, e, ok)
+	if !ok {
+		return nil, false
 	}
-	var l = 0
-	for len(nullable) != l {
-		l = len(nullable)
-		for _, rule := range rules {
-			for n := range nullable {
-				r := make([]Beta, len(rule.B))
-				copy(r, rule.B)
-				i := indices(r, n)
-				var k int
-				for _, j := range i {
-					r = append(r[:j-k], r[j-k+1:]...)
-					k++
-				}
-				if len(r) == 0 {
-					nullable[rule.A.String()] = true
-				}
-			}
-		}
+	return p, true
+}
+
+// EvaluateAll returns all leftmost derivations of the given string that are found within the limits of the grammar,
+// in the order in which Evaluate would find them. The step limit bounds the whole search.
+func (g *CFG) EvaluateAll(s string) []Path {
+	var paths []Path
+	e := g.newEvaluation(s)
+	e.yield = func(p Path) {
+		paths = append(paths, append(Path(nil), p...))
 	}
+	g.evaluateWith(s, e)
+	g.metrics.evaluated(e.steps, len(paths) != 0)
+	return paths
+}
 
-	for i, rule := range rules {
-		// Remove ε-productions.
-		if len(rule.B) == 1 && rule.B[0] == Epsilon {
-			rules = append(rules[:i], rules[i+1:]...)
-		}
-		// Remove nullable variables.
-		m := make(map[string][]Beta)
-		for n := range nullable {
-			for _, s := range powerSet(indices(rule.B, n)) {
-				r := make([]Beta, len(rule.B))
-				copy(r, rule.B)
-				var j int // offset, because of removed elements.
-				for _, i := range s {
-					r = append(r[:i-j], r[i-j+1:]...)
-					j++
-				}
-				if 0 < len(r) {
-					m[join(r, "")] = r
-				}
-			}
-		}
-		for _, r := range m {
-			rules = append(rules, NewProduction(rule.A, r))
+// Limits returns the limits of the search of Evaluate.
+func (g *CFG) Limits() Limits {
+	return g.limits
+}
+
+// RulesFor returns the productions of the given variable in the order in which they were defined.
+func (g *CFG) RulesFor(v Variable) []Production {
+	var rules []Production
+	for _, rule := range g.Rules {
+		if rule.A == v {
+			rules = append(rules, rule)
 		}
 	}
+	return rules
+}
 
-	// 2. Remove unit productions.
-	var units = make(map[string]Variable)
-	for _, rule := range rules {
-		if len(rule.B) == 1 {
-			switch v := rule.B[0].(type) {
-			case Variable:
-				units[rule.A.String()] = v
-			}
+// SetLimits sets the limits of the search of Evaluate.
+func (g *CFG) SetLimits(limits Limits) {
+	g.limits = limits
+}
+
+func (g *CFG) String() string {
+	return fmt.Sprintf(
+		"( { %v }, { %v }, [ %v ], %s )",
+		join(g.Variables, ", "),
+		join(g.Alphabet, ", "),
+		g.Rules,
+		g.StartVariable,
+	)
+}
+
+// evaluate matches the remaining symbols of the sentential form against the remaining string. The number of matched
+// terminals and the number of tried productions are used to enforce the limits.
+func (g *CFG) evaluate(s string, form []symbol, matched int, path Path, e *evaluation) (string, Path, bool) {
+	if len(form) == 0 {
+		if s == "" && e.yield != nil {
+			e.yield(path)
+			// Backtrack to find the other derivations.
+			return "", path, false
 		}
+		return "", path, s == ""
 	}
-	for k, unit := range units {
-		var i []int
-		for j, rule := range rules {
-			if rule.A.String() == k && len(rule.B) == 1 && rule.B[0] == unit {
-				i = append(i, j)
-			}
-			if rule.A.String() == unit.String() {
-				rules = append(rules, NewProduction(Variable(k), rule.B))
-			}
-		}
-		// Remove unit productions.
-		for _, i := range i {
-			rules = append(rules[:i], rules[i+1:]...)
+	switch beta := form[0].beta.(type) {
+	case Terminal, Category:
+		// If the string starts with the terminal, then we can handle the remaining symbols.
+		if n, ok := g.match(s, beta, e); ok {
+			return g.evaluate(s[n:], form[1:], matched+1, path, e)
 		}
-	}
-	rules.Sort() // This is needed since the order of a map is not guaranteed.
-
-	// 3. Replace long productions.
-	reverse := make(map[string]string) // Reusable variables.
-	for i, rule := range rules {
-		if len(rule.B) <= 2 {
-			continue
+		// Otherwise, the string is not accepted, backtrack.
+		return "", path, false
+	case Variable:
+		if 0 < e.depth && e.depth < form[0].depth+1 {
+			return "", path, false
 		}
-		r := make([]Beta, len(rule.B)-1)
-		copy(r, rule.B[1:])
-
-		a := rule.A
-		var lastV = g.getVariable()
-		b := []Beta{rule.B[0], Variable(lastV)}
-		reverse[join(b, "")] = a.String()
-		rules[i] = NewProduction(a, b)
-
-		// Current implementation:
-		// S -> ABCD
-		//
-		// S -> AX
-		// X -> BY
-		// Y -> CD
-		// Alternative implementation:
-		// S -> ABCD
-		//
-		// S -> XY
-		// X -> AB
-		// Y -> CD
-
-		var productions []Production
-		for 2 < len(r) {
-			a := Variable(lastV)
-			lastV = g.getVariable()
-			b := []Beta{r[0], Variable(lastV)}
-			reverse[join(b, "")] = a.String()
-			productions = append(productions, NewProduction(a, b))
-			r = r[1:]
+		if c := g.runeClass(form[0]); c != nil {
+			// Only the production of the next rune can match.
+			i, n, ok := c.match(s)
+			if ok && e.fields {
+				n, ok = fieldEnd(s, n)
+			}
+			if !ok || !g.step(e) {
+				return "", path, false
+			}
+			if 0 < g.limits.Length && g.limits.Length < matched+1+formLength(form[1:]) {
+				return "", path, false
+			}
+			return g.evaluate(s[n:], form[1:], matched+1, append(path, g.symbols.alternatives[form[0].id][i].production), e)
 		}
-		a = Variable(lastV)
-		b = []Beta{r[0], r[1]}
-		if v, ok := reverse[join(b, "")]; ok {
-			b = []Beta{Variable(v)}
-		} else {
-			reverse[join(b, "")] = a.String()
+		var m arenaMark
+		if e.arena != nil {
+			m = e.arena.mark()
 		}
-		productions = append(productions, NewProduction(a, b))
-
-		// Clean up unit productions create by reusing variables.
-		var remove []int
-		for j, p := range productions {
-			if j == 0 && len(p.B) == 1 {
-				rules[i] = NewProduction(rules[i].A, []Beta{rules[i].B[0], p.B[0]})
-				remove = append(remove, j)
+		for _, alternative := range g.symbols.alternatives[form[0].id] {
+			if !g.step(e) {
+				return "", path, false
+			}
+			next, ok := g.expand(s, form, matched, alternative, e)
+			if !ok {
 				continue
 			}
-			if len(p.B) == 1 {
-				rules = append(rules, NewProduction(rules[i].A, []Beta{productions[j-1].B[0], p.B[0]}))
-				remove = append(remove, j)
+			// The alternatives share the memory of the derivation, only one of them is searched at a time. Derivations
+			// that outlive the search are copied, see EvaluateAll and Continuation.
+			if s, path, ok := g.evaluate(s, next, matched, append(path, alternative.production), e); ok {
+				return s, path, true
+			}
+			if e.arena != nil {
+				// The forms of the search of the alternative are no longer used.
+				e.arena.release(m)
 			}
 		}
-		var k int
-		for _, j := range remove {
-			productions = append(productions[:j-k], productions[j-k+1:]...)
-			k++
+		// If no production rule for the variable is accepted, then the string is not accepted, backtrack.
+		return "", path, false
+	case checkpoint:
+		if !g.allowed(beta.variable, beta.start[:len(beta.start)-len(s)], s, e) {
+			return "", path, false
 		}
-
-		rules = append(rules, productions...)
+		return g.evaluate(s, form[1:], matched, path, e)
 	}
+	return "", path, false
+}
 
-	// 4. Move terminals to unit productions.
-	alphabet := make(map[string]Variable)
-	for i, v := range g.Alphabet {
-		a := Variable(fmt.Sprintf("T%d", i))
-		alphabet[v.String()] = a
+// expand replaces the variable at the start of the sentential form by the alternative, or returns false if the
+// resulting form can not derive the remaining string within the length limit. The form is allocated in the arena of
+// the evaluation, if any.
+func (g *CFG) expand(s string, form []symbol, matched int, alternative internedAlternative, e *evaluation) ([]symbol, bool) {
+	depth := form[0].depth + 1
+	var next []symbol
+	if n := len(alternative.form) + 1 + len(form) - 1; e.arena != nil {
+		next = e.arena.alloc(n)
+	} else {
+		next = make([]symbol, 0, n)
 	}
-	for _, rule := range rules {
-		for i, b := range rule.B {
-			switch v := b.(type) {
-			case Terminal:
-				rule.B[i] = alphabet[v.String()]
+	for _, s := range alternative.form {
+		s.depth = depth
+		next = append(next, s)
+	}
+	if v := form[0].beta.(Variable); g.filtered(v) {
+		// Mark the end of the variable, to check the filters on the derived substring.
+		next = append(next, symbol{beta: checkpoint{variable: v, start: s}})
+	}
+	next = append(next, form[1:]...)
+	if 0 < g.limits.Length && g.limits.Length < matched+formLength(next) {
+		return nil, false
+	}
+	if n, ok := g.minimumLength(next); !ok || len(s) < n {
+		// The remaining symbols can not derive a string that is short enough.
+		return nil, false
+	}
+	return next, true
+}
+
+// minimumLength returns the length of the shortest string derived by the sentential form, or false if it does not
+// derive any string of terminals.
+func (g *CFG) minimumLength(form []symbol) (int, bool) {
+	var n int
+	for _, s := range form {
+		switch b := s.beta.(type) {
+		case Terminal, Category:
+			n += g.symbols.length(b)
+		case Variable:
+			m := g.symbols.minLengths[s.id]
+			if m < 0 {
+				return 0, false
 			}
+			n += m
 		}
 	}
-	for b, a := range alphabet {
-		rules = append(rules, NewProduction(a, []Beta{Terminal(b)}))
-	}
+	return n, true
+}
 
-	return rules
+// evaluateWith evaluates the string in the order of the strategy, see Evaluate.
+func (g *CFG) evaluateWith(s string, e *evaluation) (Path, bool) {
+	s = g.input(s)
+	var p Path
+	var ok bool
+	switch {
+	case g.strategy == BreadthFirst:
+		p, ok = g.evaluateBreadthFirst(s, e)
+	case g.strategy == Brackets && g.dyck != nil && e.yield == nil && !g.filtered(g.StartVariable) && !e.fields:
+		var err error
+		p, err = g.dyck.Path(s)
+		ok = err == nil
+	case g.strategy == Parallel && e.yield == nil && g.symbols.classes[g.symbols.variable[g.StartVariable]] == nil:
+		p, ok = g.evaluateParallel(s, e)
+	default:
+		p, ok = g.evaluateDepthFirst(s, e)
+	}
+	return p, ok
 }
 
-// Depth allows the setting of the maximum depth of the production rules. Default is 10.
-func (g *CFG) Depth(depth int) {
-	g.depth = depth
+// evaluated records the result of an evaluation in the metrics and the log.
+func (g *CFG) evaluated(s string, e *evaluation, ok bool) {
+	g.metrics.evaluated(e.steps, ok)
+	if !ok {
+		g.debug("string rejected", "input", s, "steps", e.steps)
+	}
 }
 
-func (g *CFG) Evaluate(s string) (Path, bool) {
-	// Check each production rule for the start variable.
-	for _, production := range g.mappedRules[g.StartVariable] {
-		if _, p, ok := g.evaluate(s, production.A, production.B, 0, Path{production}); ok {
-			// The string is accepted if the string is empty.
-			return p, true
-		}
+func (g *CFG) newEvaluation(s string) *evaluation {
+	e := &evaluation{depth: g.limits.Depth, fields: g.fields}
+	if e.depth == AutoDepth {
+		e.depth = g.DepthBound(len(s))
+	}
+	if g.resources.Timeout != 0 {
+		e.deadline = time.Now().Add(g.resources.Timeout)
 	}
-	return nil, false
+	return e
 }
 
-func (g *CFG) String() string {
-	return fmt.Sprintf(
-		"( { %v }, { %v }, [ %v ], %s )",
-		join(g.Variables, ", "),
-		join(g.Alphabet, ", "),
-		g.Rules,
-		g.StartVariable,
-	)
+// step counts a production that is tried, it returns false if the step limit or the timeout is exceeded.
+func (g *CFG) step(e *evaluation) bool {
+	if e.err != nil || e.cancelled != nil && e.cancelled.Load() {
+		return false
+	}
+	if e.steps++; 0 < g.limits.Steps && g.limits.Steps < e.steps {
+		e.err = fmt.Errorf("%w: more than %d steps", ErrResourceLimit, g.limits.Steps)
+		return false
+	}
+	// Reading the clock is expensive compared to a step, so it is only checked every so often.
+	if !e.deadline.IsZero() && e.steps%256 == 0 && time.Now().After(e.deadline) {
+		e.err = fmt.Errorf("%w: timeout of %v", ErrResourceLimit, g.resources.Timeout)
+		return false
+	}
+	return true
 }
 
-func (g *CFG) evaluate(s string, alpha Alpha, production []Beta, depth int, path Path) (string, Path, bool) {
-	if g.depth <= depth {
-		return "", path, false
+// names returns the names of the variables and terminals of the grammar, a fresh variable must not collide with any
+// of them.
+func (g *CFG) names() map[Variable]bool {
+	used := make(map[Variable]bool)
+	for _, v := range g.Variables {
+		used[v] = true
 	}
-	for _, beta := range production {
-		switch beta := beta.(type) {
-		case Terminal:
-			// If the production rule is `S → ε`, then we can just handle the remaining production rules.
-			if beta == Epsilon {
-				return g.evaluate(s, alpha, production[1:], depth+1, path)
-			}
-			// If the string starts with the terminal, then we can handle the remaining production rules.
-			if strings.HasPrefix(s, string(beta)) {
-				return g.evaluate(s[len(beta):], alpha, production[1:], depth, path)
-			}
-			// Otherwise, the string is not accepted, backtrack.
-			return "", path, false
-		case Variable:
-			for _, p := range g.mappedRules[beta] {
-				// We can inline the production and try to evaluate the string.
-				if s, path, ok := g.evaluate(s, p.A, append(p.B, production[1:]...), depth+1, append(path, p)); ok {
-					return s, path, true
-				}
-			}
-			// If no production rule for the variable is accepted, then the string is not accepted, backtrack.
-			return "", path, false
+	for _, a := range g.Alphabet {
+		used[Variable(a)] = true
+	}
+	return used
+}
+
+// numbered returns the first variable `<prefix><i>` that is not yet used, starting at the given index, and advances
+// the index past it.
+func numbered(prefix string, i *int, used map[Variable]bool) Variable {
+	for {
+		v := Variable(fmt.Sprintf("%s%d", prefix, *i))
+		*i++
+		if !used[v] {
+			used[v] = true
+			return v
 		}
 	}
-	return "", path, s == ""
 }
 
-func (g *CFG) getVariable() string {
-	i := g.lastIndex
-	g.lastIndex++
-	return fmt.Sprintf("V%v", i)
+// Limits bounds the search of Evaluate, a limit of zero disables it.
+type Limits struct {
+	// Depth is the maximum depth of the derivation tree, counted in productions from the root to a leaf.
+	Depth int
+	// Length is the maximum number of symbols of a sentential form, ε is not counted.
+	Length int
+	// Steps is the maximum number of productions that are tried in a single evaluation.
+	Steps int
 }
 
+// Option configures the construction of a grammar, see New.
+type Option func(*options)
+
+// Path is a leftmost derivation: the production rules in the order they were applied.
 type Path []Production
 
+// Replay returns the sentential forms of the derivation, separated by arrows.
 func (p Path) Replay() string {
+	return strings.Join(p.steps(), " → ")
+}
+
+func (p Path) String() string {
+	return fmt.Sprintf("[ %v ]", join(p, ", "))
+}
+
+// steps returns the sentential forms of the derivation, starting with the start variable.
+func (p Path) steps() []string {
 	if len(p) == 0 {
-		return ""
+		return nil
 	}
 	ss := []string{p[0].A.String(), join(p[0].B, "")}
 	for _, p := range p[1:] {
@@ -411,17 +610,31 @@ func (p Path) Replay() string {
 		}
 		ss = append(ss, s[:i]+join(p.B, "")+s[i+len(p.A.String()):])
 	}
-	return strings.Join(ss, " → ")
+	return ss
 }
 
-func (p Path) String() string {
-	return fmt.Sprintf("[ %v ]", join(p, ", "))
+// Position is a position in a grammar file, the line and column start at 1. The zero value is an unknown position.
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 // Production is a production rule.
 type Production struct {
 	A Alpha
 	B []Beta
+
+	// Label is an optional name of the production (e.g. `expr.add`), it is not part of the rule itself.
+	Label string
+	// Position is the optional source position of the production.
+	Position Position
+	// Captures are the optional capture names of the symbols of B, "" for a symbol without a name, see Tree.Captures.
+	// Like the label they are not part of the rule itself.
+	Captures []string
 }
 
 func NewProduction(alpha Alpha, beta []Beta) Production {
@@ -431,7 +644,7 @@ func NewProduction(alpha Alpha, beta []Beta) Production {
 	}
 }
 
-// Equal checks if two production rules are equal.
+// Equal checks if two production rules are equal, the label and position are ignored.
 func (p Production) Equal(other Production) bool {
 	if p.A != other.A {
 		return false
@@ -448,30 +661,123 @@ func (p Production) Equal(other Production) bool {
 }
 
 func (p Production) String() string {
-	return fmt.Sprintf("%v → %v", p.A, join(p.B, ""))
+	if p.Label != "" {
+		return fmt.Sprintf("%v → %v #%s", p.A, p.rhs(), p.Label)
+	}
+	return fmt.Sprintf("%v → %v", p.A, p.rhs())
+}
+
+// WithLabel returns a copy of the production with the given label.
+func (p Production) WithLabel(label string) Production {
+	p.Label = label
+	return p
+}
+
+// capture returns the capture name of the i-th symbol of the production, "" if it has none.
+func (p Production) capture(i int) string {
+	if 0 <= i && i < len(p.Captures) {
+		return p.Captures[i]
+	}
+	return ""
+}
+
+// capturesAt returns the capture names of the symbols at the given positions, e.g. for a rewritten right-hand side
+// whose symbols were at these positions before. A negative position is a symbol without a name. The result is nil if
+// none of the symbols has a name.
+func (p Production) capturesAt(positions []int) []string {
+	var captures []string
+	for j, i := range positions {
+		if name := p.capture(i); name != "" {
+			if captures == nil {
+				captures = make([]string, len(positions))
+			}
+			captures[j] = name
+		}
+	}
+	return captures
+}
+
+// rhs returns the symbols of the production together with their capture names, e.g. `(body:S)`.
+func (p Production) rhs() string {
+	var b strings.Builder
+	for i, beta := range p.B {
+		if name := p.capture(i); name != "" {
+			b.WriteString(name)
+			b.WriteByte(':')
+		}
+		b.WriteString(beta.String())
+	}
+	return b.String()
+}
+
+// describe returns the production together with its line, if known (e.g. `S → aSa (line 3)`).
+func (p Production) describe() string {
+	if p.Position.Line == 0 {
+		return p.String()
+	}
+	return fmt.Sprintf("%v (line %d)", p, p.Position.Line)
+}
+
+// key identifies the rule itself, ignoring its metadata.
+func (p Production) key() string {
+	var b strings.Builder
+	writeKey(&b, Variable(p.A.String()))
+	b.WriteString(formKey(p.B))
+	return b.String()
+}
+
+// formKey identifies a sequence of symbols, ε is the empty sequence.
+func formKey(form []Beta) string {
+	var b strings.Builder
+	for _, beta := range form {
+		if beta != Epsilon {
+			writeKey(&b, beta)
+		}
+	}
+	return b.String()
+}
+
+// writeKey writes the kind of the symbol and the length of its name before the name, so the terminal "a b" is not
+// confused with the terminals "a" and "b", or a terminal with a variable of the same name.
+func writeKey(b *strings.Builder, beta Beta) {
+	switch beta.(type) {
+	case Terminal:
+		b.WriteByte('t')
+	case Variable:
+		b.WriteByte('v')
+	case Category:
+		b.WriteByte('c')
+	default:
+		fmt.Fprintf(b, "%T", beta)
+	}
+	s := beta.String()
+	b.WriteString(strconv.Itoa(len(s)))
+	b.WriteByte(':')
+	b.WriteString(s)
 }
 
 // R is a set of production rules. Formalized: `(α, β) ∈ R`, with `α ∈ V` and `β ∈ (V ∪ Σ)*`.
 type R []Production
 
 func (r R) Sort() {
-	sort.Slice(r, func(i, j int) bool {
-		a := r[i].A.String()
-		b := r[j].A.String()
-		if a == b {
-			ab := join(r[i].B, "")
-			bb := join(r[j].B, "")
-			return ab < bb
-		} else {
-			return a < b
-		}
-	})
+	sort.Slice(r, r.less)
 }
 
 func (r R) String() string {
 	return join(r, ", ")
 }
 
+// less orders the rules by their variable, and then by the concatenation of their symbols.
+func (r R) less(i, j int) bool {
+	a := r[i].A.String()
+	b := r[j].A.String()
+	if a == b {
+		return compareJoined(r[i].B, r[j].B) < 0
+	} else {
+		return a < b
+	}
+}
+
 // Terminal is an elementary symbol of a context-free grammar.
 type Terminal string
 
@@ -494,3 +800,49 @@ func (v Variable) String() string {
 func (Variable) a() {}
 
 func (Variable) b() {}
+
+// emptyString is the type of Epsilon.
+type emptyString struct{}
+
+func (emptyString) String() string {
+	return "ε"
+}
+
+func (emptyString) b() {}
+
+// symbol is a symbol of a sentential form, together with its depth in the derivation tree. The ID of a variable is
+// the one of the symbol table of the grammar.
+type symbol struct {
+	beta  Beta
+	id    int
+	depth int
+}
+
+// evaluation is the state of a single call to Evaluate.
+type evaluation struct {
+	// depth is the maximum depth of the derivation tree.
+	depth int
+	// steps is the number of productions that were tried.
+	steps int
+	// yield, if set, receives every derivation of the string, the search continues afterwards.
+	yield func(Path)
+	// deadline is the end of the timeout, if any.
+	deadline time.Time
+	// err is the resource limit that aborted the search.
+	err error
+	// fields is true if the terminals match whole fields of the input, see SetFields.
+	fields bool
+	// arena, if set, holds the sentential forms of the depth-first search.
+	arena *arena
+	// cancelled, if set, aborts the search once it is true, see Parallel.
+	cancelled *atomic.Bool
+}
+
+// options are the options of New.
+type options struct {
+	strict          bool
+	unusedTerminals bool
+	logger          *slog.Logger
+	metrics         *Metrics
+	resources       ResourceLimits
+}
@@ -1,6 +1,7 @@
 package cfg_test
 
 import (
+	"errors"
 	"fmt"
 	"github.com/0x51-dev/cfg"
 	"testing"
@@ -72,7 +73,6 @@ func ExampleCFG_parentheses() {
 		},
 		S,
 	)
-	g.Depth(15) // Needed since the default depth is 10, 14 is needed for the example.
 
 	in := "([[[()()[][]]]([])])"
 	fmt.Println(g)
@@ -148,6 +148,45 @@ func TestCFG_Evaluate_palindrome(t *testing.T) {
 	}
 }
 
+// The memory of an evaluation is reused by the next one, the derivations must not share it.
+func TestCFG_Evaluate_reuse(t *testing.T) {
+	p, ok := g.Evaluate("abba")
+	if !ok {
+		t.Fatal("expected abba to be accepted")
+	}
+	expected := fmt.Sprint(p)
+	for _, s := range []string{"baab", "aaaa", "bbbbbb"} {
+		if _, ok := g.Evaluate(s); !ok {
+			t.Fatalf("expected %q to be accepted", s)
+		}
+	}
+	if fmt.Sprint(p) != expected {
+		t.Errorf("expected %s, got %s", expected, p)
+	}
+}
+
+func TestCFG_SetLimits(t *testing.T) {
+	g, _ := cfg.Parse("S → aSa | bSb | ε\n")
+	for _, test := range []struct {
+		limits   cfg.Limits
+		accepted bool
+	}{
+		{cfg.DefaultLimits, true},
+		{cfg.Limits{Depth: cfg.AutoDepth}, true},
+		{cfg.Limits{Depth: 4}, true},
+		{cfg.Limits{Depth: 3}, false},
+		{cfg.Limits{Length: 7}, true},
+		{cfg.Limits{Length: 6}, false},
+		{cfg.Limits{Steps: 7}, true},
+		{cfg.Limits{Steps: 6}, false},
+	} {
+		g.SetLimits(test.limits)
+		if _, ok := g.Evaluate("aabbaa"); ok != test.accepted {
+			t.Errorf("%+v: expected %v, got %v", test.limits, test.accepted, ok)
+		}
+	}
+}
+
 func TestR_CNF(t *testing.T) {
 	S := cfg.Variable("S")
 	X := cfg.Variable("X")
@@ -176,7 +215,6 @@ func TestR_CNF(t *testing.T) {
 	cnf.Sort()
 	T0 := cfg.Variable("T0")
 	T1 := cfg.Variable("T1")
-	T2 := cfg.Variable("T2")
 	V0 := cfg.Variable("V0")
 	V1 := cfg.Variable("V1")
 	V2 := cfg.Variable("V2")
@@ -187,19 +225,21 @@ func TestR_CNF(t *testing.T) {
 		cfg.NewProduction(S, []cfg.Beta{T0, V2}),
 		cfg.NewProduction(T0, []cfg.Beta{a}),
 		cfg.NewProduction(T1, []cfg.Beta{b}),
-		cfg.NewProduction(T2, []cfg.Beta{c}),
 		cfg.NewProduction(V0, []cfg.Beta{X, T1}),
 		cfg.NewProduction(V1, []cfg.Beta{X, V2}),
 		cfg.NewProduction(V2, []cfg.Beta{T1, X}),
-		cfg.NewProduction(X, []cfg.Beta{T0}),
 		cfg.NewProduction(X, []cfg.Beta{T0, Y}),
-		cfg.NewProduction(X, []cfg.Beta{T1}),
 		cfg.NewProduction(X, []cfg.Beta{T1, Y}),
-		cfg.NewProduction(Y, []cfg.Beta{T0}),
+		cfg.NewProduction(X, []cfg.Beta{a}),
+		cfg.NewProduction(X, []cfg.Beta{b}),
 		cfg.NewProduction(Y, []cfg.Beta{T0, Y}),
-		cfg.NewProduction(Y, []cfg.Beta{T1}),
 		cfg.NewProduction(Y, []cfg.Beta{T1, Y}),
-		cfg.NewProduction(Y, []cfg.Beta{T2}),
+		cfg.NewProduction(Y, []cfg.Beta{a}),
+		cfg.NewProduction(Y, []cfg.Beta{b}),
+		cfg.NewProduction(Y, []cfg.Beta{c}),
+	}
+	if len(cnf) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, cnf)
 	}
 	for i, v := range cnf {
 		if !v.Equal(expected[i]) {
@@ -207,3 +247,280 @@ func TestR_CNF(t *testing.T) {
 		}
 	}
 }
+
+func TestR_CNF_terminals(t *testing.T) {
+	g, err := cfg.Parse("S → aAb\nA → a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnf := g.CNF()
+	cnf.Sort()
+	if s := cnf.String(); s != "A → a, S → AV0, T1 → b, V0 → AT1" {
+		t.Errorf("unexpected CNF: %s", s)
+	}
+}
+
+func TestR_CNF_idempotent(t *testing.T) {
+	g, err := cfg.Parse("S → aXbX\nX → aY | bY | ε\nY → X | c\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cnf := g.CNF()
+	if again := g.CNF(); again.String() != cnf.String() {
+		t.Errorf("expected %v, got %v", cnf, again)
+	}
+
+	var variables cfg.V
+	seen := make(map[cfg.Alpha]bool)
+	for _, rule := range cnf {
+		if !seen[rule.A] {
+			seen[rule.A] = true
+			variables = append(variables, rule.A.(cfg.Variable))
+		}
+	}
+	h, err := cfg.New(variables, g.Alphabet, cnf, g.StartVariable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := h.CNF(); again.String() != cnf.String() {
+		t.Errorf("expected %v, got %v", cnf, again)
+	}
+}
+
+func TestR_CNF_units(t *testing.T) {
+	g, err := cfg.Parse("S → A | C\nA → B | C\nB → C | b\nC → B | c\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.CNF().String(); s != "A → b, A → c, B → b, B → c, C → b, C → c, S → b, S → c" {
+		t.Errorf("unexpected CNF: %s", s)
+	}
+}
+
+func TestR_CNF_nullable(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		expected string
+	}{
+		{"S → AB\nA → ε\nB → ε\n", ""},
+		{"S → AB | c\nA → ε\nB → ε\n", "S → c"},
+		{"S → AbB\nA → a | ε\nB → b | ε\n", "A → a, B → b, S → AB, S → AV0, S → BB, S → b, V0 → BB"},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := g.CNF().String(); s != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, s)
+		}
+	}
+}
+
+func TestR_CNF_adjacentEpsilon(t *testing.T) {
+	g, err := cfg.Parse("S → aAB\nA → ε\nB → ε\nA → a\nB → b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := g.Rules.String()
+	if s := g.CNF().String(); s != "A → a, B → b, S → AA, S → AB, S → AV0, S → a, V0 → AB" {
+		t.Errorf("unexpected CNF: %s", s)
+	}
+	if g.Rules.String() != rules {
+		t.Errorf("expected the rules to be unchanged, got %v", g.Rules)
+	}
+}
+
+func TestR_CNF_sharedSuffix(t *testing.T) {
+	g, err := cfg.Parse("S → ABCD | EBCD\nA → a\nB → b\nC → c\nD → d\nE → e\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "A → a, B → b, C → c, D → d, E → e, S → AV0, S → EV0, V0 → BV1, V1 → CD"
+	if s := g.CNF().String(); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+}
+
+func TestR_CNF_collision(t *testing.T) {
+	S, T0 := cfg.Variable("S"), cfg.Variable("T0")
+	a, b := cfg.Terminal("a"), cfg.Terminal("b")
+	g, err := cfg.New(cfg.V{S, T0}, cfg.Alphabet{b, a}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{b, T0}),
+		cfg.NewProduction(S, []cfg.Beta{T0}),
+		cfg.NewProduction(T0, []cfg.Beta{a}),
+		cfg.NewProduction(T0, []cfg.Beta{a, a}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "S → T0'T0, S → T1T1, S → a, T0 → T1T1, T0 → a, T0' → b, T1 → a"
+	if s := g.CNF().String(); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+	c, err := g.CYK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "a", accepted: true},
+		{input: "aa", accepted: true},
+		{input: "ba", accepted: true},
+		{input: "baa", accepted: true},
+		{input: "bb", accepted: false},
+		{input: "b", accepted: false},
+	} {
+		if ok := c.Recognize(test.input); ok != test.accepted {
+			t.Errorf("%q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+	}
+}
+
+func TestR_RemoveUnits_spaces(t *testing.T) {
+	// The terminal "a b" is not the sequence of the terminals a and b.
+	S, A := cfg.Variable("S"), cfg.Variable("A")
+	g, err := cfg.New(cfg.V{S, A}, cfg.Alphabet{"a b", "a", "b"}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{A}),
+		cfg.NewProduction(S, []cfg.Beta{cfg.Terminal("a"), cfg.Terminal("b")}),
+		cfg.NewProduction(A, []cfg.Beta{cfg.Terminal("a b")}),
+		cfg.NewProduction(A, []cfg.Beta{cfg.Terminal("a"), cfg.Terminal("b")}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, warning := range g.Lint() {
+		t.Errorf("unexpected warning: %v", warning)
+	}
+	h, _, err := g.Transform(cfg.RemoveEpsilon, cfg.RemoveUnits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"a b", "ab"} {
+		if _, ok := h.Evaluate(s); !ok {
+			t.Errorf("expected %q to be accepted by %v", s, h.Rules)
+		}
+	}
+}
+
+func TestR_CNF_spaces(t *testing.T) {
+	S := cfg.Variable("S")
+	x, y, c := cfg.Terminal("x"), cfg.Terminal("y"), cfg.Terminal("c")
+	g, err := cfg.New(cfg.V{S}, cfg.Alphabet{"x", "y", "a", "b", "c", "a b", "b c"}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{x, cfg.Terminal("a b"), c}),
+		cfg.NewProduction(S, []cfg.Beta{y, cfg.Terminal("a"), cfg.Terminal("b c")}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := g.Transform(cfg.Binarize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"xa bc", "yab c"} {
+		if _, ok := h.Evaluate(s); !ok {
+			t.Errorf("expected %q to be accepted by %v", s, h.Rules)
+		}
+	}
+}
+
+func TestR_CNF_terminalNames(t *testing.T) {
+	S := cfg.Variable("S")
+	a, V0, T0 := cfg.Terminal("a"), cfg.Terminal("V0"), cfg.Terminal("T0")
+	g, err := cfg.New(cfg.V{S}, cfg.Alphabet{a, V0, T0}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{a, a, V0, T0}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := g.Transform(cfg.Binarize); err != nil {
+		t.Fatal(err)
+	}
+	expected := "S → T0'V1, T0' → a, T1 → V0, T2 → T0, V1 → T0'V2, V2 → T1T2"
+	if s := g.CNF().String(); s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+}
+
+func ExampleCFG_Alternatives() {
+	g, _ := cfg.Parse("S → ε | aSb | c\n")
+	fmt.Println(g.RulesFor("S"))
+	fmt.Println(g.Alternatives("S"))
+	// Output:
+	// [S → ε S → aSb S → c]
+	// [S → aSb S → c S → ε]
+}
+
+func TestNew_epsilon(t *testing.T) {
+	S := cfg.Variable("S")
+	a := cfg.Terminal("a")
+	for _, test := range []struct {
+		alphabet cfg.Alphabet
+		rules    cfg.R
+		err      error
+	}{
+		// The terminal ε is not the empty string.
+		{cfg.Alphabet{a, "ε"}, cfg.R{cfg.NewProduction(S, []cfg.Beta{cfg.Terminal("ε"), cfg.Epsilon})}, nil},
+		{cfg.Alphabet{a, ""}, cfg.R{cfg.NewProduction(S, []cfg.Beta{a})}, cfg.ErrEmptyTerminal},
+		{cfg.Alphabet{a}, cfg.R{cfg.NewProduction(S, []cfg.Beta{a, cfg.Terminal("")})}, cfg.ErrEmptyTerminal},
+	} {
+		if _, err := cfg.New(cfg.V{S}, test.alphabet, test.rules, S); !errors.Is(err, test.err) {
+			t.Errorf("expected %v, got %v", test.err, err)
+		}
+	}
+}
+
+func TestR_CNF_labels(t *testing.T) {
+	g, err := cfg.Parse("S → aSb #wrap | c #base\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.CNF().String(); s != "S → T0V0 #wrap, S → c #base, T0 → a, T1 → b, V0 → ST1" {
+		t.Errorf("unexpected CNF: %s", s)
+	}
+}
+
+func TestNew_strict(t *testing.T) {
+	S, A, B := cfg.Variable("S"), cfg.Variable("A"), cfg.Variable("B")
+	a, b := cfg.Terminal("a"), cfg.Terminal("b")
+	for _, test := range []struct {
+		rules cfg.R
+		err   error
+	}{
+		{cfg.R{
+			cfg.NewProduction(S, []cfg.Beta{a, S, a}),
+			cfg.NewProduction(S, []cfg.Beta{b, S, b}),
+			cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+		}, nil},
+		// Identical alternatives.
+		{cfg.R{
+			cfg.NewProduction(S, []cfg.Beta{a, A}),
+			cfg.NewProduction(S, []cfg.Beta{a, A}),
+			cfg.NewProduction(A, []cfg.Beta{b}),
+			cfg.NewProduction(B, []cfg.Beta{b}),
+		}, cfg.ErrAmbiguous},
+		// Both S → ε and S → A derive ε.
+		{cfg.R{
+			cfg.NewProduction(S, []cfg.Beta{A}),
+			cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+			cfg.NewProduction(A, []cfg.Beta{B, B}),
+			cfg.NewProduction(B, []cfg.Beta{cfg.Epsilon}),
+		}, cfg.ErrAmbiguous},
+		// S ⇒ A ⇒ S.
+		{cfg.R{
+			cfg.NewProduction(S, []cfg.Beta{A}),
+			cfg.NewProduction(S, []cfg.Beta{a}),
+			cfg.NewProduction(A, []cfg.Beta{S}),
+			cfg.NewProduction(B, []cfg.Beta{b}),
+		}, cfg.ErrAmbiguous},
+	} {
+		if _, err := cfg.New(cfg.V{S, A, B}, cfg.Alphabet{a, b}, test.rules, S, cfg.WithStrict()); !errors.Is(err, test.err) {
+			t.Errorf("expected %v, got %v", test.err, err)
+		}
+		// Without strict mode the grammars are accepted.
+		if _, err := cfg.New(cfg.V{S, A, B}, cfg.Alphabet{a, b}, test.rules, S); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+// ErrExhausted is returned by GenerateN and NearMisses if they give up before finding the requested number of
+// distinct strings, e.g. because the language is finite or has no strings of the target length.
+var ErrExhausted = errors.New("no new strings found")
+
+// maxDuplicates is the number of consecutive duplicates after which GenerateN gives up.
+const maxDuplicates = 1000
+
+// maxGenerationSteps bounds the number of symbols a generator processes for a single string, random derivations of
+// some grammars are infinite with a positive probability.
+const maxGenerationSteps = 1 << 20
+
+// NewGenerator returns a generator of random strings of the language that chooses between the rules of a variable
+// uniformly. The seed makes the generated strings reproducible.
+func NewGenerator(g *CFG, seed int64) *Generator {
+	weights := make([]float64, len(g.Rules))
+	for i := range weights {
+		weights[i] = 1
+	}
+	gen := newGenerator(g, weights, seed)
+	gen.uniform = true
+	return gen
+}
+
+func newGenerator(g *CFG, weights []float64, seed int64) *Generator {
+	t := g.symbols
+	gen := &Generator{
+		g:         g,
+		seed:      seed,
+		random:    rand.New(rand.NewSource(seed)),
+		weights:   weights,
+		rules:     make([][]int, len(t.variables)),
+		minimum:   make([]int, len(t.rules)),
+		recursive: make([]bool, len(t.rules)),
+		target:    -1,
+	}
+	component := make(map[Variable]int)
+	sizes := make(map[int]int)
+	for i, c := range g.sccs() {
+		for _, v := range c {
+			component[v] = i
+		}
+		sizes[i] = len(c)
+	}
+	for i, r := range t.rules {
+		gen.rules[r.a] = append(gen.rules[r.a], i)
+		a := t.variables[r.a]
+		for _, s := range r.b {
+			if s < 0 {
+				// An unproductive rule stays unproductive, whatever terminals follow.
+				if 0 <= gen.minimum[i] {
+					gen.minimum[i] += t.length(t.terminals[-s-1])
+				}
+				continue
+			}
+			if gen.minimum[i] < 0 || t.minLengths[s] < 0 {
+				gen.minimum[i] = -1
+			} else {
+				gen.minimum[i] += t.minLengths[s]
+			}
+			if b := t.variables[s]; component[a] == component[b] && (a == b || 1 < sizes[component[a]]) {
+				gen.recursive[i] = true
+			}
+		}
+	}
+	return gen
+}
+
+// Generator generates random strings of the language of a grammar, by random leftmost derivations.
+type Generator struct {
+	g       *CFG
+	seed    int64
+	random  *rand.Rand
+	weights []float64
+	// rules are the indices of the rules of every variable, minimum the minimal lengths of the strings they derive (-1
+	// if they do not derive any string), and recursive whether they derive their own variable again.
+	rules     [][]int
+	minimum   []int
+	recursive []bool
+	// uniform is true if all rules have the same weight, the runes of a variable with RuneRanges are then sampled
+	// directly.
+	uniform bool
+
+	// target is the length of the strings, -1 if there is no target.
+	target, tolerance int
+}
+
+// Generator returns a generator that chooses between the rules of a variable by their probabilities.
+func (p *PCFG) Generator(seed int64) *Generator {
+	return newGenerator(p.CFG, p.Probabilities, seed)
+}
+
+// Generate returns a random string of the language together with its derivation. With a target length, it retries
+// until a string of the right length is found, and returns an error if none is found after a number of attempts.
+func (gen *Generator) Generate() (string, Path, error) {
+	ts, p, err := gen.sample()
+	if err != nil {
+		return "", nil, err
+	}
+	return join(ts, ""), p, nil
+}
+
+// GenerateN returns n distinct random strings of the language, in the order they were generated. If no new string is
+// found after a number of consecutive attempts, e.g. because the language is finite, it returns the strings found so
+// far together with an error wrapping ErrExhausted.
+func (gen *Generator) GenerateN(n int) ([]string, error) {
+	seen := make(map[string]struct{}, n)
+	strs := make([]string, 0, n)
+	for duplicates := 0; len(strs) < n; {
+		s, _, err := gen.Generate()
+		if err != nil {
+			return strs, err
+		}
+		if _, ok := seen[s]; ok {
+			if duplicates++; maxDuplicates <= duplicates {
+				return strs, fmt.Errorf("%w: found only %d of %d distinct strings", ErrExhausted, len(strs), n)
+			}
+			continue
+		}
+		seen[s] = struct{}{}
+		strs = append(strs, s)
+		duplicates = 0
+	}
+	return strs, nil
+}
+
+// NearMisses returns n distinct strings that are not in the language but differ from a random string of the language
+// by a single terminal: one terminal is replaced, removed or inserted. Every mutation is verified to be rejected on
+// the level of terminals, i.e. the sequence of terminals is not derivable. If the target length is set, the strings of
+// the language are of that length. If no new string is found after a number of consecutive attempts, it returns the
+// strings found so far together with an error wrapping ErrExhausted.
+func (gen *Generator) NearMisses(n int) ([]string, error) {
+	alphabet := gen.g.Alphabet
+	seen := make(map[string]struct{}, n)
+	strs := make([]string, 0, n)
+	for failures := 0; len(strs) < n; {
+		if maxDuplicates <= failures {
+			return strs, fmt.Errorf("%w: found only %d of %d near misses", ErrExhausted, len(strs), n)
+		}
+		failures++
+		ts, _, err := gen.sample()
+		if err != nil {
+			return strs, err
+		}
+		mutated := gen.mutate(ts, alphabet)
+		if mutated == nil {
+			continue
+		}
+		m := join(mutated, "")
+		if _, ok := seen[m]; ok {
+			continue
+		}
+		form := make([]Beta, len(mutated))
+		for i, t := range mutated {
+			form[i] = t
+		}
+		if gen.g.DerivesSentential(form) {
+			continue
+		}
+		seen[m] = struct{}{}
+		strs = append(strs, m)
+		failures = 0
+	}
+	return strs, nil
+}
+
+// TargetLength makes the generator produce strings of the given length (in bytes), plus or minus the tolerance. While
+// the shortest string the current sentential form can derive is shorter than the target, the recursive rules are
+// preferred, proportional to the remaining length; afterwards the shortest completion is taken. A negative length
+// disables the target.
+func (gen *Generator) TargetLength(length, tolerance int) {
+	gen.target, gen.tolerance = length, tolerance
+}
+
+// choose returns the index of the rule for the variable, given the minimal length of the string derived by the current
+// sentential form (including the variable). It returns an error if the variable does not derive any string.
+func (gen *Generator) choose(v, minimum int) (int, error) {
+	t := gen.g.symbols
+	if t.shortest[v] < 0 {
+		return 0, fmt.Errorf("variable %v does not derive any string", t.variables[v])
+	}
+	budget := gen.target - minimum
+	if 0 <= gen.target && budget <= 0 {
+		return t.shortest[v], nil
+	}
+	if c := t.classes[v]; c != nil && gen.uniform {
+		return c.rule[c.nth(gen.random.Intn(c.size))], nil
+	}
+	var candidates []int
+	var weights []float64
+	var total float64
+	for _, i := range gen.rules[v] {
+		if gen.minimum[i] < 0 || gen.weights[i] <= 0 {
+			continue
+		}
+		w := gen.weights[i]
+		if 0 <= gen.target {
+			if gen.target+gen.tolerance < minimum-t.minLengths[v]+gen.minimum[i] {
+				continue
+			}
+			if gen.recursive[i] {
+				w *= float64(1 + budget)
+			}
+		}
+		candidates = append(candidates, i)
+		weights = append(weights, w)
+		total += w
+	}
+	if len(candidates) == 0 {
+		return t.shortest[v], nil
+	}
+	x := gen.random.Float64() * total
+	for j, w := range weights {
+		if x < w {
+			return candidates[j], nil
+		}
+		x -= w
+	}
+	return candidates[len(candidates)-1], nil
+}
+
+// sample returns the terminals of a random string of the language, of the target length if there is one.
+func (gen *Generator) sample() ([]Terminal, Path, error) {
+	attempts := 1
+	if 0 <= gen.target {
+		attempts = 100
+	}
+	for i := 0; i < attempts; i++ {
+		ts, p, err := gen.generate()
+		if err != nil {
+			return nil, nil, err
+		}
+		if n := len(join(ts, "")); gen.target < 0 || (gen.target-gen.tolerance <= n && n <= gen.target+gen.tolerance) {
+			return ts, p, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: no string of length %d ± %d found", ErrExhausted, gen.target, gen.tolerance)
+}
+
+// mutate returns a copy of the terminals with a random terminal replaced, removed or inserted, or nil if there is no
+// possible mutation.
+func (gen *Generator) mutate(ts []Terminal, alphabet Alphabet) []Terminal {
+	const (
+		replace = iota
+		remove
+		insert
+	)
+	var kinds []int
+	if len(ts) != 0 && len(alphabet) != 0 {
+		kinds = append(kinds, replace)
+	}
+	if len(ts) != 0 {
+		kinds = append(kinds, remove)
+	}
+	if len(alphabet) != 0 {
+		kinds = append(kinds, insert)
+	}
+	if len(kinds) == 0 {
+		return nil
+	}
+	mutated := make([]Terminal, 0, len(ts)+1)
+	switch kinds[gen.random.Intn(len(kinds))] {
+	case replace:
+		i := gen.random.Intn(len(ts))
+		mutated = append(append(append(mutated, ts[:i]...), alphabet[gen.random.Intn(len(alphabet))]), ts[i+1:]...)
+	case remove:
+		i := gen.random.Intn(len(ts))
+		mutated = append(append(mutated, ts[:i]...), ts[i+1:]...)
+	case insert:
+		i := gen.random.Intn(len(ts) + 1)
+		mutated = append(append(append(mutated, ts[:i]...), alphabet[gen.random.Intn(len(alphabet))]), ts[i:]...)
+	}
+	return mutated
+}
+
+// generate returns the terminals of a random string of the language together with its derivation.
+func (gen *Generator) generate() ([]Terminal, Path, error) {
+	t := gen.g.symbols
+	start := t.variable[gen.g.StartVariable]
+	if t.minLengths[start] < 0 {
+		return nil, nil, fmt.Errorf("the language is empty")
+	}
+	var ts []Terminal
+	var path Path
+	// The symbols that are not yet derived, the leftmost one last, and the minimal length of the derived string.
+	stack := []int{start}
+	minimum := t.minLengths[start]
+	for steps := 0; len(stack) != 0; steps++ {
+		if maxGenerationSteps < steps {
+			return nil, nil, fmt.Errorf("derivation exceeds %d steps", maxGenerationSteps)
+		}
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if s < 0 {
+			switch a := t.terminals[-s-1].(type) {
+			case Terminal:
+				ts = append(ts, a)
+			case Category:
+				c := t.category(a)
+				ts = append(ts, Terminal(c.nth(gen.random.Intn(c.size))))
+			}
+			continue
+		}
+		i, err := gen.choose(s, minimum)
+		if err != nil {
+			return nil, nil, err
+		}
+		minimum += gen.minimum[i] - t.minLengths[s]
+		path = append(path, gen.g.Rules[i])
+		r := t.rules[i]
+		for j := len(r.b) - 1; 0 <= j; j-- {
+			stack = append(stack, r.b[j])
+		}
+	}
+	return ts, path, nil
+}
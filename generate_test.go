@@ -0,0 +1,152 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/grammars"
+	"testing"
+)
+
+func ExampleGenerator_TargetLength() {
+	gen := cfg.NewGenerator(grammars.Dyck(), 1)
+	gen.TargetLength(40, 4)
+	s, _, _ := gen.Generate()
+	fmt.Println(36 <= len(s) && len(s) <= 44)
+	// Output:
+	// true
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	for _, grammar := range grammars.All() {
+		g := grammar.New()
+		gen := cfg.NewGenerator(g, 42)
+		for _, n := range []int{0, 10, 50} {
+			gen.TargetLength(n, 5)
+			s, p, err := gen.Generate()
+			if err != nil {
+				t.Errorf("%s: %v", grammar.Name, err)
+				continue
+			}
+			if len(s) < n-5 || n+5 < len(s) {
+				t.Errorf("%s: expected a string of length %d ± 5, got %q", grammar.Name, n, s)
+			}
+			tree, err := p.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u, err := tree.Unparse(g); err != nil || u != s {
+				t.Errorf("%s: expected the derivation of %q, got %q (%v)", grammar.Name, s, u, err)
+			}
+		}
+	}
+
+	// The same seed generates the same strings.
+	a, _, _ := cfg.NewGenerator(grammars.Expression(), 7).Generate()
+	b, _, _ := cfg.NewGenerator(grammars.Expression(), 7).Generate()
+	if a != b {
+		t.Errorf("expected the same strings, got %q and %q", a, b)
+	}
+}
+
+func TestGenerator_unproductive(t *testing.T) {
+	S, B := cfg.Variable("S"), cfg.Variable("B")
+	a, c := cfg.Terminal("a"), cfg.Terminal("c")
+	// B has no productions, so S → Ba does not derive any string.
+	g, err := cfg.New(cfg.V{S, B}, cfg.Alphabet{a, c}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{B, a}),
+		cfg.NewProduction(S, []cfg.Beta{c}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := cfg.NewGenerator(g, 1)
+	for _, target := range []int{-1, 0, 5} {
+		gen.TargetLength(target, 5)
+		for i := 0; i < 20; i++ {
+			if s, _, err := gen.Generate(); err != nil || s != "c" {
+				t.Fatalf("expected c, got %q (%v)", s, err)
+			}
+		}
+	}
+}
+
+func TestGenerator_GenerateN(t *testing.T) {
+	gen := cfg.NewGenerator(grammars.Palindrome(), 3)
+	gen.TargetLength(8, 4)
+	strs, err := gen.GenerateN(50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	for _, s := range strs {
+		if seen[s] {
+			t.Errorf("duplicate %q", s)
+		}
+		seen[s] = true
+	}
+	if len(strs) != 50 {
+		t.Errorf("expected 50 strings, got %d", len(strs))
+	}
+
+	// The language {a, b} has only two strings.
+	g, err := cfg.Parse("S → a | b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strs, err = cfg.NewGenerator(g, 1).GenerateN(3)
+	if err == nil || len(strs) != 2 {
+		t.Errorf("expected an error and two strings, got %q (%v)", strs, err)
+	}
+}
+
+func TestPCFG_Generator(t *testing.T) {
+	g, err := cfg.Parse("S → aS | bS | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.NewPCFG(g, []float64{0.5, 0, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen := p.Generator(1)
+	for i := 0; i < 100; i++ {
+		s, _, err := gen.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range s {
+			if r != 'a' {
+				t.Fatalf("expected only a, got %q", s)
+			}
+		}
+	}
+	empty, err := cfg.Parse("S → aS\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := cfg.NewGenerator(empty, 1).Generate(); err == nil {
+		t.Error("expected an error for an empty language")
+	}
+}
+
+func TestGenerator_NearMisses(t *testing.T) {
+	g := grammars.Dyck()
+	gen := cfg.NewGenerator(g, 5)
+	gen.TargetLength(10, 4)
+	misses, err := gen.NearMisses(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(misses) != 20 {
+		t.Errorf("expected 20 near misses, got %d", len(misses))
+	}
+	c, err := g.CYK()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range misses {
+		if c.Recognize(s) {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
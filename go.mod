@@ -1,5 +1,9 @@
 module github.com/0x51-dev/cfg
 
-go 1.20
+go 1.21
 
-require github.com/0x51-dev/upeg v0.1.1 // indirect
+require (
+	github.com/0x51-dev/upeg v0.1.1
+	github.com/BurntSushi/toml v1.4.0
+	gopkg.in/yaml.v3 v3.0.1
+)
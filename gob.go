@@ -0,0 +1,229 @@
+package cfg
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+func decodeBeta(s gobSymbol) Beta {
+	switch {
+	case s.Variable:
+		return Variable(s.Name)
+	case s.Epsilon:
+		return Epsilon
+	case s.Category:
+		return Category(s.Name)
+	}
+	return Terminal(s.Name)
+}
+
+func encodeBeta(b Beta) gobSymbol {
+	if c, ok := b.(Category); ok {
+		return gobSymbol{Category: true, Name: string(c)}
+	}
+	_, ok := b.(Variable)
+	return gobSymbol{Variable: ok, Epsilon: b == Epsilon, Name: b.String()}
+}
+
+func gobDecode(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+func gobEncode(v any) ([]byte, error) {
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(v); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// GobDecode decodes a grammar encoded by GobEncode. The grammar is not validated again.
+func (g *CFG) GobDecode(data []byte) error {
+	var v gobCFG
+	if err := gobDecode(data, &v); err != nil {
+		return err
+	}
+	mappedRules := make(map[Alpha][]Production)
+	for a, is := range v.Alternatives {
+		for _, i := range is {
+			if i < 0 || len(v.Rules) <= i {
+				return fmt.Errorf("invalid rule index %d", i)
+			}
+			mappedRules[a] = append(mappedRules[a], v.Rules[i])
+		}
+	}
+	var rejects map[Variable][][]Beta
+	for a, betas := range v.Rejects {
+		if rejects == nil {
+			rejects = make(map[Variable][][]Beta)
+		}
+		for _, beta := range betas {
+			var b []Beta
+			for _, s := range beta {
+				b = append(b, decodeBeta(s))
+			}
+			rejects[a] = append(rejects[a], b)
+		}
+	}
+	*g = CFG{
+		Variables:     v.Variables,
+		Alphabet:      v.Alphabet,
+		Rules:         v.Rules,
+		StartVariable: v.StartVariable,
+
+		limits:      Limits{Depth: v.Depth, Length: v.Length, Steps: v.Steps},
+		strategy:    v.Strategy,
+		fields:      v.Fields,
+		mappedRules: mappedRules,
+		symbols:     newSymbolTable(v.Variables, v.Alphabet, v.Rules, v.StartVariable, mappedRules),
+		memo:        newDeriveMemo(),
+		prefixes:    new(prefixGrammar),
+		dyck:        newDyck(v.Rules, v.StartVariable),
+
+		rejects:            rejects,
+		followRestrictions: v.FollowRestrictions,
+	}
+	return nil
+}
+
+// GobEncode encodes the grammar, including its index of alternatives and its disambiguation filters, so it can be
+// loaded without being rebuilt.
+func (g *CFG) GobEncode() ([]byte, error) {
+	// The alternatives are stored as indices into the rules.
+	key := func(p Production) string {
+		return fmt.Sprintf("%v %v", p, p.Position)
+	}
+	index := make(map[string]int)
+	for i, rule := range g.Rules {
+		if _, ok := index[key(rule)]; !ok {
+			index[key(rule)] = i
+		}
+	}
+	alternatives := make(map[Variable][]int)
+	for a, ps := range g.mappedRules {
+		for _, p := range ps {
+			alternatives[a.(Variable)] = append(alternatives[a.(Variable)], index[key(p)])
+		}
+	}
+	var rejects map[Variable][][]gobSymbol
+	for a, betas := range g.rejects {
+		if rejects == nil {
+			rejects = make(map[Variable][][]gobSymbol)
+		}
+		for _, beta := range betas {
+			var b []gobSymbol
+			for _, s := range beta {
+				b = append(b, encodeBeta(s))
+			}
+			rejects[a] = append(rejects[a], b)
+		}
+	}
+	return gobEncode(gobCFG{
+		Variables:     g.Variables,
+		Alphabet:      g.Alphabet,
+		Rules:         g.Rules,
+		StartVariable: g.StartVariable,
+		Depth:         g.limits.Depth,
+		Length:        g.limits.Length,
+		Steps:         g.limits.Steps,
+		Strategy:      g.strategy,
+		Fields:        g.fields,
+		Alternatives:  alternatives,
+
+		Rejects:            rejects,
+		FollowRestrictions: g.followRestrictions,
+	})
+}
+
+// GobDecode decodes an automaton encoded by GobEncode.
+func (a *LR0) GobDecode(data []byte) error {
+	var v gobLR0
+	if err := gobDecode(data, &v); err != nil {
+		return err
+	}
+	a.States = v.States
+	a.Transitions = make([]map[Beta]int, len(v.Transitions))
+	for i, ts := range v.Transitions {
+		a.Transitions[i] = make(map[Beta]int)
+		for s, j := range ts {
+			a.Transitions[i][decodeBeta(s)] = j
+		}
+	}
+	return nil
+}
+
+// GobEncode encodes the automaton, the interface keys of the transitions are not supported by gob itself.
+func (a *LR0) GobEncode() ([]byte, error) {
+	v := gobLR0{States: a.States, Transitions: make([]map[gobSymbol]int, len(a.Transitions))}
+	for i, ts := range a.Transitions {
+		v.Transitions[i] = make(map[gobSymbol]int)
+		for s, j := range ts {
+			v.Transitions[i][encodeBeta(s)] = j
+		}
+	}
+	return gobEncode(v)
+}
+
+// GobDecode decodes a production encoded by GobEncode.
+func (p *Production) GobDecode(data []byte) error {
+	var v gobProduction
+	if err := gobDecode(data, &v); err != nil {
+		return err
+	}
+	p.A = v.A
+	p.B = nil
+	for _, s := range v.B {
+		p.B = append(p.B, decodeBeta(s))
+	}
+	p.Label = v.Label
+	p.Position = v.Position
+	p.Captures = v.Captures
+	return nil
+}
+
+// GobEncode encodes the production. Since Alpha and Beta are interfaces, the symbols are encoded together with their
+// kind. This also makes all types containing productions (e.g. PredictiveTable) encodable.
+func (p Production) GobEncode() ([]byte, error) {
+	v := gobProduction{A: p.A.(Variable), Label: p.Label, Position: p.Position, Captures: p.Captures}
+	for _, b := range p.B {
+		v.B = append(v.B, encodeBeta(b))
+	}
+	return gobEncode(v)
+}
+
+type gobCFG struct {
+	Variables     V
+	Alphabet      Alphabet
+	Rules         R
+	StartVariable Variable
+	Depth         int
+	Length        int
+	Steps         int
+	Strategy      Strategy
+	Fields        bool
+	Alternatives  map[Variable][]int
+
+	Rejects            map[Variable][][]gobSymbol
+	FollowRestrictions map[Variable][]Terminal
+}
+
+type gobLR0 struct {
+	States      [][]Item
+	Transitions []map[gobSymbol]int
+}
+
+type gobProduction struct {
+	A        Variable
+	B        []gobSymbol
+	Label    string
+	Position Position
+	Captures []string
+}
+
+type gobSymbol struct {
+	Variable bool
+	Epsilon  bool
+	Category bool
+	Name     string
+}
@@ -0,0 +1,102 @@
+package cfg_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func TestCFG_GobEncode(t *testing.T) {
+	g, err := cfg.Parse("S → aSa #a | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(struct {
+		Grammar *cfg.CFG
+		Table   *cfg.PredictiveTable
+		LR0     *cfg.LR0
+	}{g, g.PredictiveTable(), g.LR0()}); err != nil {
+		t.Fatal(err)
+	}
+
+	var v struct {
+		Grammar *cfg.CFG
+		Table   *cfg.PredictiveTable
+		LR0     *cfg.LR0
+	}
+	if err := gob.NewDecoder(&b).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Grammar.String() != g.String() {
+		t.Errorf("expected %v, got %v", g, v.Grammar)
+	}
+	if v.Grammar.Rules[0].Label != "a" || v.Grammar.Rules[0].Position != g.Rules[0].Position {
+		t.Errorf("expected the metadata to be preserved, got %#v", v.Grammar.Rules[0])
+	}
+	if p, ok := v.Grammar.Evaluate("abba"); !ok || p.String() != "[ S → aSa #a, S → bSb, S → ε ]" {
+		t.Errorf("unexpected evaluation: %v %v", p, ok)
+	}
+	if v.Table.DOT() != g.PredictiveTable().DOT() {
+		t.Errorf("expected the predictive table to be preserved")
+	}
+	if v.LR0.DOT() != g.LR0().DOT() {
+		t.Errorf("expected the LR(0) automaton to be preserved")
+	}
+}
+
+func TestCFG_GobEncode_category(t *testing.T) {
+	g, err := cfg.Parse("S → \\p{L}S | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := g.GobEncode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var h cfg.CFG
+	if err := h.GobDecode(data); err != nil {
+		t.Fatal(err)
+	}
+	if h.Rules[0].B[0] != cfg.Category("L") {
+		t.Errorf("expected the category to be preserved, got %v", h.Rules[0])
+	}
+	if _, ok := h.Evaluate("äb"); !ok {
+		t.Error("expected äb to be accepted")
+	}
+}
+
+func TestCFG_GobEncode_filters(t *testing.T) {
+	g, err := cfg.Parse("S → Ib | I\nI → aI | a\nK → aa\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Reject("I", []cfg.Beta{cfg.Variable("K")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.FollowRestriction("I", "b"); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := gob.NewEncoder(&b).Encode(g); err != nil {
+		t.Fatal(err)
+	}
+	var h *cfg.CFG
+	if err := gob.NewDecoder(&b).Decode(&h); err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "a", accepted: true},
+		{input: "aaa", accepted: false},
+		{input: "aa", accepted: false},
+		{input: "ab", accepted: false},
+	} {
+		if _, ok := h.Evaluate(test.input); ok != test.accepted {
+			t.Errorf("%q: expected %v, got %v", test.input, test.accepted, ok)
+		}
+	}
+}
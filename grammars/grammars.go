@@ -0,0 +1,111 @@
+// Package grammars provides representative grammars, together with generators of accepted inputs of increasing size,
+// for benchmarks and tests of the engines of the cfg package.
+package grammars
+
+import (
+	"github.com/0x51-dev/cfg"
+	"strings"
+)
+
+// json is the grammar of JSON (RFC 8259) in the EBNF notation of the W3C, restricted to ASCII strings.
+const json = `
+	JSON      ::= Element
+	Element   ::= WS Value WS
+	Value     ::= Object | Array | String | Number | 'true' | 'false' | 'null'
+	Object    ::= '{' WS '}' | '{' Member ( ',' Member )* '}'
+	Member    ::= WS String WS ':' Element
+	Array     ::= '[' WS ']' | '[' Element ( ',' Element )* ']'
+	String    ::= '"' Character* '"'
+	Character ::= [a-zA-Z0-9 ] | '\' [\"/bfnrt]
+	Number    ::= '-'? Integer ( '.' [0-9]+ )?
+	Integer   ::= '0' | [1-9] [0-9]*
+	WS        ::= [#x20#x9#xA#xD]*
+`
+
+// Dyck returns the grammar of balanced parentheses and brackets: `S → (S)S | [S]S | ε`.
+func Dyck() *cfg.CFG {
+	return must(cfg.Parse("S → (S)S | [S]S | ε\n"))
+}
+
+// DyckInput returns a string of balanced parentheses and brackets of at least n bytes.
+func DyckInput(n int) string {
+	return repeat("([()])[]", n)
+}
+
+// Expression returns an unambiguous grammar of arithmetic expressions over x, with the usual precedence of + and *.
+// The rules are right-recursive, so they can be evaluated top-down.
+func Expression() *cfg.CFG {
+	var (
+		e, t, f = cfg.Variable("E"), cfg.Variable("T"), cfg.Variable("F")
+		rules   cfg.R
+	)
+	rules = append(rules, cfg.Alt(e, cfg.Betas(t, "+", e), cfg.Betas(t))...)
+	rules = append(rules, cfg.Alt(t, cfg.Betas(f, "*", t), cfg.Betas(f))...)
+	rules = append(rules, cfg.Alt(f, cfg.Betas("(", e, ")"), cfg.Betas("x"))...)
+	return must(cfg.New(cfg.V{e, t, f}, cfg.Alphabet{"+", "*", "(", ")", "x"}, rules, e))
+}
+
+// ExpressionInput returns an expression of at least n bytes.
+func ExpressionInput(n int) string {
+	return repeat("x*(x+x)+", n) + "x"
+}
+
+// JSON returns the grammar of JSON, imported from its W3C EBNF definition.
+func JSON() *cfg.CFG {
+	return must(cfg.ParseW3CEBNF(json))
+}
+
+// JSONInput returns a JSON array of at least n bytes.
+func JSONInput(n int) string {
+	return "[" + strings.TrimSuffix(repeat(`{"a": -1.5, "b": [true, null]},`, n), ",") + "]"
+}
+
+// Palindrome returns the grammar of palindromes over a and b: `S → aSa | bSb | a | b | ε`.
+func Palindrome() *cfg.CFG {
+	return must(cfg.Parse("S → aSa | bSb | a | b | ε\n"))
+}
+
+// PalindromeInput returns a palindrome of at least n bytes.
+func PalindromeInput(n int) string {
+	half := repeat("ab", n/2)
+	var reversed strings.Builder
+	for i := len(half) - 1; 0 <= i; i-- {
+		reversed.WriteByte(half[i])
+	}
+	return half + "a" + reversed.String()
+}
+
+func must(g *cfg.CFG, err error) *cfg.CFG {
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// repeat repeats the unit until the result is at least n bytes long, the unit is used at least once.
+func repeat(unit string, n int) string {
+	count := (n + len(unit) - 1) / len(unit)
+	if count < 1 {
+		count = 1
+	}
+	return strings.Repeat(unit, count)
+}
+
+// Grammar is a named grammar together with a generator of accepted inputs.
+type Grammar struct {
+	Name string
+	// New returns a new instance of the grammar, so that its limits can be changed independently.
+	New func() *cfg.CFG
+	// Input returns an accepted input of at least n bytes.
+	Input func(n int) string
+}
+
+// All returns all grammars of the package.
+func All() []Grammar {
+	return []Grammar{
+		{Name: "Dyck", New: Dyck, Input: DyckInput},
+		{Name: "Expression", New: Expression, Input: ExpressionInput},
+		{Name: "JSON", New: JSON, Input: JSONInput},
+		{Name: "Palindrome", New: Palindrome, Input: PalindromeInput},
+	}
+}
@@ -0,0 +1,31 @@
+package grammars_test
+
+import (
+	"github.com/0x51-dev/cfg/grammars"
+	"testing"
+)
+
+func TestAll(t *testing.T) {
+	for _, grammar := range grammars.All() {
+		g := grammar.New()
+		c, err := g.CYK()
+		if err != nil {
+			t.Fatalf("%s: %v", grammar.Name, err)
+		}
+		for _, n := range []int{1, 16, 64} {
+			s := grammar.Input(n)
+			if len(s) < n {
+				t.Errorf("%s: expected an input of at least %d bytes, got %q", grammar.Name, n, s)
+			}
+			if _, ok := g.Evaluate(s); !ok {
+				t.Errorf("%s: expected %q to be accepted by Evaluate", grammar.Name, s)
+			}
+			if !c.Recognize(s) {
+				t.Errorf("%s: expected %q to be accepted by CYK", grammar.Name, s)
+			}
+			if c.Recognize(s[1:]) {
+				t.Errorf("%s: expected %q to be rejected by CYK", grammar.Name, s[1:])
+			}
+		}
+	}
+}
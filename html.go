@@ -0,0 +1,108 @@
+package cfg
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+)
+
+var htmlTemplate = template.Must(template.New("derivation").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Derivation of {{.Input}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+ul { list-style: none; padding-left: 1.5em; border-left: 1px dotted #999; }
+summary { cursor: pointer; }
+.rule { color: #777; margin-left: 1em; font-size: 0.9em; }
+.leaf { font-family: monospace; font-weight: bold; }
+.pending > summary { color: #bbb; }
+#form { font-family: monospace; font-size: 1.4em; }
+</style>
+</head>
+<body>
+<h1>Derivation of <code>{{.Input}}</code></h1>
+<p>
+<input type="range" id="step" min="0" max="{{.Max}}" value="{{.Max}}">
+<span id="label"></span>
+</p>
+<p id="form"></p>
+<h2>Derivation tree</h2>
+{{template "node" .Tree}}
+<script>
+const steps = {{.Steps}};
+const slider = document.getElementById("step");
+function update() {
+	const step = Number(slider.value);
+	document.getElementById("label").textContent = "step " + step + " of " + (steps.length - 1);
+	document.getElementById("form").textContent = steps[step];
+	for (const node of document.querySelectorAll("details[data-step]")) {
+		node.classList.toggle("pending", step <= Number(node.dataset.step));
+	}
+}
+slider.addEventListener("input", update);
+update();
+</script>
+</body>
+</html>
+{{define "node"}}{{if .Children}}<details open data-step="{{.Step}}"><summary>{{.Symbol}}<span class="rule">{{.Production}}</span></summary>
+<ul>{{range .Children}}
+<li>{{template "node" .}}</li>{{end}}
+</ul></details>{{else}}<span class="leaf">{{.Symbol}}</span>{{end}}{{end}}
+`))
+
+// htmlNode is a tree node annotated with the derivation step at which it gets expanded.
+type htmlNode struct {
+	Symbol     string
+	Production string
+	Step       int
+	Children   []htmlNode
+}
+
+func newHTMLNode(t *Tree, step *int) htmlNode {
+	n := htmlNode{Symbol: t.Symbol.String()}
+	if t.Production != nil {
+		n.Production = t.Production.String()
+		n.Step = *step
+		*step++
+	}
+	for _, c := range t.Children {
+		n.Children = append(n.Children, newHTMLNode(c, step))
+	}
+	return n
+}
+
+// HTML renders the derivation as a self-contained HTML page, containing a collapsible derivation tree and a slider to
+// step through the sentential forms.
+func (p Path) HTML() (string, error) {
+	t, err := p.Tree()
+	if err != nil {
+		return "", err
+	}
+	return t.HTML()
+}
+
+// HTML renders the tree as a self-contained HTML page, see Path.HTML.
+func (t *Tree) HTML() (string, error) {
+	steps := t.Path().steps()
+	if len(steps) == 0 {
+		return "", fmt.Errorf("tree %v has no derivation", t)
+	}
+	var step int
+	var s strings.Builder
+	if err := htmlTemplate.Execute(&s, struct {
+		Input string
+		Max   int
+		Steps []string
+		Tree  htmlNode
+	}{
+		Input: steps[len(steps)-1],
+		Max:   len(steps) - 1,
+		Steps: steps,
+		Tree:  newHTMLNode(t, &step),
+	}); err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
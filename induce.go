@@ -0,0 +1,180 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Induce proposes a grammar for the examples, for corpora without a grammar. Every rune becomes a terminal. Runs of a
+// repeated symbol are generalized to one or more repetitions of it, digrams that occur more than once are replaced by
+// a variable until every digram is unique (as in Re-Pair, the offline variant of Sequitur), and the runs of the
+// resulting variables are generalized again. The start variable S (S0 if S is a terminal) has a production for every
+// distinct example, so the language contains all examples.
+func Induce(examples []string, opts InduceOptions) (*CFG, error) {
+	g, _, err := induce(examples, opts)
+	return g, err
+}
+
+// induce returns the induced grammar, together with the variables it introduced.
+func induce(examples []string, opts InduceOptions) (*CFG, *induction, error) {
+	if len(examples) == 0 {
+		return nil, nil, errors.New("no examples")
+	}
+	ind := &induction{
+		opts:      opts,
+		expansion: make(map[Beta]string),
+		repeated:  make(map[Beta]Variable),
+		literal:   make(map[string]bool),
+	}
+	for _, s := range opts.Literal {
+		ind.literal[s] = true
+	}
+	var alphabet Alphabet
+	seqs := make([][]Beta, len(examples))
+	for i, s := range examples {
+		for _, r := range s {
+			t := Terminal(string(r))
+			if _, ok := ind.expansion[t]; !ok {
+				ind.expansion[t] = string(r)
+				alphabet = append(alphabet, t)
+			}
+			seqs[i] = append(seqs[i], t)
+		}
+	}
+	seqs = ind.pair(ind.generalize(seqs))
+	seqs = ind.generalize(seqs)
+
+	// The fresh variables have more than one rune, only the start variable can collide with a terminal.
+	start := Variable("S")
+	if _, ok := ind.expansion[Terminal(start)]; ok {
+		start = "S0"
+	}
+	var rules R
+	unique := make(map[string]bool)
+	for _, seq := range seqs {
+		if len(seq) == 0 {
+			seq = []Beta{Epsilon}
+		}
+		p := NewProduction(start, seq)
+		if !unique[p.key()] {
+			unique[p.key()] = true
+			rules = append(rules, p)
+		}
+	}
+	g, err := New(append(V{start}, ind.variables...), alphabet, append(rules, ind.rules...), start)
+	return g, ind, err
+}
+
+// InduceOptions configures Induce.
+type InduceOptions struct {
+	// Repetitions is the minimal length of a run of a symbol that is generalized to one or more repetitions, less than
+	// two disables the generalization.
+	Repetitions int
+	// Literal are the strings of which runs are never generalized, e.g. "a" keeps `aa` from becoming `a+`.
+	Literal []string
+}
+
+// induction are the variables introduced by Induce.
+type induction struct {
+	opts      InduceOptions
+	variables V
+	rules     R
+	// expansion is the string derived by every symbol.
+	expansion map[Beta]string
+	// repeated is the variable that derives the runs of a symbol.
+	repeated map[Beta]Variable
+	literal  map[string]bool
+	pairs    int
+}
+
+// generalize replaces the runs of a symbol by a variable that derives one or more repetitions of it.
+func (ind *induction) generalize(seqs [][]Beta) [][]Beta {
+	if ind.opts.Repetitions < 2 {
+		return seqs
+	}
+	for i, seq := range seqs {
+		var generalized []Beta
+		for j := 0; j < len(seq); {
+			k := j + 1
+			for k < len(seq) && seq[k] == seq[j] {
+				k++
+			}
+			if k-j < ind.opts.Repetitions || ind.literal[ind.expansion[seq[j]]] {
+				generalized = append(generalized, seq[j:k]...)
+			} else {
+				generalized = append(generalized, ind.repetition(seq[j]))
+			}
+			j = k
+		}
+		seqs[i] = generalized
+	}
+	return seqs
+}
+
+// pair replaces the most frequent digram by a fresh variable, as long as a digram occurs more than once.
+func (ind *induction) pair(seqs [][]Beta) [][]Beta {
+	for {
+		count := make(map[[2]Beta]int)
+		var order [][2]Beta
+		for _, seq := range seqs {
+			last := make(map[[2]Beta]int)
+			for i := 0; i+1 < len(seq); i++ {
+				d := [2]Beta{seq[i], seq[i+1]}
+				// Overlapping occurrences, e.g. in `aaa`, only count once.
+				if j, ok := last[d]; ok && j == i-1 {
+					continue
+				}
+				last[d] = i
+				if count[d] == 0 {
+					order = append(order, d)
+				}
+				count[d]++
+			}
+		}
+		var best [2]Beta
+		for _, d := range order {
+			if count[best] < count[d] {
+				best = d
+			}
+		}
+		if count[best] < 2 {
+			return seqs
+		}
+		v := Variable(fmt.Sprintf("N%d", ind.pairs))
+		ind.pairs++
+		ind.add(v, best[0], best[1])
+		ind.expansion[v] = ind.expansion[best[0]] + ind.expansion[best[1]]
+		for i, seq := range seqs {
+			var paired []Beta
+			for j := 0; j < len(seq); j++ {
+				if j+1 < len(seq) && seq[j] == best[0] && seq[j+1] == best[1] {
+					paired = append(paired, v)
+					j++
+				} else {
+					paired = append(paired, seq[j])
+				}
+			}
+			seqs[i] = paired
+		}
+	}
+}
+
+// repetition returns the variable that derives one or more repetitions of the symbol.
+func (ind *induction) repetition(b Beta) Variable {
+	if v, ok := ind.repeated[b]; ok {
+		return v
+	}
+	v := Variable(fmt.Sprintf("R%d", len(ind.repeated)))
+	ind.repeated[b] = v
+	ind.add(v, b, v)
+	ind.rules = append(ind.rules, NewProduction(v, []Beta{b}))
+	ind.expansion[v] = ind.expansion[b]
+	return v
+}
+
+func (ind *induction) add(v Variable, b ...Beta) {
+	if len(ind.variables) == 0 || ind.variables[len(ind.variables)-1] != v {
+		ind.variables = append(ind.variables, v)
+	}
+	ind.rules = append(ind.rules, NewProduction(v, b))
+}
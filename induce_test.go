@@ -0,0 +1,62 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleInduce() {
+	g, _ := cfg.Induce([]string{"ab", "aab", "aaab", "abcabc"}, cfg.InduceOptions{Repetitions: 2})
+	fmt.Println(g.Rules)
+	// Output:
+	// S → N0, S → N1, S → R1, R0 → aR0, R0 → a, N0 → ab, N1 → R0b, N2 → N0c, R1 → N2R1, R1 → N2
+}
+
+func TestInduce(t *testing.T) {
+	for _, test := range []struct {
+		examples []string
+		opts     cfg.InduceOptions
+		accepted []string
+		rejected []string
+	}{
+		{
+			examples: []string{"ab", "aab"},
+			opts:     cfg.InduceOptions{Repetitions: 2},
+			accepted: []string{"aaaab"},
+			rejected: []string{"b", "abb"},
+		},
+		{
+			examples: []string{"ab", "aab"},
+			opts:     cfg.InduceOptions{Repetitions: 2, Literal: []string{"a"}},
+			rejected: []string{"aaab"},
+		},
+		{
+			examples: []string{"", "xyxy", "xyz", "Sx"},
+			rejected: []string{"xy", "x"},
+		},
+		{
+			examples: []string{"(1+2)", "(1+1+2)", "((1))"},
+			opts:     cfg.InduceOptions{Repetitions: 2},
+			accepted: []string{"(((1)))"},
+		},
+	} {
+		g, err := cfg.Induce(test.examples, test.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range append(test.examples, test.accepted...) {
+			if _, ok := g.EvaluateShortest(s); !ok {
+				t.Errorf("%q: expected %q to be accepted by %v", test.examples, s, g.Rules)
+			}
+		}
+		for _, s := range test.rejected {
+			if _, ok := g.EvaluateShortest(s); ok {
+				t.Errorf("%q: expected %q to be rejected by %v", test.examples, s, g.Rules)
+			}
+		}
+	}
+	if _, err := cfg.Induce(nil, cfg.InduceOptions{}); err == nil {
+		t.Error("expected an error without examples")
+	}
+}
@@ -0,0 +1,112 @@
+package cfg
+
+import (
+	"container/heap"
+	"math"
+)
+
+// KBest returns the k most probable derivations of the string, most probable first, or fewer if the string has less
+// derivations. The spans of the string are first scored by the Viterbi algorithm (see EvaluateShortest), the
+// derivations are then enumerated lazily in the style of Huang and Chiang: a derivation is expanded from its leftmost
+// open span, and the Viterbi scores of the open spans are an exact estimate of the remaining probability, so the
+// derivations are completed in the order of their probability. Unlike Viterbi it does not depend on the limits of the
+// grammar.
+func (p *PCFG) KBest(s string, k int) []Derivation {
+	t := p.symbols
+	s = p.input(s)
+	e := p.newEvaluation(s)
+	cost := func(rule int) float64 {
+		return -math.Log(p.Probabilities[rule])
+	}
+	search := p.spanSearch(s, cost, nil)
+	inside := func(items []chartItem) float64 {
+		var c float64
+		for _, x := range items {
+			c += search.best[x]
+		}
+		return c
+	}
+	rules := make([][]int, len(t.variables))
+	for i, r := range t.rules {
+		rules[r.a] = append(rules[r.a], i)
+	}
+
+	goal := chartItem{variable: t.variable[p.StartVariable], rule: -1, i: 0, j: len(s)}
+	if !search.final[goal] {
+		return nil
+	}
+	var derivations []Derivation
+	queue := &kbestQueue{{open: []chartItem{goal}, estimate: search.best[goal]}}
+	for queue.Len() != 0 && len(derivations) < k {
+		d := heap.Pop(queue).(kbestState)
+		if len(d.open) == 0 {
+			derivations = append(derivations, Derivation{Path: d.path, Probability: math.Exp(-d.cost)})
+			continue
+		}
+		x, rest := d.open[0], d.open[1:]
+		expand := func(c float64, path Path, items ...chartItem) {
+			for _, y := range items {
+				if !search.final[y] {
+					return
+				}
+			}
+			open := append(append(make([]chartItem, 0, len(items)+len(rest)), items...), rest...)
+			heap.Push(queue, kbestState{cost: c, estimate: c + inside(open), open: open, path: path})
+		}
+		if 0 <= x.variable {
+			for _, r := range rules[x.variable] {
+				// The paths of the states share their prefix, so the path is copied on append.
+				path := append(d.path[:len(d.path):len(d.path)], p.Rules[r])
+				expand(d.cost+cost(r), path, chartItem{variable: -1, rule: r, dot: len(t.rules[r].b), i: x.i, j: x.j})
+			}
+			continue
+		}
+		if x.dot == 0 {
+			expand(d.cost, d.path)
+			continue
+		}
+		prev := chartItem{variable: -1, rule: x.rule, dot: x.dot - 1, i: x.i}
+		if b := t.rules[x.rule].b[x.dot-1]; b < 0 {
+			// A terminal may match spans of different lengths, e.g. a category, so every start is tried.
+			for m := x.i; m < x.j; m++ {
+				if n, ok := p.match(s[m:], t.terminals[-b-1], e); ok && m+n == x.j {
+					prev.j = m
+					expand(d.cost, d.path, prev)
+				}
+			}
+		} else {
+			for m := x.i; m <= x.j; m++ {
+				prev.j = m
+				expand(d.cost, d.path, prev, chartItem{variable: b, rule: -1, i: m, j: x.j})
+			}
+		}
+	}
+	return derivations
+}
+
+// Derivation is a derivation together with its probability.
+type Derivation struct {
+	Path        Path
+	Probability float64
+}
+
+// kbestQueue is the priority queue of the partial derivations of KBest, ordered by their estimated cost.
+type kbestQueue []kbestState
+
+func (q kbestQueue) Len() int           { return len(q) }
+func (q kbestQueue) Less(i, j int) bool { return q[i].estimate < q[j].estimate }
+func (q *kbestQueue) Pop() any {
+	s := (*q)[len(*q)-1]
+	*q = (*q)[:len(*q)-1]
+	return s
+}
+func (q *kbestQueue) Push(x any)   { *q = append(*q, x.(kbestState)) }
+func (q kbestQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// kbestState is a partial derivation: the productions of the expanded variables, and the open spans from left to right.
+// The cost is the negative log probability of the productions, the estimate adds the Viterbi costs of the open spans.
+type kbestState struct {
+	cost, estimate float64
+	open           []chartItem
+	path           Path
+}
@@ -0,0 +1,124 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"math"
+	"sort"
+	"testing"
+)
+
+func ExamplePCFG_KBest() {
+	g, _ := cfg.Parse("S → aB | Ab | AB\nA → a\nB → b\n")
+	p, _ := cfg.NewPCFG(g, []float64{0.2, 0.5, 0.3, 1, 1})
+	for _, d := range p.KBest("ab", 2) {
+		fmt.Printf("%v %.1f\n", d.Path, d.Probability)
+	}
+	// Output:
+	// [ S → Ab, A → a ] 0.5
+	// [ S → AB, A → a, B → b ] 0.3
+}
+
+func TestPCFG_KBest(t *testing.T) {
+	g, err := cfg.New(
+		cfg.V{"E"}, cfg.Alphabet{"+", "*", "a"},
+		cfg.R{
+			cfg.NewProduction(cfg.Variable("E"), []cfg.Beta{cfg.Variable("E"), cfg.Terminal("+"), cfg.Variable("E")}),
+			cfg.NewProduction(cfg.Variable("E"), []cfg.Beta{cfg.Variable("E"), cfg.Terminal("*"), cfg.Variable("E")}),
+			cfg.NewProduction(cfg.Variable("E"), []cfg.Beta{cfg.Terminal("a")}),
+		},
+		"E",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.NewPCFG(g, []float64{0.3, 0.1, 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := "a+a*a+a"
+	var expected []float64
+	for _, path := range p.EvaluateAll(s) {
+		expected = append(expected, p.Probability(path))
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(expected)))
+	if len(expected) != 5 {
+		t.Fatalf("expected 5 derivations, got %d", len(expected))
+	}
+
+	derivations := p.KBest(s, 10)
+	if len(derivations) != len(expected) {
+		t.Fatalf("expected %d derivations, got %d", len(expected), len(derivations))
+	}
+	seen := make(map[string]bool)
+	for i, d := range derivations {
+		if math.Abs(d.Probability-expected[i]) > 1e-12 || math.Abs(d.Probability-p.Probability(d.Path)) > 1e-12 {
+			t.Errorf("%d: expected probability %g, got %g", i, expected[i], d.Probability)
+		}
+		if seen[d.Path.String()] {
+			t.Errorf("duplicate derivation %v", d.Path)
+		}
+		seen[d.Path.String()] = true
+	}
+	if best, prob, _ := p.Viterbi(s); math.Abs(prob-derivations[0].Probability) > 1e-12 {
+		t.Errorf("expected the Viterbi derivation %v first, got %v", best, derivations[0].Path)
+	}
+	if d := p.KBest("a+", 1); len(d) != 0 {
+		t.Errorf("expected no derivations, got %v", d)
+	}
+
+	// Unit cycles have infinitely many derivations.
+	cycle, err := cfg.Parse("S → A | a\nA → S\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := cfg.NewPCFG(cycle, []float64{0.5, 0.5, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := q.KBest("a", 3); len(d) != 3 || len(d[2].Path) != 5 {
+		t.Errorf("expected three derivations of increasing length, got %v", d)
+	}
+}
+
+func TestPCFG_KBest_category(t *testing.T) {
+	g, err := cfg.Parse("S → \\p{L} S | \\p{L}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.NewPCFG(g, []float64{0.5, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := p.Viterbi("ab"); !ok {
+		t.Fatal("expected a Viterbi derivation of ab")
+	}
+	if d := p.KBest("ab", 2); len(d) != 1 || d[0].Probability != 0.25 {
+		t.Errorf("expected a single derivation of ab, got %v", d)
+	}
+}
+
+func TestPCFG_KBest_fields(t *testing.T) {
+	g, err := cfg.New(
+		cfg.V{"S"}, cfg.Alphabet{"if", "x"},
+		cfg.R{
+			cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Terminal("if"), cfg.Variable("S")}),
+			cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Terminal("x")}),
+		},
+		"S",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetFields(true)
+	p, err := cfg.NewPCFG(g, []float64{0.5, 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := p.KBest("if  if x", 1); len(d) != 1 || len(d[0].Path) != 3 {
+		t.Errorf("expected a derivation of if if x, got %v", d)
+	}
+	if d := p.KBest("ifif x", 1); len(d) != 0 {
+		t.Errorf("expected no derivation of ifif x, got %v", d)
+	}
+}
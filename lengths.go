@@ -0,0 +1,106 @@
+package cfg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LengthSet returns for every length from 0 to n whether the language contains a string of that many terminals, the set
+// is empty for a negative n. The lengths of the strings of the variables are combined bottom-up until nothing changes.
+// A round takes O(|G| n²), and every round but the last adds a length to a variable, so there are at most |V| (n+1) + 1
+// rounds.
+func (g *CFG) LengthSet(n int) LengthSet {
+	if n < 0 {
+		return LengthSet{}
+	}
+	t := g.symbols
+	lengths := make([]bitset, len(t.variables))
+	for i := range lengths {
+		lengths[i] = newBitset(n + 1)
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, r := range t.rules {
+			sum := newBitset(n + 1)
+			sum.set(0)
+			for _, s := range r.b {
+				if s < 0 {
+					sum = shift(sum, n)
+				} else {
+					sum = sumset(sum, lengths[s], n)
+				}
+			}
+			if lengths[r.a].union(sum) {
+				changed = true
+			}
+		}
+	}
+	set := make(LengthSet, n+1)
+	start := lengths[t.variable[g.StartVariable]]
+	for i := range set {
+		set[i] = start.has(i)
+	}
+	return set
+}
+
+// shift returns the lengths plus one, up to n.
+func shift(a bitset, n int) bitset {
+	b := newBitset(n + 1)
+	for i := 0; i < n; i++ {
+		if a.has(i) {
+			b.set(i + 1)
+		}
+	}
+	return b
+}
+
+// sumset returns the sums of the lengths of both sets, up to n.
+func sumset(a, b bitset, n int) bitset {
+	c := newBitset(n + 1)
+	for i := 0; i <= n; i++ {
+		if !a.has(i) {
+			continue
+		}
+		for j := 0; i+j <= n; j++ {
+			if b.has(j) {
+				c.set(i + j)
+			}
+		}
+	}
+	return c
+}
+
+// LengthSet are the lengths of the strings of a language, the length i is contained if the i-th element is true.
+type LengthSet []bool
+
+// Period returns the smallest period p and the smallest start such that the set repeats itself with period p from the
+// start on, i.e. whether a length is contained only depends on its remainder modulo p. The length set of every
+// context-free language is ultimately periodic, but since the set is finite the period is only reported if it is
+// observed at least twice.
+func (l LengthSet) Period() (start, period int, ok bool) {
+	n := len(l) - 1
+	for p := 1; 2*p <= n+1; p++ {
+		start = 0
+		for i := n - p; 0 <= i; i-- {
+			if l[i] != l[i+p] {
+				start = i + 1
+				break
+			}
+		}
+		if 2*p <= n+1-start {
+			return start, p, true
+		}
+	}
+	return 0, 0, false
+}
+
+// String returns the contained lengths, e.g. `{0, 2, 4}`.
+func (l LengthSet) String() string {
+	var s []string
+	for i, ok := range l {
+		if ok {
+			s = append(s, strconv.Itoa(i))
+		}
+	}
+	return "{" + strings.Join(s, ", ") + "}"
+}
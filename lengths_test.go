@@ -0,0 +1,53 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_LengthSet() {
+	// aⁿbⁿ
+	g, _ := cfg.Parse("S → aSb | ε\n")
+	lengths := g.LengthSet(10)
+	fmt.Println(lengths)
+	fmt.Println(lengths.Period())
+	// Output:
+	// {0, 2, 4, 6, 8, 10}
+	// 0 2 true
+}
+
+func TestLengthSet_Period(t *testing.T) {
+	for _, test := range []struct {
+		grammar       string
+		start, period int
+		ok            bool
+	}{
+		{"S → aS | a\n", 1, 1, true},
+		{"S → aaaS | aa\n", 0, 3, true},
+		// A finite language, the set is empty from 3 on.
+		{"S → ab | b\n", 3, 1, true},
+		// Only the length 12, the set ends before a period is observed twice.
+		{"S → AAAAAA\nA → aa\n", 0, 0, false},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lengths := g.LengthSet(12)
+		start, period, ok := lengths.Period()
+		if start != test.start || period != test.period || ok != test.ok {
+			t.Errorf("%s: expected %d %d %v, got %d %d %v (%v)", test.grammar, test.start, test.period, test.ok, start, period, ok, lengths)
+		}
+	}
+}
+
+func TestCFG_LengthSet_negative(t *testing.T) {
+	lengths := g.LengthSet(-1)
+	if len(lengths) != 0 {
+		t.Errorf("expected an empty set, got %v", lengths)
+	}
+	if _, _, ok := lengths.Period(); ok {
+		t.Error("expected no period of an empty set")
+	}
+}
@@ -0,0 +1,72 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LLConflict is a cell of the predictive table that contains more than one production.
+type LLConflict struct {
+	Variable    Variable
+	Terminal    Terminal
+	Productions []Production
+}
+
+func (c LLConflict) String() string {
+	var ps []string
+	for _, p := range c.Productions {
+		ps = append(ps, p.describe())
+	}
+	return fmt.Sprintf("conflict on (%v, %v): %s", c.Variable, c.Terminal, strings.Join(ps, ", "))
+}
+
+// PredictiveTable is the LL(1) parsing table. The columns are the terminals of the alphabet, followed by EndOfInput.
+type PredictiveTable struct {
+	Variables V
+	Terminals Alphabet
+	Cells     map[Variable]map[Terminal][]Production
+}
+
+// PredictiveTable computes the LL(1) parsing table from the FIRST and FOLLOW sets of the grammar.
+func (g *CFG) PredictiveTable() *PredictiveTable {
+	if g.tables != nil {
+		return g.tables.Predictive.clone()
+	}
+	first := g.first()
+	nullable := g.nullable()
+	follow := g.Follow()
+	t := &PredictiveTable{
+		Variables: g.Variables,
+		Terminals: append(append(Alphabet{}, g.Alphabet...), EndOfInput),
+		Cells:     make(map[Variable]map[Terminal][]Production),
+	}
+	for _, v := range g.Variables {
+		t.Cells[v] = make(map[Terminal][]Production)
+	}
+	for _, rule := range g.Rules {
+		a := rule.A.(Variable)
+		ts, empty := g.firstOf(rule.B, first, nullable)
+		for _, terminal := range sortedTerminals(ts) {
+			t.Cells[a][terminal] = append(t.Cells[a][terminal], rule)
+		}
+		if empty {
+			for _, terminal := range follow[a] {
+				t.Cells[a][terminal] = append(t.Cells[a][terminal], rule)
+			}
+		}
+	}
+	return t
+}
+
+// Conflicts returns all cells with more than one production, in row-major order.
+func (t *PredictiveTable) Conflicts() []LLConflict {
+	var conflicts []LLConflict
+	for _, v := range t.Variables {
+		for _, terminal := range t.Terminals {
+			if ps := t.Cells[v][terminal]; 1 < len(ps) {
+				conflicts = append(conflicts, LLConflict{Variable: v, Terminal: terminal, Productions: ps})
+			}
+		}
+	}
+	return conflicts
+}
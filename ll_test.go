@@ -0,0 +1,26 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+)
+
+func ExamplePredictiveTable_DOT() {
+	g, _ := cfg.Parse("S → aS | ε\n")
+	fmt.Print(g.PredictiveTable().DOT())
+	// Output:
+	// digraph LL1 {
+	// 	node [shape=plaintext, fontname=monospace];
+	// 	table [label=<<TABLE BORDER="0" CELLBORDER="1" CELLSPACING="0">
+	// 		<TR><TD></TD><TD><B>a</B></TD><TD><B>$</B></TD></TR>
+	// 		<TR><TD><B>S</B></TD><TD>S → aS</TD><TD>S → ε</TD></TR>
+	// 	</TABLE>>];
+	// }
+}
+
+func ExamplePredictiveTable_Conflicts() {
+	g, _ := cfg.Parse("S → aS | a\n")
+	fmt.Println(g.PredictiveTable().Conflicts())
+	// Output:
+	// [conflict on (S, a): S → aS (line 1), S → a (line 1)]
+}
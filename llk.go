@@ -0,0 +1,271 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// concatK concatenates every string of a with every string of b, truncated to k terminals.
+func concatK(a, b kSet, k int) kSet {
+	c := make(kSet)
+	for _, x := range a {
+		if k <= len(x) {
+			c.add(x)
+			continue
+		}
+		for _, y := range b {
+			xy := append(append([]Terminal{}, x...), y...)
+			if k < len(xy) {
+				xy = xy[:k]
+			}
+			c.add(xy)
+		}
+	}
+	return c
+}
+
+// LLkConflict is a pair of productions of a variable that can not be distinguished by the next k terminals. A
+// lookahead shorter than k terminals ends with EndOfInput.
+type LLkConflict struct {
+	Variable    Variable
+	Lookahead   []Terminal
+	Productions [2]Production
+}
+
+func (c LLkConflict) String() string {
+	return fmt.Sprintf(
+		"conflict on (%v, %s): %s, %s",
+		c.Variable, join(c.Lookahead, ""), c.Productions[0].describe(), c.Productions[1].describe(),
+	)
+}
+
+// FirstK computes the FIRST_k set of every variable: the prefixes of at most k terminals of the strings it derives,
+// in lexicographic order. A prefix shorter than k terminals is a whole derived string, the empty one is ε.
+func (g *CFG) FirstK(k int) map[Variable][][]Terminal {
+	m := make(map[Variable][][]Terminal)
+	for v, s := range g.firstK(k) {
+		m[v] = s.sorted()
+	}
+	return m
+}
+
+// FollowK computes the FOLLOW_k set of every variable: the prefixes of at most k terminals that can follow it, in
+// lexicographic order. A prefix shorter than k terminals is followed by the end of the input.
+func (g *CFG) FollowK(k int) map[Variable][][]Terminal {
+	m := make(map[Variable][][]Terminal)
+	for v, s := range g.followK(k, g.firstK(k)) {
+		m[v] = s.sorted()
+	}
+	return m
+}
+
+// IsLLk checks whether the grammar is LL(k): whether in every left sentential form the next k terminals determine the
+// production of the leftmost variable. The contexts of the variables are tracked as the sets of their possible
+// lookaheads (local FOLLOW_k sets), so the check is exact, but its cost grows quickly with k.
+func (g *CFG) IsLLk(k int) (bool, []LLkConflict) {
+	first := g.firstK(k)
+	type context struct {
+		variable Variable
+		follow   kSet
+	}
+	seen := make(map[string]bool)
+	queue := []context{{g.StartVariable, kSet{"": nil}}}
+	seen[string(g.StartVariable)+"\x01"+queue[0].follow.key()] = true
+	var conflicts []LLkConflict
+	reported := make(map[[2]int]bool)
+	indices := g.ruleIndices()
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		rules := g.RulesFor(c.variable)
+		for _, rule := range rules {
+			for i, b := range rule.B {
+				if v, ok := b.(Variable); ok {
+					next := context{v, concatK(g.firstKOf(rule.B[i+1:], first, k), c.follow, k)}
+					if key := string(v) + "\x01" + next.follow.key(); !seen[key] {
+						seen[key] = true
+						queue = append(queue, next)
+					}
+				}
+			}
+		}
+		for _, conflict := range g.llkConflicts(c.variable, c.follow, first, k) {
+			pair := [2]int{indices[conflict.Productions[0].key()], indices[conflict.Productions[1].key()]}
+			if !reported[pair] {
+				reported[pair] = true
+				conflicts = append(conflicts, conflict)
+			}
+		}
+	}
+	return len(conflicts) == 0, conflicts
+}
+
+// IsStrongLLk checks whether the grammar is strong LL(k): whether the next k terminals determine the production of a
+// variable, regardless of the context of the variable. Every strong LL(k) grammar is LL(k), for k = 1 both are the
+// same.
+func (g *CFG) IsStrongLLk(k int) (bool, []LLkConflict) {
+	first := g.firstK(k)
+	follow := g.followK(k, first)
+	var conflicts []LLkConflict
+	for _, v := range g.Variables {
+		conflicts = append(conflicts, g.llkConflicts(v, follow[v], first, k)...)
+	}
+	return len(conflicts) == 0, conflicts
+}
+
+// firstK computes the FIRST_k sets of the variables: the prefixes of at most k terminals of the derived strings.
+func (g *CFG) firstK(k int) map[Variable]kSet {
+	first := make(map[Variable]kSet)
+	for _, v := range g.Variables {
+		first[v] = make(kSet)
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			for _, x := range g.firstKOf(rule.B, first, k) {
+				if first[a].add(x) {
+					changed = true
+				}
+			}
+		}
+	}
+	return first
+}
+
+func (g *CFG) firstKOf(beta []Beta, first map[Variable]kSet, k int) kSet {
+	s := kSet{"": nil}
+	for _, b := range beta {
+		switch b := b.(type) {
+		case Terminal:
+			s = concatK(s, kSet{string(b): []Terminal{b}}, k)
+		case Category:
+			// A category is the terminal `\p{L}` in sets of terminals, see symbolTable.name.
+			s = concatK(s, kSet{b.String(): []Terminal{Terminal(b.String())}}, k)
+		case Variable:
+			s = concatK(s, first[b], k)
+		}
+	}
+	return s
+}
+
+// followK computes the FOLLOW_k sets of the variables: the prefixes of at most k terminals that can follow the
+// variable. A prefix shorter than k terminals is followed by the end of the input.
+func (g *CFG) followK(k int, first map[Variable]kSet) map[Variable]kSet {
+	follow := make(map[Variable]kSet)
+	for _, v := range g.Variables {
+		follow[v] = make(kSet)
+	}
+	follow[g.StartVariable].add(nil)
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range g.Rules {
+			a := rule.A.(Variable)
+			for i, b := range rule.B {
+				v, ok := b.(Variable)
+				if !ok {
+					continue
+				}
+				for _, x := range concatK(g.firstKOf(rule.B[i+1:], first, k), follow[a], k) {
+					if follow[v].add(x) {
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return follow
+}
+
+// llkConflicts returns the pairs of productions of the variable that share a lookahead, given the lookaheads that can
+// follow the variable.
+func (g *CFG) llkConflicts(v Variable, follow kSet, first map[Variable]kSet, k int) []LLkConflict {
+	rules := g.RulesFor(v)
+	lookaheads := make([]kSet, len(rules))
+	for i, rule := range rules {
+		lookaheads[i] = concatK(g.firstKOf(rule.B, first, k), follow, k)
+	}
+	var conflicts []LLkConflict
+	for i := range rules {
+		for j := i + 1; j < len(rules); j++ {
+			var shared []string
+			for key := range lookaheads[i] {
+				if _, ok := lookaheads[j][key]; ok {
+					shared = append(shared, key)
+				}
+			}
+			if len(shared) == 0 {
+				continue
+			}
+			sort.Strings(shared)
+			lookahead := append([]Terminal{}, lookaheads[i][shared[0]]...)
+			if len(lookahead) < k {
+				lookahead = append(lookahead, EndOfInput)
+			}
+			conflicts = append(conflicts, LLkConflict{
+				Variable:    v,
+				Lookahead:   lookahead,
+				Productions: [2]Production{rules[i], rules[j]},
+			})
+		}
+	}
+	return conflicts
+}
+
+// ruleIndices returns the index of the first rule with the given key.
+func (g *CFG) ruleIndices() map[string]int {
+	indices := make(map[string]int)
+	for i, rule := range g.Rules {
+		if _, ok := indices[rule.key()]; !ok {
+			indices[rule.key()] = i
+		}
+	}
+	return indices
+}
+
+// kSet is a set of strings of at most k terminals, keyed by their joined terminals.
+type kSet map[string][]Terminal
+
+// add adds the string to the set, returns true if it was not yet part of it.
+func (s kSet) add(ts []Terminal) bool {
+	key := s.keyOf(ts)
+	if _, ok := s[key]; ok {
+		return false
+	}
+	s[key] = ts
+	return true
+}
+
+// key returns a key that identifies the whole set.
+func (s kSet) key() string {
+	return strings.Join(s.keys(), "\x02")
+}
+
+func (s kSet) keys() []string {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s kSet) sorted() [][]Terminal {
+	ts := make([][]Terminal, 0, len(s))
+	for _, k := range s.keys() {
+		ts = append(ts, s[k])
+	}
+	return ts
+}
+
+func (kSet) keyOf(ts []Terminal) string {
+	var sb strings.Builder
+	for i, t := range ts {
+		if i != 0 {
+			sb.WriteByte(0)
+		}
+		sb.WriteString(string(t))
+	}
+	return sb.String()
+}
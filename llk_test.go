@@ -0,0 +1,52 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_IsStrongLLk() {
+	g, _ := cfg.Parse("S → aAaa | bAba\nA → b | ε\n")
+	fmt.Println(g.IsLLk(2))
+	fmt.Println(g.IsStrongLLk(2))
+	// Output:
+	// true []
+	// false [conflict on (A, ba): A → b (line 2), A → ε (line 2)]
+}
+
+func TestCFG_IsLLk(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		k       int
+	}{
+		{"S → aS | b\n", 1},
+		{"S → aSb | ab\n", 2},
+		{"S → aab | aac\n", 3},
+		{"S → Ab | Ac\nA → aA | ε\n", 0},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for k := 1; k <= 3; k++ {
+			expected := test.k != 0 && test.k <= k
+			if ok, conflicts := g.IsLLk(k); ok != expected {
+				t.Errorf("%q: expected LL(%d) to be %v, got %v", test.grammar, k, expected, conflicts)
+			}
+		}
+	}
+}
+
+func ExampleCFG_FirstK() {
+	g, _ := cfg.Parse("S → AB\nA → aA | ε\nB → b | bc\n")
+	first := g.FirstK(2)
+	follow := g.FollowK(2)
+	for _, v := range g.Variables {
+		fmt.Println(v, first[v], follow[v])
+	}
+	// Output:
+	// S [[a a] [a b] [b] [b c]] [[]]
+	// A [[] [a] [a a]] [[b] [b c]]
+	// B [[b] [b c]] [[]]
+}
@@ -0,0 +1,54 @@
+package cfg
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the grammar log its internal decisions at debug level: the warnings of Lint on construction, the
+// productions added and removed by every transform (see Transform), and strings rejected by Evaluate. See also
+// SetLogger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithUnusedTerminalWarnings makes New log a warning for every terminal of the alphabet that is not used by the
+// grammar, see UsedTerminals. The warnings are logged by the logger of WithLogger.
+func WithUnusedTerminalWarnings() Option {
+	return func(o *options) {
+		o.unusedTerminals = true
+	}
+}
+
+// Logger returns the logger of the grammar, or nil if it does not log.
+func (g *CFG) Logger() *slog.Logger {
+	return g.logger
+}
+
+// SetLogger sets the logger of the grammar, nil disables logging. See WithLogger.
+func (g *CFG) SetLogger(logger *slog.Logger) {
+	g.logger = logger
+}
+
+// warnUnusedTerminals logs the terminals of the alphabet that are not used.
+func (g *CFG) warnUnusedTerminals() {
+	used := make(map[Terminal]bool)
+	for _, t := range g.UsedTerminals() {
+		used[t] = true
+	}
+	for _, t := range g.Alphabet {
+		if !used[t] {
+			g.logger.Warn("unused terminal", "terminal", string(t))
+		}
+	}
+}
+
+// debug logs the message if the grammar has a logger.
+func (g *CFG) debug(msg string, args ...any) {
+	if g.logger == nil || !g.logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
+	}
+	g.logger.Debug(msg, args...)
+}
@@ -0,0 +1,51 @@
+package cfg_test
+
+import (
+	"bytes"
+	"github.com/0x51-dev/cfg"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func ExampleWithLogger() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	S, A := cfg.Variable("S"), cfg.Variable("A")
+	a := cfg.Terminal("a")
+	g, _ := cfg.New(cfg.V{S, A}, cfg.Alphabet{a}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{a, S}),
+		cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+		cfg.NewProduction(A, []cfg.Beta{a}),
+	}, S, cfg.WithLogger(logger))
+	g.CNF()
+	g.Evaluate("b")
+	// Output:
+	// level=DEBUG msg="grammar warning" warning="A: unreachable from the start variable"
+	// level=DEBUG msg="transformed grammar" transform=RemoveEpsilon added=1 removed=1 rules=3
+	// level=DEBUG msg="transformed grammar" transform=RemoveUnits added=0 removed=0 rules=3
+	// level=DEBUG msg="transformed grammar" transform=Binarize added=0 removed=0 rules=3
+	// level=DEBUG msg="transformed grammar" transform=LiftTerminals added=1 removed=1 rules=3
+	// level=DEBUG msg="string rejected" input=b steps=2
+}
+
+func TestWithUnusedTerminalWarnings(t *testing.T) {
+	var b bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&b, nil))
+	S := cfg.Variable("S")
+	rules := cfg.R{cfg.NewProduction(S, []cfg.Beta{cfg.Terminal("a")})}
+	if _, err := cfg.New(cfg.V{S}, cfg.Alphabet{"a", "b"}, rules, S, cfg.WithLogger(logger), cfg.WithUnusedTerminalWarnings()); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.String(); strings.Count(s, "level=WARN") != 1 || !strings.Contains(s, `msg="unused terminal" terminal=b`) {
+		t.Error(s)
+	}
+}
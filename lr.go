@@ -0,0 +1,141 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+)
+
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool { return items[i].String() < items[j].String() })
+}
+
+// Item is an LR(0) item, a production with a dot marking how much of it has been recognized.
+type Item struct {
+	Production Production
+	Dot        int
+}
+
+// Complete returns true if the dot is at the end of the production.
+func (i Item) Complete() bool {
+	return len(i.symbols()) <= i.Dot
+}
+
+// Next returns the symbol after the dot, nil if the item is complete.
+func (i Item) Next() Beta {
+	if i.Complete() {
+		return nil
+	}
+	return i.symbols()[i.Dot]
+}
+
+func (i Item) String() string {
+	s := i.symbols()
+	return fmt.Sprintf("%v → %s·%s", i.Production.A, join(s[:i.Dot], ""), join(s[i.Dot:], ""))
+}
+
+// symbols returns the right-hand side of the production, without ε.
+func (i Item) symbols() []Beta {
+	var bs []Beta
+	for _, b := range i.Production.B {
+		if b != Epsilon {
+			bs = append(bs, b)
+		}
+	}
+	return bs
+}
+
+// LR0 is the canonical collection of LR(0) item sets of the augmented grammar, the first state is the initial state.
+type LR0 struct {
+	States      [][]Item
+	Transitions []map[Beta]int
+}
+
+// augmentedStart returns the variable `S'` of the augmented production `S' → S`, with more primes if the grammar
+// already uses it.
+func (g *CFG) augmentedStart() Variable {
+	return fresh(string(g.StartVariable)+"'", g.names())
+}
+
+// LR0 computes the LR(0) automaton of the grammar, augmented with the production `S' → S`.
+func (g *CFG) LR0() *LR0 {
+	if g.tables != nil {
+		return g.tables.LR0.clone()
+	}
+	start := Item{Production: NewProduction(g.augmentedStart(), []Beta{g.StartVariable})}
+	a := &LR0{}
+	index := make(map[string]int)
+	add := func(items []Item) int {
+		key := join(items, "\n")
+		if i, ok := index[key]; ok {
+			return i
+		}
+		index[key] = len(a.States)
+		a.States = append(a.States, items)
+		a.Transitions = append(a.Transitions, make(map[Beta]int))
+		return len(a.States) - 1
+	}
+	add(g.closure([]Item{start}))
+	for i := 0; i < len(a.States); i++ {
+		var symbols []Beta
+		kernels := make(map[Beta][]Item)
+		for _, item := range a.States[i] {
+			next := item.Next()
+			if next == nil {
+				continue
+			}
+			if _, ok := kernels[next]; !ok {
+				symbols = append(symbols, next)
+			}
+			kernels[next] = append(kernels[next], Item{Production: item.Production, Dot: item.Dot + 1})
+		}
+		for _, s := range symbols {
+			a.Transitions[i][s] = add(g.closure(kernels[s]))
+		}
+	}
+	return a
+}
+
+// Conflicts returns the indices of the states containing shift/reduce or reduce/reduce conflicts.
+func (a *LR0) Conflicts() []int {
+	var conflicts []int
+	for i, items := range a.States {
+		var complete, shift int
+		for _, item := range items {
+			if item.Complete() {
+				complete++
+			} else if _, ok := item.Next().(Variable); !ok {
+				shift++
+			}
+		}
+		if 1 < complete || (complete == 1 && 0 < shift) {
+			conflicts = append(conflicts, i)
+		}
+	}
+	return conflicts
+}
+
+// closure extends the kernel items with the items of all variables after a dot. Both the kernel and the added items
+// are sorted, to get a canonical representation of the set.
+func (g *CFG) closure(kernel []Item) []Item {
+	sortItems(kernel)
+	seen := make(map[string]bool)
+	for _, item := range kernel {
+		seen[item.String()] = true
+	}
+	items := kernel
+	for i := 0; i < len(items); i++ {
+		v, ok := items[i].Next().(Variable)
+		if !ok {
+			continue
+		}
+		for _, p := range g.mappedRules[v] {
+			item := Item{Production: p}
+			if s := item.String(); !seen[s] {
+				seen[s] = true
+				items = append(items, item)
+			}
+		}
+	}
+	sortItems(items[len(kernel):])
+	return items
+}
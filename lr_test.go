@@ -0,0 +1,56 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleLR0_DOT() {
+	g, _ := cfg.Parse("S → aS | b\n")
+	fmt.Print(g.LR0().DOT())
+	// Output:
+	// digraph LR0 {
+	// 	rankdir=LR;
+	// 	node [shape=box, fontname=monospace];
+	// 	I0 [label="I0\lS' → ·S\lS → ·aS\lS → ·b\l"];
+	// 	I1 [label="I1\lS' → S·\l"];
+	// 	I2 [label="I2\lS → a·S\lS → ·aS\lS → ·b\l"];
+	// 	I3 [label="I3\lS → b·\l"];
+	// 	I4 [label="I4\lS → aS·\l"];
+	// 	I0 -> I1 [label="S"];
+	// 	I0 -> I2 [label="a"];
+	// 	I0 -> I3 [label="b"];
+	// 	I2 -> I4 [label="S"];
+	// 	I2 -> I2 [label="a"];
+	// 	I2 -> I3 [label="b"];
+	// }
+}
+
+func ExampleLR0_Conflicts() {
+	g, _ := cfg.Parse("S → Sa | ε\n")
+	a := g.LR0()
+	for _, i := range a.Conflicts() {
+		fmt.Println(i, a.States[i])
+	}
+	// Output:
+	// 1 [S → S·a S' → S·]
+}
+
+func TestCFG_LR0_augmented(t *testing.T) {
+	g, err := cfg.New(cfg.V{"S", "S'"}, cfg.Alphabet{"a"}, cfg.R{
+		cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Variable("S'")}),
+		cfg.NewProduction(cfg.Variable("S'"), []cfg.Beta{cfg.Variable("S")}),
+		cfg.NewProduction(cfg.Variable("S'"), []cfg.Beta{cfg.Terminal("a")}),
+	}, "S")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := g.LR0()
+	if start := a.States[0][0].Production; start.A != cfg.Variable("S''") {
+		t.Errorf("expected the augmented production to use a fresh variable, got %v", start)
+	}
+	if len(a.States[0]) != 4 {
+		t.Errorf("expected 4 items in the initial state, got %v", a.States[0])
+	}
+}
@@ -0,0 +1,224 @@
+// Package lsp implements the language features needed by editors to support the grammar text format: diagnostics,
+// go-to-definition and hover. The types mirror the Language Server Protocol, so they can be sent over any transport.
+package lsp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity int
+
+const (
+	Error   Severity = 1
+	Warning Severity = 2
+)
+
+// Diagnostic is an error or warning for a range in the document.
+type Diagnostic struct {
+	Range    Range    `json:"range"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Document is an analyzed grammar document.
+type Document struct {
+	grammar     *cfg.CFG
+	diagnostics []Diagnostic
+	definitions map[cfg.Variable]Range
+	symbols     []symbol
+}
+
+// Open parses and analyzes the given grammar text, with cfg.ParseRules. Invalid lines are reported and skipped, so the
+// rest of the document can still be analyzed.
+func Open(text string) *Document {
+	d := &Document{definitions: make(map[cfg.Variable]Range)}
+	rules, occurrences, errs := cfg.ParseRules(text)
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for _, err := range errs {
+		var syntax *cfg.SyntaxError
+		if !errors.As(err, &syntax) {
+			d.diagnostics = append(d.diagnostics, Diagnostic{Severity: Error, Message: err.Error()})
+			continue
+		}
+		// The whole line is skipped.
+		line := syntax.Line - 1
+		d.diagnostics = append(d.diagnostics, Diagnostic{
+			Range: Range{
+				Start: Position{Line: line},
+				End:   Position{Line: line, Character: len([]rune(lines[line]))},
+			},
+			Severity: Error,
+			Message:  "expected a production rule or a terminal set",
+		})
+	}
+	var start cfg.Variable
+	for _, o := range occurrences {
+		at := Position{Line: o.Position.Line - 1, Character: o.Position.Column - 1}
+		d.symbols = append(d.symbols, symbol{
+			variable:   o.Variable,
+			rng:        Range{Start: at, End: Position{Line: at.Line, Character: at.Character + o.Length}},
+			definition: o.Definition,
+		})
+	}
+	for _, rule := range rules {
+		// Terminal sets are variables of the form <name>, they are never the start variable.
+		if v := rule.A.(cfg.Variable); !strings.HasPrefix(string(v), "<") {
+			start = v
+			break
+		}
+	}
+	if start == "" {
+		return d
+	}
+
+	for _, sym := range d.symbols {
+		if sym.definition {
+			if _, ok := d.definitions[sym.variable]; !ok {
+				d.definitions[sym.variable] = sym.rng
+			}
+		}
+	}
+	var variables cfg.V
+	defined := make(map[cfg.Variable]bool)
+	for _, sym := range d.symbols {
+		if defined[sym.variable] {
+			continue
+		}
+		defined[sym.variable] = true
+		variables = append(variables, sym.variable)
+	}
+	for _, sym := range d.symbols {
+		if _, ok := d.definitions[sym.variable]; !ok {
+			d.diagnostics = append(d.diagnostics, Diagnostic{
+				Range:    sym.rng,
+				Severity: Error,
+				Message:  fmt.Sprintf("undefined variable %s", sym.variable),
+			})
+		}
+	}
+
+	var alphabet cfg.Alphabet
+	terminals := make(map[cfg.Terminal]bool)
+	for _, rule := range rules {
+		for _, b := range rule.B {
+			if t, ok := b.(cfg.Terminal); ok && !terminals[t] {
+				terminals[t] = true
+				alphabet = append(alphabet, t)
+			}
+		}
+	}
+	g, err := cfg.New(variables, alphabet, rules, start)
+	if err != nil {
+		d.diagnostics = append(d.diagnostics, Diagnostic{Severity: Error, Message: err.Error()})
+		return d
+	}
+	d.grammar = g
+	for _, w := range g.Lint() {
+		if _, ok := d.definitions[w.Variable]; !ok {
+			// Already reported as undefined.
+			continue
+		}
+		// The position of a production is the one of its expression.
+		start := Position{Line: w.Position.Line - 1, Character: w.Position.Column - 1}
+		d.diagnostics = append(d.diagnostics, Diagnostic{
+			Range:    Range{Start: start, End: Position{Line: start.Line, Character: start.Character + 1}},
+			Severity: Warning,
+			Message:  fmt.Sprintf("%s: %s", w.Variable, w.Message),
+		})
+	}
+	return d
+}
+
+// Definition returns the range of the first production rule defining the variable at the given position.
+func (d *Document) Definition(p Position) (Range, bool) {
+	sym, ok := d.symbolAt(p)
+	if !ok {
+		return Range{}, false
+	}
+	rng, ok := d.definitions[sym.variable]
+	return rng, ok
+}
+
+// Diagnostics returns the syntax errors, undefined variables and lint warnings of the document.
+func (d *Document) Diagnostics() []Diagnostic {
+	return d.diagnostics
+}
+
+// Grammar returns the grammar of the document, nil if the document could not be analyzed.
+func (d *Document) Grammar() *cfg.CFG {
+	return d.grammar
+}
+
+// Hover returns the FIRST and FOLLOW sets of the variable at the given position.
+func (d *Document) Hover(p Position) (Hover, bool) {
+	sym, ok := d.symbolAt(p)
+	if !ok || d.grammar == nil {
+		return Hover{}, false
+	}
+	first := join(d.grammar.First()[sym.variable])
+	for _, v := range d.grammar.Nullable() {
+		if v == sym.variable {
+			// ε is not a terminal, but part of the FIRST set of a nullable variable.
+			first = strings.TrimPrefix(first+", ε", ", ")
+		}
+	}
+	follow := d.grammar.Follow()[sym.variable]
+	return Hover{
+		Contents: fmt.Sprintf(
+			"FIRST(%s) = { %s }\nFOLLOW(%s) = { %s }",
+			sym.variable, first, sym.variable, join(follow),
+		),
+		Range: sym.rng,
+	}, true
+}
+
+func (d *Document) symbolAt(p Position) (symbol, bool) {
+	for _, sym := range d.symbols {
+		if sym.rng.contains(p) {
+			return sym, true
+		}
+	}
+	return symbol{}, false
+}
+
+// Hover is the information shown when hovering over a symbol.
+type Hover struct {
+	Contents string `json:"contents"`
+	Range    Range  `json:"range"`
+}
+
+// Position is a zero-based line and character offset. Since all symbols of the text format are within the basic
+// multilingual plane, the character offset is both the rune and the UTF-16 offset.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a range in the document, the end position is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+func (r Range) contains(p Position) bool {
+	return r.Start.Line == p.Line && r.Start.Character <= p.Character && p.Character < r.End.Character
+}
+
+func join(a cfg.Alphabet) string {
+	var s []string
+	for _, t := range a {
+		s = append(s, t.String())
+	}
+	return strings.Join(s, ", ")
+}
+
+// symbol is an occurrence of a variable in the document.
+type symbol struct {
+	variable   cfg.Variable
+	rng        Range
+	definition bool
+}
@@ -0,0 +1,113 @@
+package lsp_test
+
+import (
+	"github.com/0x51-dev/cfg/lsp"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	d := lsp.Open("S → aAb | X\nA → aA | ε\nB → b\n")
+	var messages []string
+	for _, d := range d.Diagnostics() {
+		messages = append(messages, d.Message)
+	}
+	expected := []string{
+		"undefined variable X",
+		"B: unreachable from the start variable",
+	}
+	if len(messages) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, messages)
+	}
+	for i, m := range messages {
+		if m != expected[i] {
+			t.Errorf("expected %q, got %q", expected[i], m)
+		}
+	}
+
+	rng, ok := d.Definition(lsp.Position{Line: 0, Character: 5})
+	if !ok || rng.Start != (lsp.Position{Line: 1, Character: 0}) {
+		t.Errorf("expected definition of A at 1:0, got %v", rng)
+	}
+	if _, ok := d.Definition(lsp.Position{Line: 0, Character: 4}); ok {
+		t.Error("expected no definition for a terminal")
+	}
+
+	h, ok := d.Hover(lsp.Position{Line: 1, Character: 5})
+	if !ok {
+		t.Fatal("expected hover for A")
+	}
+	if h.Contents != "FIRST(A) = { a, ε }\nFOLLOW(A) = { b }" {
+		t.Errorf("unexpected hover: %q", h.Contents)
+	}
+}
+
+func TestDocument_syntaxError(t *testing.T) {
+	d := lsp.Open("S → a\nS = b\nS → a | \n")
+	diagnostics := d.Diagnostics()
+	if len(diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, got %v", diagnostics)
+	}
+	for i, line := range []int{1, 2} {
+		if diagnostics[i].Severity != lsp.Error || diagnostics[i].Range.Start.Line != line {
+			t.Errorf("expected error on line %d, got %v", line, diagnostics[i])
+		}
+	}
+	if d.Grammar() == nil {
+		t.Error("expected the valid lines to be analyzed")
+	}
+}
+
+func TestDocument_labels(t *testing.T) {
+	d := lsp.Open("S → aSb #wrap | ε #empty\nS → a #x #y\n")
+	if diagnostics := d.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Range.Start.Line != 1 {
+		t.Fatalf("expected a single error on line 1, got %v", diagnostics)
+	}
+	if l := d.Grammar().Rules[0].Label; l != "wrap" {
+		t.Errorf("expected label wrap, got %q", l)
+	}
+}
+
+func TestDocument_duplicate(t *testing.T) {
+	d := lsp.Open("S → a | b\nS → a\n")
+	diagnostics := d.Diagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected a single warning, got %v", diagnostics)
+	}
+	if w := diagnostics[0]; w.Range.Start.Line != 1 || w.Message != "S: duplicate of S → a (line 1)" {
+		t.Errorf("unexpected warning: %v", w)
+	}
+}
+
+func TestDocument_terminalSets(t *testing.T) {
+	d := lsp.Open("digit = 0-2 | x\nS → <digit>S | <digit>\n")
+	if diagnostics := d.Diagnostics(); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+	if s := d.Grammar().StartVariable; s != "S" {
+		t.Errorf("expected start variable S, got %s", s)
+	}
+	rng, ok := d.Definition(lsp.Position{Line: 1, Character: 6})
+	if !ok || rng != (lsp.Range{Start: lsp.Position{Line: 0, Character: 0}, End: lsp.Position{Line: 0, Character: 5}}) {
+		t.Errorf("expected definition of digit at 0:0-0:5, got %v", rng)
+	}
+	if _, ok := d.Grammar().Evaluate("x20"); !ok {
+		t.Error("expected x20 to be accepted")
+	}
+}
+
+func TestDocument_syntax(t *testing.T) {
+	// Captures, escapes and categories are part of the syntax of cfg.Parse.
+	d := lsp.Open("S → (body:A) | \\| A\nA → \\p{L}A | name:\\p{Nd}\n")
+	if diagnostics := d.Diagnostics(); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics, got %v", diagnostics)
+	}
+	rng, ok := d.Definition(lsp.Position{Line: 0, Character: 10})
+	if !ok || rng.Start != (lsp.Position{Line: 1, Character: 0}) {
+		t.Errorf("expected definition of A at 1:0, got %v", rng)
+	}
+	for _, s := range []string{"(x1)", "|7"} {
+		if _, ok := d.Grammar().Evaluate(s); !ok {
+			t.Errorf("expected %q to be accepted", s)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package cfg
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// WithMetrics makes the grammar count its evaluations in the given metrics, see SetMetrics.
+func WithMetrics(m *Metrics) Option {
+	return func(o *options) {
+		o.metrics = m
+	}
+}
+
+// SetMetrics sets the metrics in which Evaluate, EvaluateAll and Derive are counted, nil disables counting. The
+// metrics can be shared by several grammars.
+func (g *CFG) SetMetrics(m *Metrics) {
+	g.metrics = m
+}
+
+// Metrics counts the evaluations of one or more grammars, it is safe for concurrent use. Since it implements
+// expvar.Var it can be published with expvar.Publish, Collect exposes the counters to other metric systems.
+type Metrics struct {
+	evaluations, rejects, steps atomic.Int64
+	// hits and misses are the lookups of the memo of Derive.
+	hits, misses atomic.Int64
+}
+
+// AverageSteps returns the average number of productions that were tried per evaluation.
+func (m *Metrics) AverageSteps() float64 {
+	n := m.evaluations.Load()
+	if n == 0 {
+		return 0
+	}
+	return float64(m.steps.Load()) / float64(n)
+}
+
+// CacheHitRate returns the fraction of calls of Derive that were answered by the memo.
+func (m *Metrics) CacheHitRate() float64 {
+	hits := m.hits.Load()
+	n := hits + m.misses.Load()
+	if n == 0 {
+		return 0
+	}
+	return float64(hits) / float64(n)
+}
+
+// Collect calls the function with the name and value of every metric, in the style of Prometheus: the counters end
+// with `_total`, e.g. `cfg_evaluations_total`.
+func (m *Metrics) Collect(metric func(name string, value float64)) {
+	metric("cfg_evaluations_total", float64(m.evaluations.Load()))
+	metric("cfg_rejects_total", float64(m.rejects.Load()))
+	metric("cfg_steps_total", float64(m.steps.Load()))
+	metric("cfg_cache_hits_total", float64(m.hits.Load()))
+	metric("cfg_cache_misses_total", float64(m.misses.Load()))
+}
+
+// Evaluations returns the number of evaluations.
+func (m *Metrics) Evaluations() int64 {
+	return m.evaluations.Load()
+}
+
+// Rejects returns the number of evaluations that did not find a derivation.
+func (m *Metrics) Rejects() int64 {
+	return m.rejects.Load()
+}
+
+// String returns the metrics as a JSON object.
+func (m *Metrics) String() string {
+	values := make(map[string]float64)
+	m.Collect(func(name string, value float64) {
+		values[name] = value
+	})
+	b, _ := json.Marshal(values)
+	return string(b)
+}
+
+// evaluated counts an evaluation, m may be nil.
+func (m *Metrics) evaluated(steps int, accepted bool) {
+	if m == nil {
+		return
+	}
+	m.evaluations.Add(1)
+	m.steps.Add(int64(steps))
+	if !accepted {
+		m.rejects.Add(1)
+	}
+}
+
+// lookedUp counts a lookup of the memo of Derive, m may be nil.
+func (m *Metrics) lookedUp(hit bool) {
+	if m == nil {
+		return
+	}
+	if hit {
+		m.hits.Add(1)
+	} else {
+		m.misses.Add(1)
+	}
+}
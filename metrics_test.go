@@ -0,0 +1,53 @@
+package cfg_test
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleMetrics() {
+	g, _ := cfg.Parse("S → aSa | bSb | ε\n")
+	m := new(cfg.Metrics)
+	g.SetMetrics(m)
+	g.Evaluate("abba")
+	g.Evaluate("ab")
+	g.Derive("S", "aa")
+	g.Derive("S", "aa")
+	fmt.Println(m.Evaluations(), m.Rejects(), m.CacheHitRate())
+	m.Collect(func(name string, value float64) {
+		fmt.Println(name, value)
+	})
+	// Output:
+	// 2 1 0.5
+	// cfg_evaluations_total 2
+	// cfg_rejects_total 1
+	// cfg_steps_total 12
+	// cfg_cache_hits_total 1
+	// cfg_cache_misses_total 1
+}
+
+func TestMetrics_String(t *testing.T) {
+	var _ expvar.Var = new(cfg.Metrics)
+	m := new(cfg.Metrics)
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := g.Reverse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetMetrics(m)
+	h.SetMetrics(m)
+	g.EvaluateAll("aa")
+	h.Evaluate("b")
+	if m.Evaluations() != 2 || m.Rejects() != 1 || m.AverageSteps() == 0 {
+		t.Error(m)
+	}
+	expected := `{"cfg_cache_hits_total":0,"cfg_cache_misses_total":0,"cfg_evaluations_total":2,"cfg_rejects_total":1,"cfg_steps_total":`
+	if s := m.String(); len(s) < len(expected) || s[:len(expected)] != expected {
+		t.Error(s)
+	}
+}
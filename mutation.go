@@ -0,0 +1,119 @@
+package cfg
+
+import "fmt"
+
+// MutationTest measures the strength of a test corpus of the grammar: a mutant of the grammar (see Mutants) is killed
+// if the corpus contains a string that is in the language of only one of both grammars. Mutants that accept the same
+// language as the grammar can not be killed.
+func MutationTest(g *CFG, corpus []string) (*MutationReport, error) {
+	original, err := g.CYK()
+	if err != nil {
+		return nil, err
+	}
+	accepted := make([]bool, len(corpus))
+	for i, s := range corpus {
+		accepted[i] = original.Recognize(s)
+	}
+	mutants, err := g.Mutants()
+	if err != nil {
+		return nil, err
+	}
+	report := new(MutationReport)
+	for _, m := range mutants {
+		recognizer, err := m.Grammar.CYK()
+		if err != nil {
+			return nil, err
+		}
+		killed := false
+		for i, s := range corpus {
+			if recognizer.Recognize(s) != accepted[i] {
+				killed = true
+				break
+			}
+		}
+		if killed {
+			report.Killed = append(report.Killed, m)
+		} else {
+			report.Survived = append(report.Survived, m)
+		}
+	}
+	return report, nil
+}
+
+// Mutants returns all grammars that differ from the grammar by a single change of a rule: the rule is dropped, two
+// adjacent symbols of the rule are swapped, or a terminal of the rule is replaced by another terminal of the alphabet.
+func (g *CFG) Mutants() ([]Mutant, error) {
+	var mutants []Mutant
+	add := func(i int, description string, replacement ...Production) error {
+		rules := make(R, 0, len(g.Rules))
+		rules = append(append(append(rules, g.Rules[:i]...), replacement...), g.Rules[i+1:]...)
+		mutant, err := New(g.Variables, g.Alphabet, rules, g.StartVariable)
+		if err != nil {
+			return err
+		}
+		mutants = append(mutants, Mutant{Grammar: mutant, Rule: i, Description: description})
+		return nil
+	}
+	for i, rule := range g.Rules {
+		if err := add(i, fmt.Sprintf("drop %v", rule)); err != nil {
+			return nil, err
+		}
+		for j := 0; j+1 < len(rule.B); j++ {
+			if rule.B[j] == rule.B[j+1] {
+				continue
+			}
+			mutated := rule
+			mutated.B = append([]Beta{}, rule.B...)
+			mutated.B[j], mutated.B[j+1] = mutated.B[j+1], mutated.B[j]
+			positions := make([]int, len(rule.B))
+			for k := range positions {
+				positions[k] = k
+			}
+			positions[j], positions[j+1] = j+1, j
+			mutated.Captures = rule.capturesAt(positions)
+			if err := add(i, fmt.Sprintf("swap %v and %v in %v", rule.B[j], rule.B[j+1], rule), mutated); err != nil {
+				return nil, err
+			}
+		}
+		for j, b := range rule.B {
+			if _, ok := b.(Terminal); !ok {
+				continue
+			}
+			for _, t := range g.Alphabet {
+				if t == rule.B[j] {
+					continue
+				}
+				mutated := rule
+				mutated.B = append([]Beta{}, rule.B...)
+				mutated.B[j] = t
+				if err := add(i, fmt.Sprintf("replace %v by %v in %v", rule.B[j], t, rule), mutated); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return mutants, nil
+}
+
+// Mutant is a grammar that differs from another by a single change of a rule.
+type Mutant struct {
+	Grammar *CFG
+	// Rule is the index of the changed rule in the original grammar.
+	Rule int
+	// Description describes the change, e.g. `drop S → aSa`.
+	Description string
+}
+
+// MutationReport lists the killed and the surviving mutants of a mutation test.
+type MutationReport struct {
+	Killed, Survived []Mutant
+}
+
+// Score returns the fraction of the mutants that were killed, 1 if there are no mutants.
+func (r *MutationReport) Score() float64 {
+	n := len(r.Killed) + len(r.Survived)
+	if n == 0 {
+		return 1
+	}
+	return float64(len(r.Killed)) / float64(n)
+}
@@ -0,0 +1,35 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleMutationTest() {
+	report, _ := cfg.MutationTest(g, []string{"", "aa", "abba"})
+	for _, m := range report.Survived {
+		fmt.Println(m.Description)
+	}
+	fmt.Printf("%.2f\n", report.Score())
+	// Output:
+	// swap b and S in S → bSb
+	// swap S and b in S → bSb
+	// 0.82
+}
+
+func TestCFG_Mutants(t *testing.T) {
+	mutants, err := g.Mutants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 drops, 4 swaps and 4 replacements.
+	if len(mutants) != 11 {
+		t.Fatalf("expected 11 mutants, got %d", len(mutants))
+	}
+	for _, m := range mutants {
+		if len(m.Grammar.Rules) == len(g.Rules) && m.Grammar.String() == g.String() {
+			t.Errorf("mutant %q is the original grammar", m.Description)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/0x51-dev/upeg/parser"
 	"github.com/0x51-dev/upeg/parser/op"
+	"strings"
 )
 
 var (
@@ -12,7 +13,7 @@ var (
 		Value: op.And{
 			op.ZeroOrMore{Value: op.EndOfLine{}},
 			op.OneOrMore{
-				Value: productionRule,
+				Value: op.Or{productionRule, terminalSet},
 			},
 		},
 	}
@@ -27,87 +28,341 @@ var (
 			'(', ')', '[', ']',
 		},
 	}
+	// escaped is any character (except a newline) preceded by a backslash, e.g. `\|` or `\→`, which is a terminal.
+	escaped = op.Capture{
+		Name:  "Escaped",
+		Value: op.Ignore{Value: op.And{'\\', op.AnyBut{Value: op.Or{'\n', '\r'}}}},
+	}
+	// unicodeCategory is a Unicode category, e.g. `\p{Lu}`, which is a terminal that matches any rune of it.
+	unicodeCategory = op.Capture{
+		Name: "Category",
+		Value: op.Ignore{Value: op.And{
+			"\\p{",
+			op.OneOrMore{Value: op.Or{op.RuneRange{Min: 'a', Max: 'z'}, op.RuneRange{Min: 'A', Max: 'Z'}}},
+			'}',
+		}},
+	}
+	setName = op.Capture{
+		Name: "SetName",
+		Value: op.Ignore{Value: op.And{
+			op.RuneRange{Min: 'a', Max: 'z'},
+			op.ZeroOrMore{Value: op.Or{
+				op.RuneRange{Min: 'a', Max: 'z'},
+				op.RuneRange{Min: '0', Max: '9'},
+				'_',
+			}},
+		}},
+	}
+	setReference = op.And{'<', setName, '>'}
+	// member is a single character, or a range of characters (e.g. `0-9`), of a terminal set.
+	member = op.And{position{}, op.Or{
+		unicodeCategory,
+		escaped,
+		op.Capture{Name: "Range", Value: op.Ignore{Value: op.And{memberCharacter, '-', memberCharacter}}},
+		op.Capture{Name: "Terminal", Value: memberCharacter},
+	}}
+	memberCharacter = op.AnyBut{Value: op.Or{' ', '\t', '\n', '\r', '|'}}
+	terminalSet     = op.Capture{
+		Name: "TerminalSet",
+		Value: op.And{
+			position{},
+			setName,
+			'=',
+			member,
+			op.ZeroOrMore{Value: op.And{'|', member}},
+			op.EndOfLine{},
+		},
+	}
 	epsilon = op.Capture{
 		Name:  "Epsilon",
 		Value: 'ε',
 	}
+	element = op.And{position{}, op.Or{unicodeCategory, escaped, terminal, nonTerminal, setReference}}
+	// captured is a symbol with a capture name, e.g. `body:S`, see Tree.Captures.
+	captured = op.Capture{
+		Name: "Captured",
+		Value: op.And{
+			op.Capture{
+				Name: "CaptureName",
+				Value: op.Ignore{Value: op.And{
+					op.RuneRange{Min: 'a', Max: 'z'},
+					op.ZeroOrMore{Value: op.Or{
+						op.RuneRange{Min: 'a', Max: 'z'},
+						op.RuneRange{Min: '0', Max: '9'},
+						'_',
+					}},
+				}},
+			},
+			':',
+			element,
+		},
+	}
 	expression = op.Capture{
 		Name:  "Expression",
-		Value: op.Or{op.OneOrMore{Value: op.Or{terminal, nonTerminal}}, epsilon},
+		Value: op.Or{op.OneOrMore{Value: op.Or{captured, element}}, epsilon},
+	}
+	label = op.Capture{
+		Name: "Label",
+		Value: op.Ignore{Value: op.OneOrMore{Value: op.Or{
+			op.RuneRange{Min: 'a', Max: 'z'},
+			op.RuneRange{Min: 'A', Max: 'Z'},
+			op.RuneRange{Min: '0', Max: '9'},
+			'.', '_', '-',
+		}}},
 	}
+	alternative    = op.And{position{}, expression, op.Optional{Value: op.And{'#', label}}}
 	productionRule = op.Capture{
 		Name: "ProductionRule",
 		Value: op.And{
+			position{},
 			nonTerminal,
 			op.Or{'→', "->"},
-			expression,
-			op.ZeroOrMore{Value: op.And{'|', expression}},
+			alternative,
+			op.ZeroOrMore{Value: op.And{'|', alternative}},
 			op.EndOfLine{},
 		},
 	}
 )
 
+// position captures the current position in the input, without consuming any input.
+type position struct{}
+
+func (position) Match(start parser.Cursor, _ *parser.Parser) (parser.Cursor, error) {
+	return start, nil
+}
+
+func (position) Parse(p *parser.Parser) (*parser.Node, error) {
+	line, column := p.Reader.Cursor().Line()
+	return parser.NewNode("Position", fmt.Sprintf("%d:%d", line+1, column+1)), nil
+}
+
+func (position) String() string {
+	return "Position"
+}
+
+// SyntaxError is an error in a single line of the text format, returned by ParseTolerant.
+type SyntaxError struct {
+	// Line is the one-based line number.
+	Line int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Occurrence is a variable in the text format, see ParseRules. The variable of a terminal set is `<name>`, its
+// definition only spans the name.
+type Occurrence struct {
+	Variable Variable
+	Position Position
+	// Length is the number of characters of the occurrence.
+	Length int
+	// Definition is true for the variable of a production rule and the name of a terminal set.
+	Definition bool
+}
+
+// parsed are the productions of the text format, before they are checked by New.
+type parsed struct {
+	start       Variable
+	variables   []Variable
+	terminals   []Terminal
+	productions []Production
+	occurrences []Occurrence
+	// sets are the names of the terminal sets that are defined.
+	sets map[string]struct{}
+}
+
 func parseGrammar(n *parser.Node) (*CFG, error) {
+	p, err := parseRules(n)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range p.occurrences {
+		if name := strings.TrimSuffix(strings.TrimPrefix(string(o.Variable), "<"), ">"); name != string(o.Variable) {
+			if _, ok := p.sets[name]; !ok {
+				return nil, fmt.Errorf("terminal set %s not defined", name)
+			}
+		}
+	}
+	return New(p.variables, p.terminals, p.productions, p.start)
+}
+
+func parseRules(n *parser.Node) (*parsed, error) {
 	if n.Name != "CFG" {
 		return nil, fmt.Errorf("expected CFG, got %s", n.Name)
 	}
 
-	var start Variable
+	// The maps keep track of seen symbols, the slices preserve the order in which they appear.
+	p := &parsed{sets: make(map[string]struct{})}
 	vm := make(map[Variable]struct{})
 	tm := make(map[Terminal]struct{})
-	var productions []Production
+	// Categories are not part of the alphabet.
+	addTerminal := func(b Beta) {
+		t, ok := b.(Terminal)
+		if !ok {
+			return
+		}
+		if _, ok := tm[t]; !ok {
+			tm[t] = struct{}{}
+			p.terminals = append(p.terminals, t)
+		}
+	}
+	parsePosition := func(n *parser.Node) (Position, error) {
+		var pos Position
+		_, err := fmt.Sscanf(n.Value(), "%d:%d", &pos.Line, &pos.Column)
+		return pos, err
+	}
+	// Terminal sets are defined as variables, so they can be referenced before their definition.
+	for _, n := range n.Children() {
+		if n.Name == "TerminalSet" {
+			name := n.Children()[1].Value()
+			if _, ok := p.sets[name]; ok {
+				return nil, fmt.Errorf("terminal set %s already defined", name)
+			}
+			p.sets[name] = struct{}{}
+		}
+	}
 	for _, n := range n.Children() {
+		if n.Name == "TerminalSet" {
+			name := n.Children()[1].Value()
+			v := setVariable(name)
+			vm[v] = struct{}{}
+			p.variables = append(p.variables, v)
+			pos, err := parsePosition(n.Children()[0])
+			if err != nil {
+				return nil, err
+			}
+			p.occurrences = append(p.occurrences, Occurrence{Variable: v, Position: pos, Length: len([]rune(name)), Definition: true})
+			for _, n := range n.Children()[2:] {
+				switch n.Name {
+				case "Position":
+					if pos, err = parsePosition(n); err != nil {
+						return nil, err
+					}
+				case "Range":
+					rs := []rune(n.Value())
+					min, max := rs[0], rs[len(rs)-1]
+					if max < min {
+						return nil, fmt.Errorf("invalid range %s in terminal set %s", n.Value(), v)
+					}
+					for r := min; r <= max; r++ {
+						addTerminal(Terminal(r))
+						p.productions = append(p.productions, Production{A: v, B: []Beta{Terminal(r)}, Position: pos})
+					}
+				case "Terminal", "Escaped", "Category":
+					t, err := parseTerminal(n)
+					if err != nil {
+						return nil, err
+					}
+					addTerminal(t)
+					p.productions = append(p.productions, Production{A: v, B: []Beta{t}, Position: pos})
+				default:
+					return nil, fmt.Errorf("expected Range or Terminal, got %s", n.Name)
+				}
+			}
+			continue
+		}
 		if n.Name != "ProductionRule" {
-			return nil, fmt.Errorf("expected ProductionRule, got %s", n.Name)
+			return nil, fmt.Errorf("expected ProductionRule or TerminalSet, got %s", n.Name)
 		}
-		if len(n.Children()) < 2 {
-			return nil, fmt.Errorf("expected at least 2 children, got %d", len(n.Children()))
+		if len(n.Children()) < 3 {
+			return nil, fmt.Errorf("expected at least 3 children, got %d", len(n.Children()))
 		}
 
-		v := Variable(n.Children()[0].Value())
+		v := Variable(n.Children()[1].Value())
 		if _, ok := vm[v]; !ok {
-			if start == "" {
+			if p.start == "" {
 				// First non-terminal is the start symbol.
-				start = v
+				p.start = v
 			}
 			vm[v] = struct{}{}
+			p.variables = append(p.variables, v)
 		}
+		pos, err := parsePosition(n.Children()[0])
+		if err != nil {
+			return nil, err
+		}
+		p.occurrences = append(p.occurrences, Occurrence{Variable: v, Position: pos, Length: 1, Definition: true})
 
-		for _, n := range n.Children()[1:] {
+		for _, n := range n.Children()[2:] {
+			if n.Name == "Position" {
+				// A position belongs to the following expression.
+				if pos, err = parsePosition(n); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if n.Name == "Label" {
+				// A label belongs to the preceding expression.
+				p.productions[len(p.productions)-1].Label = n.Value()
+				continue
+			}
 			if n.Name != "Expression" {
 				return nil, fmt.Errorf("expected Expression, got %s", n.Name)
 			}
 			var ts []Beta
-			for _, n := range n.Children() {
+			var captures []string
+			// at is the position of the next symbol.
+			var at Position
+			children := n.Children()
+			for _, n := range children {
+				if n.Name == "Captured" {
+					if captures == nil {
+						captures = make([]string, len(children))
+					}
+					captures[len(ts)] = n.Children()[0].Value()
+					if at, err = parsePosition(n.Children()[1]); err != nil {
+						return nil, err
+					}
+					n = n.Children()[2]
+				}
 				switch n.Name {
-				case "Terminal":
-					t := Terminal(n.Value())
-					ts = append(ts, t)
-					if _, ok := tm[t]; !ok {
-						tm[t] = struct{}{}
+				case "Position":
+					if at, err = parsePosition(n); err != nil {
+						return nil, err
 					}
+				case "Terminal", "Escaped", "Category":
+					t, err := parseTerminal(n)
+					if err != nil {
+						return nil, err
+					}
+					ts = append(ts, t)
+					addTerminal(t)
 				case "NonTerminal":
 					ts = append(ts, Variable(n.Value()))
+					p.occurrences = append(p.occurrences, Occurrence{Variable: Variable(n.Value()), Position: at, Length: 1})
+				case "SetName":
+					v := setVariable(n.Value())
+					ts = append(ts, v)
+					p.occurrences = append(p.occurrences, Occurrence{Variable: v, Position: at, Length: len([]rune(v))})
 				case "Epsilon":
 					ts = append(ts, Epsilon)
 				default:
-					return nil, fmt.Errorf("expected Terminal, NonTerminal, or Epsilon, got %s", n.Name)
+					return nil, fmt.Errorf("expected Terminal, NonTerminal, SetName, or Epsilon, got %s", n.Name)
 				}
 			}
-			productions = append(productions, Production{A: v, B: ts})
+			if captures != nil {
+				captures = captures[:len(ts)]
+			}
+			p.productions = append(p.productions, Production{A: v, B: ts, Position: pos, Captures: captures})
 		}
 	}
-	var variables []Variable
-	for v := range vm {
-		variables = append(variables, v)
-	}
-	var terminals []Terminal
-	for t := range tm {
-		terminals = append(terminals, t)
-	}
-	return New(variables, terminals, productions, start)
+	return p, nil
 }
 
+// Parse parses a grammar from its text format, with one production rule per line (e.g. `S → aSb | ε`). Every
+// alternative can be labeled with a trailing `#label`. The variable of the first rule is the start variable. A symbol
+// can be prefixed with a capture name (e.g. `S → (body:S)`), see Tree.Captures.
+//
+// Named terminal sets can be declared on their own line (e.g. `digit = 0-9 | _`) and referenced in expressions as
+// `<digit>`. A set is expanded to the variable `<digit>` with one production for each of its terminals.
+//
+// A Unicode category is written as `\p{L}`, see Category.
 func Parse(input string) (*CFG, error) {
 	p, err := parser.New([]rune(input))
 	if err != nil {
@@ -120,3 +375,98 @@ func Parse(input string) (*CFG, error) {
 	}
 	return parseGrammar(n)
 }
+
+// ParseTolerant parses a grammar like Parse, but skips lines that can not be parsed. All syntax errors are returned
+// as SyntaxError, together with the grammar of the remaining lines. The grammar is nil if it can not be constructed
+// from the remaining lines, the error of its construction is then the last error.
+func ParseTolerant(input string) (*CFG, []error) {
+	rules, errs := parseLines(input)
+	if len(rules) == 0 {
+		return nil, errs
+	}
+	g, err := parseGrammar(parser.NewParentNode("CFG", rules))
+	if err != nil {
+		return nil, append(errs, err)
+	}
+	return g, errs
+}
+
+// ParseRules parses the production rules of the text format like ParseTolerant, but does not construct a grammar, so
+// the rules may reference variables and terminal sets that are not defined. The occurrences of the variables are
+// returned in the order of the text, e.g. for editors. The rules are nil if the remaining lines are inconsistent, e.g.
+// define a terminal set twice, the error is then the last error.
+func ParseRules(input string) (R, []Occurrence, []error) {
+	rules, errs := parseLines(input)
+	if len(rules) == 0 {
+		return nil, nil, errs
+	}
+	p, err := parseRules(parser.NewParentNode("CFG", rules))
+	if err != nil {
+		return nil, nil, append(errs, err)
+	}
+	return p.productions, p.occurrences, errs
+}
+
+// parseLines parses the production rules and terminal sets of the input, the lines that can not be parsed are
+// returned as SyntaxError.
+func parseLines(input string) ([]*parser.Node, []error) {
+	if !strings.HasSuffix(input, "\n") {
+		input += "\n"
+	}
+	p, err := parser.New([]rune(input))
+	if err != nil {
+		return nil, []error{err}
+	}
+	p.SetIgnoreList([]any{' ', '\t'})
+	var errs []error
+	var rules []*parser.Node
+	for {
+		for {
+			if _, err := p.Match(op.EndOfLine{}); err != nil {
+				break
+			}
+		}
+		if p.Reader.Done() {
+			break
+		}
+		start := p.Reader.Cursor()
+		n, err := p.Parse(op.Or{productionRule, terminalSet})
+		if err == nil {
+			rules = append(rules, n)
+			continue
+		}
+		line, _ := start.Line()
+		errs = append(errs, &SyntaxError{Line: line + 1, Err: err})
+		// Skip the rest of the line.
+		p.Reader.Jump(start)
+		for !p.Reader.Done() && p.Reader.Rune() != '\n' {
+			p.Reader.Next()
+		}
+	}
+	return rules, errs
+}
+
+// parseTerminal returns the Terminal or Category of a Terminal, Escaped or Category node.
+func parseTerminal(n *parser.Node) (Beta, error) {
+	switch n.Name {
+	case "Escaped":
+		return unescape(n.Value()), nil
+	case "Category":
+		c := Category(strings.TrimSuffix(strings.TrimPrefix(n.Value(), `\p{`), "}"))
+		if err := c.valid(); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	return Terminal(n.Value()), nil
+}
+
+// unescape returns the terminal of an escaped character.
+func unescape(s string) Terminal {
+	return Terminal(strings.TrimPrefix(s, "\\"))
+}
+
+// setVariable returns the variable that is used for the terminal set with the given name.
+func setVariable(name string) Variable {
+	return Variable(fmt.Sprintf("<%s>", name))
+}
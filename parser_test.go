@@ -1,6 +1,9 @@
 package cfg
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -17,3 +20,145 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParse_labels(t *testing.T) {
+	g, err := Parse("S → aSb #wrap | ε #empty\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, label := range []string{"wrap", "empty"} {
+		if l := g.Rules[i].Label; l != label {
+			t.Errorf("expected label %q, got %q", label, l)
+		}
+	}
+	p, _ := g.Evaluate("ab")
+	if s := p.String(); s != "[ S → aSb #wrap, S → ε #empty ]" {
+		t.Errorf("unexpected path: %s", s)
+	}
+}
+
+func TestParse_positions(t *testing.T) {
+	g, err := Parse("\nS → aSa\nS → b | A\nA → a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pos := range []Position{{2, 5}, {3, 5}, {3, 9}, {4, 5}} {
+		if p := g.Rules[i].Position; p != pos {
+			t.Errorf("expected %v, got %v", pos, p)
+		}
+	}
+	if _, err := New(g.Variables, Alphabet{"a"}, g.Rules, g.StartVariable); err == nil || err.Error() != "terminal b not in alphabet: S → b (line 3)" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParse_terminalSets(t *testing.T) {
+	g, err := Parse("S → <digit>S | <digit>\ndigit = 0-3 | x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.String(); s != "( { S, <digit> }, { 0, 1, 2, 3, x }, [ S → <digit>S, S → <digit>, <digit> → 0, <digit> → 1, <digit> → 2, <digit> → 3, <digit> → x ], S )" {
+		t.Errorf("unexpected grammar: %s", s)
+	}
+	if p := g.Rules[6].Position; p != (Position{2, 15}) {
+		t.Errorf("unexpected position: %v", p)
+	}
+	for _, s := range []string{"0", "x2", "3120"} {
+		if _, ok := g.Evaluate(s); !ok {
+			t.Errorf("expected %q to be accepted", s)
+		}
+	}
+	if _, err := Parse("S → <letter>\n"); err == nil || err.Error() != "terminal set letter not defined" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseTolerant(t *testing.T) {
+	g, errs := ParseTolerant("S → aSa | A\nS = b\n\nA → \nA → b | ε\nS → 1")
+	var lines []int
+	for _, err := range errs {
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Fatalf("expected a syntax error, got %v", err)
+		}
+		lines = append(lines, syntaxErr.Line)
+	}
+	if fmt.Sprint(lines) != "[2 4 6]" {
+		t.Errorf("expected errors on lines [2 4 6], got %v", lines)
+	}
+	if g == nil {
+		t.Fatal("expected a partial grammar")
+	}
+	if s := g.String(); s != "( { S, A }, { a, b }, [ S → aSa, S → A, A → b, A → ε ], S )" {
+		t.Errorf("unexpected grammar: %s", s)
+	}
+	if p := g.Rules[2].Position; p != (Position{5, 5}) {
+		t.Errorf("unexpected position: %v", p)
+	}
+
+	if g, errs := ParseTolerant("S → A\n"); g != nil || len(errs) != 1 || errs[0].Error() != "variable A not in variables: S → A (line 1)" {
+		t.Errorf("unexpected result: %v, %v", g, errs)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, occurrences, errs := ParseRules("S → x:A <d> | B\nS = b\nd = 0-1\n")
+	if len(errs) != 1 {
+		t.Fatalf("expected a single syntax error, got %v", errs)
+	}
+	if s := rules.String(); s != "S → x:A<d>, S → B, <d> → 0, <d> → 1" {
+		t.Errorf("unexpected rules: %s", s)
+	}
+	expected := []Occurrence{
+		{Variable: "S", Position: Position{1, 1}, Length: 1, Definition: true},
+		{Variable: "A", Position: Position{1, 7}, Length: 1},
+		{Variable: "<d>", Position: Position{1, 9}, Length: 3},
+		{Variable: "B", Position: Position{1, 15}, Length: 1},
+		{Variable: "<d>", Position: Position{3, 1}, Length: 1, Definition: true},
+	}
+	if !reflect.DeepEqual(occurrences, expected) {
+		t.Errorf("expected %v, got %v", expected, occurrences)
+	}
+}
+
+func TestParse_escaped(t *testing.T) {
+	g, err := Parse("S → a\\→S | \\|\\# | \\\\\\ \n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Alphabet{"a", "→", "|", "#", "\\", " "}
+	if !reflect.DeepEqual(g.Alphabet, expected) {
+		t.Errorf("expected %q, got %q", expected, g.Alphabet)
+	}
+	if _, ok := g.Evaluate("a→a→|#"); !ok {
+		t.Error("expected a→a→|# to be accepted")
+	}
+	h, err := Parse(g.Text())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Text() != h.Text() {
+		t.Errorf("expected %q, got %q", g.Text(), h.Text())
+	}
+	set, err := Parse("S → <d>\nd = \\| | \\  | x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := (Alphabet{"|", " ", "x"}); !reflect.DeepEqual(set.Alphabet, expected) {
+		t.Errorf("expected %q, got %q", expected, set.Alphabet)
+	}
+	// An escaped ε is a terminal, not the empty string.
+	e, err := Parse("S → \\ε | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e.Alphabet, Alphabet{"ε"}) {
+		t.Errorf("expected the terminal ε, got %q", e.Alphabet)
+	}
+	if _, ok := e.Evaluate("ε"); !ok {
+		t.Error("expected ε to be accepted")
+	}
+	if e.Text() != "S → \\ε | ε\n" {
+		t.Errorf("unexpected text %q", e.Text())
+	}
+}
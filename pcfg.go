@@ -0,0 +1,333 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrInconsistent is returned if the probabilities of a PCFG are inconsistent: derivations are infinitely long with
+	// a positive probability, so the probabilities of the finite derivations do not sum up to 1.
+	ErrInconsistent = errors.New("inconsistent PCFG")
+	// ErrInfiniteExpectation is returned if an expectation of a PCFG is infinite. This is the case if the spectral radius
+	// ρ of the matrix of the expected numbers of variables in a production of a variable is at least 1. Grammars with
+	// ρ > 1 are inconsistent, the error then wraps ErrInconsistent as well. Critical grammars with ρ = 1 can be
+	// consistent, e.g. `S → SS | a` with equal probabilities, but their derivations are infinitely long in expectation.
+	ErrInfiniteExpectation = errors.New("infinite expectation")
+)
+
+// NewPCFG returns a probabilistic grammar with the given probabilities of the rules, in the order of the rules. The
+// probabilities of the rules of every variable must sum up to 1.
+func NewPCFG(g *CFG, probabilities []float64) (*PCFG, error) {
+	if len(probabilities) != len(g.Rules) {
+		return nil, fmt.Errorf("expected %d probabilities, got %d", len(g.Rules), len(probabilities))
+	}
+	sums := make(map[Variable]float64)
+	for i, rule := range g.Rules {
+		// NaN fails every comparison, so the check is written such that it is rejected.
+		if !(0 <= probabilities[i] && probabilities[i] <= 1) {
+			return nil, fmt.Errorf("invalid probability %v of %s", probabilities[i], rule.describe())
+		}
+		sums[rule.A.(Variable)] += probabilities[i]
+	}
+	for _, v := range g.Variables {
+		if sum, ok := sums[v]; ok && 1e-9 < math.Abs(sum-1) {
+			return nil, fmt.Errorf("probabilities of %v sum up to %v", v, sum)
+		}
+	}
+	return &PCFG{CFG: g, Probabilities: probabilities}, nil
+}
+
+// solveLinear solves the linear system `ax = b` by Gaussian elimination with partial pivoting, it returns false if the
+// system is singular. The arguments are modified.
+func solveLinear(a [][]float64, b []float64) ([]float64, bool) {
+	n := len(b)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[pivot][col]) < math.Abs(a[row][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, false
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+		for row := col + 1; row < n; row++ {
+			f := a[row][col] / a[col][col]
+			for k := col; k < n; k++ {
+				a[row][k] -= f * a[col][k]
+			}
+			b[row] -= f * b[col]
+		}
+	}
+	x := make([]float64, n)
+	for row := n - 1; 0 <= row; row-- {
+		sum := b[row]
+		for k := row + 1; k < n; k++ {
+			sum -= a[row][k] * x[k]
+		}
+		x[row] = sum / a[row][row]
+	}
+	return x, true
+}
+
+// Train estimates the probabilities of the rules from the relative frequencies of the productions in the derivation
+// trees, e.g. of a treebank, after applying the smoothing. Every production of the trees must be a rule of the grammar.
+// The rules of a variable that does not occur in the trees are uniformly distributed.
+func Train(g *CFG, trees []*Tree, smoothing Smoothing) (*PCFG, error) {
+	if smoothing.Laplace < 0 || smoothing.Backoff < 0 || 1 < smoothing.Backoff || smoothing.Floor < 0 {
+		return nil, fmt.Errorf("invalid smoothing %+v", smoothing)
+	}
+	index := make(map[string]int)
+	alternatives := make(map[Variable][]int)
+	for i, rule := range g.Rules {
+		if _, ok := index[rule.key()]; !ok {
+			index[rule.key()] = i
+		}
+		a := rule.A.(Variable)
+		alternatives[a] = append(alternatives[a], i)
+	}
+	for v, is := range alternatives {
+		if 1 < smoothing.Floor*float64(len(is)) {
+			return nil, fmt.Errorf("floor %v is too high for the %d rules of %v", smoothing.Floor, len(is), v)
+		}
+	}
+
+	counts := make([]float64, len(g.Rules))
+	var err error
+	for _, t := range trees {
+		t.Walk(Visitor{Enter: func(t *Tree) bool {
+			if t.Production == nil || err != nil {
+				return err == nil
+			}
+			i, ok := index[t.Production.key()]
+			if !ok {
+				err = fmt.Errorf("production %v not in rules", t.Production)
+				return false
+			}
+			counts[i]++
+			return true
+		}})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	probabilities := make([]float64, len(g.Rules))
+	for _, is := range alternatives {
+		n := float64(len(is))
+		var total float64
+		for _, i := range is {
+			total += counts[i] + smoothing.Laplace
+		}
+		for _, i := range is {
+			p := 1 / n
+			if total != 0 {
+				p = (counts[i] + smoothing.Laplace) / total
+			}
+			p = (1-smoothing.Backoff)*p + smoothing.Backoff/n
+			probabilities[i] = smoothing.Floor + (1-n*smoothing.Floor)*p
+		}
+	}
+	return &PCFG{CFG: g, Probabilities: probabilities}, nil
+}
+
+// PCFG is a probabilistic context-free grammar, which assigns a probability to every rule of the grammar.
+type PCFG struct {
+	*CFG
+	// Probabilities are the probabilities of the rules, in the order of the rules.
+	Probabilities []float64
+}
+
+// Entropy returns the entropy (in bits) of the distribution of the derivations, which is the entropy of the language if
+// the grammar is unambiguous. It solves the equations `H(A) = h(A) + Σ p(A → β) Σ_{B ∈ β} H(B)`, with h(A) the entropy
+// of the choice between the rules of A.
+func (p *PCFG) Entropy() (float64, error) {
+	return p.expectation(func(probability float64) float64 {
+		if probability == 0 {
+			return 0
+		}
+		return -probability * math.Log2(probability)
+	})
+}
+
+// ExpectedDerivationLength returns the expected number of productions of a derivation. It solves the equations
+// `L(A) = 1 + Σ p(A → β) Σ_{B ∈ β} L(B)`, which only have a positive solution if ρ < 1, otherwise +Inf is returned
+// together with ErrInfiniteExpectation.
+func (p *PCFG) ExpectedDerivationLength() (float64, error) {
+	return p.expectation(func(probability float64) float64 {
+		return probability
+	})
+}
+
+// Probability returns the probability of the derivation, the product of the probabilities of its productions.
+func (p *PCFG) Probability(path Path) float64 {
+	return math.Exp(p.logProbability(path))
+}
+
+// Viterbi returns the most probable derivation of the string among the derivations found by EvaluateAll, together
+// with its probability. Derivations with a probability of zero are never returned.
+func (p *PCFG) Viterbi(s string) (Path, float64, bool) {
+	var best Path
+	bestLog := math.Inf(-1)
+	for _, path := range p.EvaluateAll(s) {
+		if l := p.logProbability(path); bestLog < l {
+			best, bestLog = path, l
+		}
+	}
+	if best == nil {
+		return nil, 0, false
+	}
+	return best, math.Exp(bestLog), true
+}
+
+// expectation solves the equations `x(A) = Σ c(p(A → β)) + Σ p(A → β) Σ_{B ∈ β} x(B)` for the variables that are
+// reachable from the start variable, and returns the value of the start variable. The equations are only solved if the
+// expected derivation length is finite, which is the case if and only if ρ < 1, otherwise +Inf is returned.
+func (p *PCFG) expectation(c func(probability float64) float64) (float64, error) {
+	reachable := p.reachable()
+	index := make(map[Variable]int)
+	for _, v := range p.Variables {
+		if _, ok := reachable[v]; ok {
+			index[v] = len(index)
+		}
+	}
+	n := len(index)
+	system := func(c func(probability float64) float64) ([][]float64, []float64) {
+		a := make([][]float64, n)
+		for i := range a {
+			a[i] = make([]float64, n)
+			a[i][i] = 1
+		}
+		b := make([]float64, n)
+		for i, rule := range p.Rules {
+			row, ok := index[rule.A.(Variable)]
+			if !ok {
+				continue
+			}
+			b[row] += c(p.Probabilities[i])
+			for _, beta := range rule.B {
+				if v, ok := beta.(Variable); ok {
+					a[row][index[v]] -= p.Probabilities[i]
+				}
+			}
+		}
+		return a, b
+	}
+	// A positive solution of the expected derivation length exists if and only if the spectral radius of the
+	// matrix of the expected numbers of variables is below 1.
+	lengths, finite := solveLinear(system(func(probability float64) float64 { return probability }))
+	for _, l := range lengths {
+		if l <= 0 || math.IsInf(l, 0) || math.IsNaN(l) {
+			finite = false
+		}
+	}
+	if !finite {
+		if p.inconsistent(index) {
+			return math.Inf(1), fmt.Errorf("%w: %w: the expected derivation length is infinite", ErrInfiniteExpectation, ErrInconsistent)
+		}
+		return math.Inf(1), fmt.Errorf("%w: the expected derivation length is infinite", ErrInfiniteExpectation)
+	}
+	x, _ := solveLinear(system(c))
+	return x[index[p.StartVariable]], nil
+}
+
+// inconsistent returns true if the grammar is known to be inconsistent, given the indices of the reachable variables:
+// a variable can not derive a string with the productions of a positive probability, or ρ > 1. The lower bound of ρ
+// is the Collatz–Wielandt bound `min_i (Mx)_i / x_i` of positive vectors x, which approach the Perron vector by power
+// iteration of M + I.
+func (p *PCFG) inconsistent(index map[Variable]int) bool {
+	n := len(index)
+	productive := make([]bool, n)
+	for changed := true; changed; {
+		changed = false
+		for i, rule := range p.Rules {
+			row, ok := index[rule.A.(Variable)]
+			if !ok || productive[row] || p.Probabilities[i] == 0 {
+				continue
+			}
+			all := true
+			for _, beta := range rule.B {
+				if v, ok := beta.(Variable); ok && !productive[index[v]] {
+					all = false
+				}
+			}
+			if all {
+				productive[row], changed = true, true
+			}
+		}
+	}
+	for _, ok := range productive {
+		if !ok {
+			return true
+		}
+	}
+	m := make([][]float64, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+	}
+	for i, rule := range p.Rules {
+		row, ok := index[rule.A.(Variable)]
+		if !ok {
+			continue
+		}
+		for _, beta := range rule.B {
+			if v, ok := beta.(Variable); ok {
+				m[row][index[v]] += p.Probabilities[i]
+			}
+		}
+	}
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1
+	}
+	for k := 0; k < 1000; k++ {
+		y := make([]float64, n)
+		lower, largest := math.Inf(1), 0.0
+		for i := range m {
+			for j, a := range m[i] {
+				y[i] += a * x[j]
+			}
+			lower = math.Min(lower, y[i]/x[i])
+			y[i] += x[i]
+			largest = math.Max(largest, y[i])
+		}
+		if 1+1e-9 < lower {
+			return true
+		}
+		for i := range y {
+			x[i] = y[i] / largest
+		}
+	}
+	return false
+}
+
+func (p *PCFG) logProbability(path Path) float64 {
+	probabilities := make(map[string]float64)
+	for i, rule := range p.Rules {
+		if _, ok := probabilities[rule.key()]; !ok {
+			probabilities[rule.key()] = p.Probabilities[i]
+		}
+	}
+	var l float64
+	for _, production := range path {
+		l += math.Log(probabilities[production.key()])
+	}
+	return l
+}
+
+// Smoothing are the options of Train that prevent productions that do not occur in the trees from getting a
+// probability of zero, which would make every derivation that contains them impossible.
+type Smoothing struct {
+	// Laplace is added to the count of every rule (additive smoothing), 1 is Laplace smoothing.
+	Laplace float64
+	// Backoff is the weight in [0, 1] of the uniform distribution over the rules of a variable, which is interpolated
+	// with the estimated distribution.
+	Backoff float64
+	// Floor is the minimum probability of a rule. The distribution of every variable is scaled to keep summing up to 1,
+	// so the floor times the number of rules of a variable must not exceed 1.
+	Floor float64
+}
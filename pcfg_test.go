@@ -0,0 +1,119 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"math"
+	"strings"
+	"testing"
+)
+
+func ExampleTrain() {
+	g, _ := cfg.Parse("S → aS | bS | ε\n")
+	trees, _ := cfg.ReadTreebank(strings.NewReader(`
+		(S a (S a (S)))
+		(S a (S))
+	`))
+	for _, smoothing := range []cfg.Smoothing{{}, {Laplace: 1}, {Floor: 0.1}} {
+		p, _ := cfg.Train(g, trees, smoothing)
+		fmt.Printf("%.2f\n", p.Probabilities)
+	}
+	// Output:
+	// [0.60 0.00 0.40]
+	// [0.50 0.12 0.38]
+	// [0.52 0.10 0.38]
+}
+
+func TestPCFG_Viterbi(t *testing.T) {
+	g, err := cfg.Parse("S → aS | bS | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees, err := cfg.ReadTreebank(strings.NewReader("(S a (S))"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := cfg.Train(g, trees, cfg.Smoothing{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := p.Viterbi("ab"); ok {
+		t.Error("expected no derivation, b was never seen")
+	}
+	if p, err = cfg.Train(g, trees, cfg.Smoothing{Backoff: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	path, probability, ok := p.Viterbi("ab")
+	if !ok {
+		t.Fatal("expected a derivation")
+	}
+	if expected := p.Probability(path); math.Abs(probability-expected) > 1e-12 {
+		t.Errorf("expected %v, got %v", expected, probability)
+	}
+
+	if _, err := cfg.Train(g, trees, cfg.Smoothing{Floor: 0.5}); err == nil {
+		t.Error("expected an error for a floor that is too high")
+	}
+	unknown, _ := cfg.ReadTreebank(strings.NewReader("(S c)"))
+	if _, err := cfg.Train(g, unknown, cfg.Smoothing{}); err == nil {
+		t.Error("expected an error for an unknown production")
+	}
+	if _, err := cfg.NewPCFG(g, []float64{0.5, 0.5, 0.5}); err == nil {
+		t.Error("expected an error for probabilities that do not sum up to 1")
+	}
+	if _, err := cfg.NewPCFG(g, []float64{math.NaN(), 0.5, 0.5}); err == nil {
+		t.Error("expected an error for a NaN probability")
+	}
+}
+
+func ExamplePCFG_Entropy() {
+	g, _ := cfg.Parse("S → aS | ε\n")
+	p, _ := cfg.NewPCFG(g, []float64{0.5, 0.5})
+	h, _ := p.Entropy()
+	l, _ := p.ExpectedDerivationLength()
+	fmt.Println(h, l)
+	// Output:
+	// 2 2
+}
+
+func TestPCFG_ExpectedDerivationLength(t *testing.T) {
+	for _, test := range []struct {
+		grammar       string
+		probabilities []float64
+		expected      float64
+	}{
+		{"S → AB\nA → a\nB → b | bB\n", []float64{1, 1, 0.75, 0.25}, 1 + 1 + 4.0/3},
+		{"S → SS | a\n", []float64{0.4, 0.6}, 5},
+		{"S → SS | a\n", []float64{0.6, 0.4}, -1},
+		{"S → aS | ε\n", []float64{1, 0}, -1},
+		// A critical grammar is consistent, but the expected derivation length is infinite.
+		{"S → SS | a\n", []float64{0.5, 0.5}, math.Inf(1)},
+		{"S → AA | a\nA → S | a\n", []float64{0.5, 0.5, 1, 0}, math.Inf(1)},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := cfg.NewPCFG(g, test.probabilities)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := p.ExpectedDerivationLength()
+		if test.expected < 0 {
+			if !errors.Is(err, cfg.ErrInconsistent) || !errors.Is(err, cfg.ErrInfiniteExpectation) || !math.IsInf(l, 1) {
+				t.Errorf("%q: expected an inconsistent grammar, got %v (%v)", test.grammar, l, err)
+			}
+			continue
+		}
+		if math.IsInf(test.expected, 1) {
+			if errors.Is(err, cfg.ErrInconsistent) || !errors.Is(err, cfg.ErrInfiniteExpectation) || !math.IsInf(l, 1) {
+				t.Errorf("%q: expected a critical grammar, got %v (%v)", test.grammar, l, err)
+			}
+			continue
+		}
+		if err != nil || 1e-9 < math.Abs(l-test.expected) {
+			t.Errorf("%q: expected %v, got %v (%v)", test.grammar, test.expected, l, err)
+		}
+	}
+}
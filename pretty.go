@@ -0,0 +1,117 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The layout hints of the gaps between the symbols of a production.
+const (
+	// LayoutNone concatenates the symbols.
+	LayoutNone LayoutHint = iota
+	// LayoutSpace separates the symbols by a space.
+	LayoutSpace
+	// LayoutNewline starts a new line at the current indentation.
+	LayoutNewline
+	// LayoutIndent increases the indentation and starts a new line.
+	LayoutIndent
+	// LayoutDedent decreases the indentation and starts a new line.
+	LayoutDedent
+)
+
+// NewPrettyPrinter returns a pretty-printer for the trees of the grammar, which indents lines with the given string.
+// Without layout hints, the terminals are concatenated as by Tree.Unparse.
+func NewPrettyPrinter(g *CFG, indent string) *PrettyPrinter {
+	return &PrettyPrinter{g: g, indent: indent, layouts: make(map[string][]LayoutHint)}
+}
+
+// LayoutHint is a hint of the whitespace between two symbols of a production.
+type LayoutHint int
+
+// PrettyPrinter converts derivation trees to formatted text, based on layout hints of the productions.
+type PrettyPrinter struct {
+	g       *CFG
+	indent  string
+	layouts map[string][]LayoutHint
+}
+
+// Layout sets the hints of the production, one for every gap between two of its symbols, e.g. `Block → { S }` with
+// LayoutIndent and LayoutDedent puts the statements on their own, indented lines.
+func (p *PrettyPrinter) Layout(production Production, hints ...LayoutHint) error {
+	var defined bool
+	for _, rule := range p.g.mappedRules[production.A] {
+		defined = defined || rule.Equal(production)
+	}
+	if !defined {
+		return fmt.Errorf("production %v not in rules", production)
+	}
+	if len(hints) != len(production.B)-1 {
+		return fmt.Errorf("expected %d hints for %v, got %d", len(production.B)-1, production, len(hints))
+	}
+	p.layouts[production.key()] = hints
+	return nil
+}
+
+// Print returns the formatted string derived by the tree, which is verified against the grammar (see Tree.Unparse).
+// Whitespace is only written between terminals: a new line takes precedence over a space, and consecutive hints do not
+// add up.
+func (p *PrettyPrinter) Print(t *Tree) (string, error) {
+	if _, err := t.Unparse(p.g); err != nil {
+		return "", err
+	}
+	s := &prettyState{}
+	p.print(t, s)
+	return s.b.String(), nil
+}
+
+func (p *PrettyPrinter) print(t *Tree, s *prettyState) {
+	if len(t.Children) == 0 {
+		if t.Symbol != Epsilon {
+			s.write(string(t.Symbol.(Terminal)), p.indent)
+		}
+		return
+	}
+	hints := p.layouts[t.Production.key()]
+	for i, c := range t.Children {
+		if 0 < i && i-1 < len(hints) {
+			s.hint(hints[i-1])
+		}
+		p.print(c, s)
+	}
+}
+
+// prettyState is the output of a pretty-printer together with the pending whitespace.
+type prettyState struct {
+	b       strings.Builder
+	level   int
+	pending LayoutHint
+}
+
+func (s *prettyState) hint(h LayoutHint) {
+	switch h {
+	case LayoutIndent:
+		s.level++
+		h = LayoutNewline
+	case LayoutDedent:
+		if 0 < s.level {
+			s.level--
+		}
+		h = LayoutNewline
+	}
+	if s.pending < h {
+		s.pending = h
+	}
+}
+
+func (s *prettyState) write(terminal, indent string) {
+	if s.b.Len() != 0 {
+		switch s.pending {
+		case LayoutSpace:
+			s.b.WriteString(" ")
+		case LayoutNewline:
+			s.b.WriteString("\n" + strings.Repeat(indent, s.level))
+		}
+	}
+	s.pending = LayoutNone
+	s.b.WriteString(terminal)
+}
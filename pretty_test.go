@@ -0,0 +1,55 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExamplePrettyPrinter() {
+	// Blocks of statements: B → {L}, L → SL | ε, S → x | B.
+	g, _ := cfg.New(
+		cfg.V{"B", "L", "S"},
+		cfg.Alphabet{"{", "}", "x;"},
+		append(append(
+			cfg.Alt("B", cfg.Betas("{", cfg.Vr("L"), "}")),
+			cfg.Alt("L", cfg.Seq(cfg.Vr("S"), cfg.Vr("L")), cfg.Seq(cfg.Epsilon))...),
+			cfg.Alt("S", cfg.Betas("x;"), cfg.Seq(cfg.Vr("B")))...,
+		),
+		"B",
+	)
+	p := cfg.NewPrettyPrinter(g, "  ")
+	_ = p.Layout(g.Rules[0], cfg.LayoutIndent, cfg.LayoutDedent)
+	_ = p.Layout(g.Rules[1], cfg.LayoutNewline)
+	path, _ := g.Evaluate("{x;{x;x;}x;}")
+	t, _ := path.Tree()
+	s, _ := p.Print(t)
+	fmt.Println(s)
+	// Output:
+	// {
+	//   x;
+	//   {
+	//     x;
+	//     x;
+	//   }
+	//   x;
+	// }
+}
+
+func TestPrettyPrinter_Layout(t *testing.T) {
+	p := cfg.NewPrettyPrinter(g, " ")
+	if err := p.Layout(g.Rules[0], cfg.LayoutSpace); err == nil {
+		t.Error("expected an error for a wrong number of hints")
+	}
+	if err := p.Layout(cfg.Prod("S → ab"), cfg.LayoutSpace); err == nil {
+		t.Error("expected an error for a production that is not in the grammar")
+	}
+	if err := p.Layout(g.Rules[0], cfg.LayoutSpace, cfg.LayoutSpace); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := g.Evaluate("abba")
+	tree, _ := path.Tree()
+	if s, err := p.Print(tree); err != nil || s != "a bb a" {
+		t.Errorf("expected %q, got %q (%v)", "a bb a", s, err)
+	}
+}
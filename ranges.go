@@ -0,0 +1,128 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// RuneRanges returns the ranges of runes derived by the variable, if every production of it is a single terminal of a
+// single rune, e.g. the productions of a character class like `[a-z]`. Such a variable is represented internally by its
+// ranges: Evaluate matches it in constant time instead of trying every production, and a Generator created by
+// NewGenerator samples uniformly from its runes.
+func (g *CFG) RuneRanges(v Variable) ([]RuneRange, bool) {
+	i, ok := g.symbols.variable[v]
+	if !ok || g.symbols.classes[i] == nil {
+		return nil, false
+	}
+	return append([]RuneRange(nil), g.symbols.classes[i].ranges...), true
+}
+
+// runeClass returns the class of the variable at the start of a sentential form, or nil if it has none or has
+// filters, which are checked on the productions.
+func (g *CFG) runeClass(s symbol) *runeClass {
+	c := g.symbols.classes[s.id]
+	if c == nil || g.filtered(s.beta.(Variable)) {
+		return nil
+	}
+	return c
+}
+
+// newRuneClass returns the class of the variable, or nil if not every production of it is a single rune. The indices
+// of the rules are in the order of the rules of the grammar, those of the alternatives in the order of evaluation.
+func newRuneClass(alternatives []internedAlternative, rules []int, all R) *runeClass {
+	if len(alternatives) < 2 {
+		return nil
+	}
+	c := &runeClass{
+		alternative: make(map[rune]int, len(alternatives)),
+		rule:        make(map[rune]int, len(alternatives)),
+	}
+	runes := make([]rune, 0, len(alternatives))
+	for i, a := range alternatives {
+		r, ok := singleRune(a.form)
+		if !ok {
+			return nil
+		}
+		if _, ok := c.alternative[r]; ok {
+			// Duplicate productions are separate derivations.
+			return nil
+		}
+		c.alternative[r] = i
+		runes = append(runes, r)
+	}
+	for _, i := range rules {
+		r, _ := utf8.DecodeRuneInString(string(all[i].B[0].(Terminal)))
+		c.rule[r] = i
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+	for _, r := range runes {
+		if n := len(c.ranges); n != 0 && c.ranges[n-1].Hi+1 == r {
+			c.ranges[n-1].Hi = r
+		} else {
+			c.ranges = append(c.ranges, RuneRange{Lo: r, Hi: r})
+		}
+		c.size++
+	}
+	return c
+}
+
+// singleRune returns the rune if the sentential form is a terminal of a single rune.
+func singleRune(form []symbol) (rune, bool) {
+	if len(form) != 1 {
+		return 0, false
+	}
+	t, ok := form[0].beta.(Terminal)
+	if !ok || utf8.RuneCountInString(string(t)) != 1 {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(string(t))
+	return r, r != utf8.RuneError
+}
+
+// RuneRange is the range of runes from Lo to Hi, both inclusive.
+type RuneRange struct {
+	Lo, Hi rune
+}
+
+// Contains returns true if the rune is part of the range.
+func (r RuneRange) Contains(c rune) bool {
+	return r.Lo <= c && c <= r.Hi
+}
+
+// String returns the range as in a character class, e.g. `a-z`.
+func (r RuneRange) String() string {
+	if r.Lo == r.Hi {
+		return string(r.Lo)
+	}
+	return fmt.Sprintf("%c-%c", r.Lo, r.Hi)
+}
+
+// runeClass is a variable of which every production is a single rune.
+type runeClass struct {
+	ranges []RuneRange
+	size   int
+	// alternative and rule are the indices of the production of every rune, in the alternatives of the variable and in
+	// the rules of the grammar.
+	alternative map[rune]int
+	rule        map[rune]int
+}
+
+// match returns the index of the alternative of the rune at the start of the string, and the length of the rune.
+func (c *runeClass) match(s string) (int, int, bool) {
+	r, n := utf8.DecodeRuneInString(s)
+	i, ok := c.alternative[r]
+	return i, n, ok && n != 0
+}
+
+// nth returns the n-th rune of the class, in the order of the ranges.
+func (c *runeClass) nth(n int) rune {
+	for _, r := range c.ranges {
+		if size := int(r.Hi-r.Lo) + 1; n < size {
+			return r.Lo + rune(n)
+		} else {
+			n -= size
+		}
+	}
+	return utf8.RuneError
+}
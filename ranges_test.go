@@ -0,0 +1,83 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_RuneRanges() {
+	g, _ := cfg.ParseW3CEBNF("Name ::= [a-z_] [a-z0-9_]*")
+	for _, v := range g.Variables {
+		if ranges, ok := g.RuneRanges(v); ok {
+			fmt.Println(v, ranges)
+		}
+	}
+	_, ok := g.Evaluate("snake_case_2")
+	fmt.Println(ok)
+	// Output:
+	// Name_1 [_ a-z]
+	// Name_2 [0-9 _ a-z]
+	// true
+}
+
+func TestCFG_RuneRanges(t *testing.T) {
+	g, err := cfg.ParseW3CEBNF("S ::= [a-z] S | [0-9] | 'xy'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.RuneRanges(g.StartVariable); ok {
+		t.Error("expected no ranges for the start variable")
+	}
+	if _, ok := g.RuneRanges("X"); ok {
+		t.Error("expected no ranges for an unknown variable")
+	}
+	for _, test := range []struct {
+		input    string
+		accepted bool
+	}{
+		{input: "abc1", accepted: true},
+		{input: "xy", accepted: true},
+		{input: "zxy", accepted: true},
+		{input: "a", accepted: false},
+		{input: "A1", accepted: false},
+		{input: "é1", accepted: false},
+	} {
+		p, ok := g.Evaluate(test.input)
+		if ok != test.accepted {
+			t.Errorf("%q: expected %v, got %v", test.input, test.accepted, ok)
+			continue
+		}
+		if ok {
+			tree, err := p.Tree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s, err := tree.Unparse(g); err != nil || s != test.input {
+				t.Errorf("%q: unexpected derivation %v", test.input, p)
+			}
+		}
+	}
+
+	g, err = cfg.ParseW3CEBNF("S ::= [a-zα-ω]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make(map[string]int)
+	gen := cfg.NewGenerator(g, 1)
+	for i := 0; i < 5000; i++ {
+		s, _, err := gen.Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[s]++
+	}
+	if len(counts) != 26+25 {
+		t.Fatalf("expected every rune to be generated, got %d", len(counts))
+	}
+	for s, n := range counts {
+		if n < 50 || 150 < n {
+			t.Errorf("expected %q to be generated about 100 times, got %d", s, n)
+		}
+	}
+}
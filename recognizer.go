@@ -0,0 +1,166 @@
+package cfg
+
+import "fmt"
+
+// Compile compiles the grammar into a Recognizer, which decides whether a string is in the language without searching
+// for a derivation. It returns an error if the grammar has disambiguation filters, see Reject.
+func (g *CFG) Compile() (*Recognizer, error) {
+	for _, v := range g.Variables {
+		if g.filtered(v) {
+			return nil, fmt.Errorf("variable %v has disambiguation filters", v)
+		}
+	}
+	t := g.symbols
+	r := &Recognizer{
+		symbols:   t,
+		start:     t.variable[g.StartVariable],
+		variables: len(t.variables),
+		predict:   make([][]recognizerRule, len(t.variables)),
+		nullable:  t.nullable(),
+	}
+	first := t.first(r.nullable)
+	for _, rule := range t.rules {
+		p := recognizerRule{dot: len(r.next), first: newBitset(len(t.terminals)), nullable: t.nullableOf(rule.b, r.nullable)}
+		t.firstOf(rule.b, first, r.nullable, p.first)
+		r.predict[rule.a] = append(r.predict[rule.a], p)
+		for _, b := range rule.b {
+			r.next = append(r.next, b)
+			r.lhs = append(r.lhs, rule.a)
+		}
+		r.next = append(r.next, r.variables)
+		r.lhs = append(r.lhs, rule.a)
+	}
+	return r, nil
+}
+
+// Recognizer is an Earley recognizer of a grammar, see CFG.Compile. The productions are laid out as a single array of
+// interned symbols, an item of the chart is an index into it. The recognizer is immutable and safe for concurrent use.
+// Unlike Evaluate it is exact, it does not depend on the order of the rules or any limits.
+type Recognizer struct {
+	symbols   *symbolTable
+	start     int
+	variables int
+	// next is the symbol after the dot of every item, a variable with ID v is encoded as v, a terminal with ID t as
+	// -t-1, the end of the production as the number of variables. lhs is the variable of the production of the item.
+	next []int
+	lhs  []int
+	// predict are the productions of every variable.
+	predict  [][]recognizerRule
+	nullable bitset
+}
+
+// Accepts returns true if the string is in the language of the grammar.
+func (r *Recognizer) Accepts(s string) bool {
+	c := &recognizerChart{
+		r: r,
+		s: s,
+		// The sets are indexed by the offset in bytes, the sets inside a multi-byte terminal stay empty.
+		sets:    make([]recognizerSet, len(s)+1),
+		seen:    make(map[recognizerItem]struct{}),
+		waiting: make(map[[2]int][]recognizerItem),
+	}
+	c.predict(0, r.start)
+	for k := range c.sets {
+		for i := 0; i < len(c.sets[k].items); i++ {
+			item := c.sets[k].items[i]
+			switch b := r.next[item.dot]; {
+			case b == r.variables:
+				// Complete: advance the items of the origin that wait for the variable.
+				if item.origin == k {
+					// The items of the set that wait for a nullable variable were already advanced.
+					continue
+				}
+				for _, waiting := range c.waiting[[2]int{item.origin, r.lhs[item.dot]}] {
+					c.add(k, waiting.dot+1, waiting.origin)
+				}
+			case b < 0:
+				// Scan: the terminal matches at the offset.
+				if n, ok := r.symbols.match(s[k:], r.symbols.terminals[-b-1]); ok {
+					c.add(k+n, item.dot+1, item.origin)
+				}
+			default:
+				key := [2]int{k, b}
+				c.waiting[key] = append(c.waiting[key], item)
+				c.predict(k, b)
+				if r.nullable.has(b) {
+					// A nullable variable is completed within the same set, before the item is added.
+					c.add(k, item.dot+1, item.origin)
+				}
+			}
+		}
+	}
+	for _, item := range c.sets[len(s)].items {
+		if item.origin == 0 && r.lhs[item.dot] == r.start && r.next[item.dot] == r.variables {
+			return true
+		}
+	}
+	return false
+}
+
+// recognizerRule is a production of a Recognizer, with its first item and the terminals it can start with.
+type recognizerRule struct {
+	dot      int
+	first    bitset
+	nullable bool
+}
+
+// recognizerItem is an item of the chart of a Recognizer: the position of the dot in the productions, and the offset
+// at which the production was predicted. The offset of the set is only part of the items of recognizerChart.seen.
+type recognizerItem struct {
+	k, dot, origin int
+}
+
+// recognizerChart is the chart of a single call to Recognizer.Accepts.
+type recognizerChart struct {
+	r    *Recognizer
+	s    string
+	sets []recognizerSet
+	seen map[recognizerItem]struct{}
+	// waiting are the items of every set that wait for a variable.
+	waiting map[[2]int][]recognizerItem
+}
+
+// recognizerSet is a set of the chart of a Recognizer.
+type recognizerSet struct {
+	items     []recognizerItem
+	predicted bitset
+	// lookahead are the terminals that match at the offset of the set, nil until a variable is predicted.
+	lookahead bitset
+}
+
+// add adds the item to the set at offset k, unless it is already part of it.
+func (c *recognizerChart) add(k, dot, origin int) {
+	item := recognizerItem{k: k, dot: dot, origin: origin}
+	if _, ok := c.seen[item]; !ok {
+		c.seen[item] = struct{}{}
+		c.sets[k].items = append(c.sets[k].items, recognizerItem{dot: dot, origin: origin})
+	}
+}
+
+// predict adds the productions of the variable to the set at offset k, once per set. Productions that can not derive
+// the empty string, and do not start with a terminal that matches at the offset, are never completed, so they are
+// skipped.
+func (c *recognizerChart) predict(k, v int) {
+	set := &c.sets[k]
+	if set.predicted == nil {
+		set.predicted = newBitset(c.r.variables)
+	}
+	if set.predicted.has(v) {
+		return
+	}
+	set.predicted.set(v)
+	if set.lookahead == nil {
+		t := c.r.symbols
+		set.lookahead = newBitset(len(t.terminals))
+		for i, a := range t.terminals {
+			if _, ok := t.match(c.s[k:], a); ok {
+				set.lookahead.set(i)
+			}
+		}
+	}
+	for _, p := range c.r.predict[v] {
+		if p.nullable || p.first.intersects(set.lookahead) {
+			c.add(k, p.dot, k)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"github.com/0x51-dev/cfg/grammars"
+	"testing"
+)
+
+func ExampleCFG_Compile() {
+	g, _ := cfg.Parse("S → SS | (S) | ε\n")
+	r, _ := g.Compile()
+	fmt.Println(r.Accepts("(()())"), r.Accepts("(()"))
+	// Output:
+	// true false
+}
+
+func TestRecognizer_Accepts(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		strings []string
+	}{
+		{"S → aSa | bSb | ε\n", []string{"", "aa", "abba", "abab", "a", "aabb"}},
+		{"S → AB\nA → aA | ε\nB → bB | ε\n", []string{"", "a", "b", "ab", "aabb", "ba"}},
+		{"S → S\\+S | S\\*S | (S) | a\n", []string{"a", "a+a*a", "(a+a", "a++a", "(a)*(a+a)"}},
+		{"S → \\p{L}S | \\p{N}\n", []string{"1", "x1", "äö9", "x", "1x"}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := g.Compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range test.strings {
+			if _, expected := g.Evaluate(s); r.Accepts(s) != expected {
+				t.Errorf("%q: expected %v", s, expected)
+			}
+		}
+	}
+
+	g, err := cfg.Parse("S → aS | a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Reject("S", []cfg.Beta{cfg.Terminal("a")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Compile(); err == nil {
+		t.Error("expected an error for a grammar with filters")
+	}
+}
+
+func TestRecognizer_concurrent(t *testing.T) {
+	for _, grammar := range grammars.All() {
+		r, err := grammar.New().Compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan bool)
+		for _, n := range sizes {
+			go func(s string) {
+				done <- r.Accepts(s)
+			}(grammar.Input(n))
+		}
+		for range sizes {
+			if !<-done {
+				t.Errorf("%s: expected the input to be accepted", grammar.Name)
+			}
+		}
+	}
+}
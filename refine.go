@@ -0,0 +1,62 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInfeasible is returned by Refine if no candidate grammar rejects a negative example.
+var ErrInfeasible = errors.New("infeasible")
+
+// Refine induces a grammar from the positive examples (see Induce) and refines it until it rejects all negative
+// examples. A negative example that is accepted by the candidate is a counterexample: the first generalized run in its
+// derivation becomes literal, and the grammar is induced again. Without generalized runs the language is exactly the
+// set of positive examples, so if a negative example is positive as well, refining is infeasible and ErrInfeasible is
+// returned. Like DiffTest, the candidates are decided exactly, here by EvaluateShortest. Every refinement step is
+// returned, also on error.
+func Refine(positives, negatives []string, opts InduceOptions) (*CFG, []Refinement, error) {
+	opts.Literal = append([]string(nil), opts.Literal...)
+	var steps []Refinement
+	for {
+		g, ind, err := induce(positives, opts)
+		if err != nil {
+			return nil, steps, err
+		}
+		units := make(map[Beta]string)
+		for b, v := range ind.repeated {
+			units[v] = ind.expansion[b]
+		}
+		var step *Refinement
+		for _, s := range negatives {
+			path, ok := g.EvaluateShortest(s)
+			if !ok {
+				continue
+			}
+			step = &Refinement{Grammar: g, Counterexample: s}
+			for _, rule := range path {
+				if unit, ok := units[rule.A.(Variable)]; ok {
+					step.Literal = unit
+					break
+				}
+			}
+			break
+		}
+		if step == nil {
+			return g, steps, nil
+		}
+		steps = append(steps, *step)
+		if step.Literal == "" {
+			return nil, steps, fmt.Errorf("%w: %q is a positive example", ErrInfeasible, step.Counterexample)
+		}
+		opts.Literal = append(opts.Literal, step.Literal)
+	}
+}
+
+// Refinement is a step of Refine.
+type Refinement struct {
+	// Grammar is the candidate that accepted the counterexample.
+	Grammar        *CFG
+	Counterexample string
+	// Literal is the repeated string that is no longer generalized by the next candidate, empty if there is none.
+	Literal string
+}
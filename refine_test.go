@@ -0,0 +1,46 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleRefine() {
+	g, steps, _ := cfg.Refine([]string{"aab", "abb"}, []string{"aaab"}, cfg.InduceOptions{Repetitions: 2})
+	for _, step := range steps {
+		fmt.Printf("%v accepts %q, %q becomes literal\n", step.Grammar.Rules, step.Counterexample, step.Literal)
+	}
+	fmt.Println(g.Rules)
+	// Output:
+	// S → R0b, S → aR1, R0 → aR0, R0 → a, R1 → bR1, R1 → b accepts "aaab", "a" becomes literal
+	// S → aab, S → aR0, R0 → bR0, R0 → b
+}
+
+func TestRefine(t *testing.T) {
+	positives := []string{"(1)", "((1))", "(1+1)", "(1+1+1)"}
+	negatives := []string{"(1", "((1)", "(1++1)", "1)"}
+	g, _, err := cfg.Refine(positives, negatives, cfg.InduceOptions{Repetitions: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range positives {
+		if _, ok := g.EvaluateShortest(s); !ok {
+			t.Errorf("expected %q to be accepted", s)
+		}
+	}
+	for _, s := range negatives {
+		if _, ok := g.EvaluateShortest(s); ok {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+
+	_, steps, err := cfg.Refine([]string{"ab", "aab"}, []string{"aaab", "ab"}, cfg.InduceOptions{Repetitions: 2})
+	if !errors.Is(err, cfg.ErrInfeasible) {
+		t.Fatalf("expected an infeasible refinement, got %v", err)
+	}
+	if len(steps) == 0 || steps[len(steps)-1].Literal != "" {
+		t.Errorf("expected the last step to have no literal, got %v", steps)
+	}
+}
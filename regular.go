@@ -0,0 +1,282 @@
+package cfg
+
+import (
+	"regexp"
+)
+
+// none matches no string at all.
+var none = regex{empty: true}
+
+// RegularApprox returns a regular expression that matches a superset of the language, using the approximation of
+// Mohri and Nederhof. Mutually recursive variables that are neither left- nor right-linear are made right-linear,
+// which loses the balance between both sides of a recursion (e.g. `S → aSb | ε` becomes `a*b*`). The expression can
+// be used as a cheap prefilter: a string that does not match it is not in the language.
+func (g *CFG) RegularApprox() *regexp.Regexp {
+	solved := make(map[Variable]regex)
+	for _, component := range g.sccs() {
+		for v, r := range g.solve(component, solved) {
+			solved[v] = r
+		}
+	}
+	return regexp.MustCompile("^" + solved[g.StartVariable].wrap(1) + "$")
+}
+
+// linearity returns whether all productions of the component are right-linear, or left-linear, in the variables of
+// the component.
+func (g *CFG) linearity(component map[Variable]bool) (right, left bool) {
+	right, left = true, true
+	for v := range component {
+		for _, rule := range g.mappedRules[v] {
+			for i, b := range rule.B {
+				if w, ok := b.(Variable); ok && component[w] {
+					right = right && i == len(rule.B)-1
+					left = left && i == 0
+				}
+			}
+		}
+	}
+	return right, left
+}
+
+// sccs returns the strongly connected components of the variables, every variable of a component can derive every
+// other variable of the component. The components are ordered such that a component only derives variables of itself
+// and the components before it.
+func (g *CFG) sccs() []V {
+	var components []V
+	index := make(map[Variable]int)
+	low := make(map[Variable]int)
+	onStack := make(map[Variable]bool)
+	var stack V
+	var visit func(v Variable)
+	visit = func(v Variable) {
+		index[v] = len(index)
+		low[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, rule := range g.RulesFor(v) {
+			for _, b := range rule.B {
+				w, ok := b.(Variable)
+				if !ok {
+					continue
+				}
+				if _, ok := index[w]; !ok {
+					visit(w)
+					if low[w] < low[v] {
+						low[v] = low[w]
+					}
+				} else if onStack[w] && index[w] < low[v] {
+					low[v] = index[w]
+				}
+			}
+		}
+		if low[v] == index[v] {
+			var component V
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(V{w}, component...)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for _, v := range g.Variables {
+		if _, ok := index[v]; !ok {
+			visit(v)
+		}
+	}
+	return components
+}
+
+// solve returns the regular expressions of the variables of the component, given the expressions of the variables of
+// the components before it.
+func (g *CFG) solve(component V, solved map[Variable]regex) map[Variable]regex {
+	inComponent := make(map[Variable]bool)
+	for _, v := range component {
+		inComponent[v] = true
+	}
+	sequence := func(bs []Beta) regex {
+		r := regex{}
+		for _, b := range bs {
+			switch b := b.(type) {
+			case Terminal:
+				r = r.concat(newRegex(b))
+			case Category:
+				// The syntax of a Unicode category is the one of the regexp package.
+				r = r.concat(regex{s: b.String(), precedence: 2})
+			case Variable:
+				r = r.concat(solved[b])
+			}
+		}
+		return r
+	}
+
+	// The equations are X = Σ coefficients[X][Y] Y + constants[X] for right-linear components, and
+	// X = Σ Y coefficients[X][Y] + constants[X] for left-linear ones.
+	variables := append(V{}, component...)
+	coefficients := make(map[Variable]map[Variable]regex)
+	constants := make(map[Variable]regex)
+	for _, v := range component {
+		coefficients[v] = make(map[Variable]regex)
+		constants[v] = none
+	}
+	add := func(a, b Variable, r regex) {
+		if c, ok := coefficients[a][b]; ok {
+			r = c.or(r)
+		}
+		coefficients[a][b] = r
+	}
+
+	right, left := g.linearity(inComponent)
+	switch {
+	case right || left:
+		for _, v := range component {
+			for _, rule := range g.mappedRules[v] {
+				var position = -1
+				for i, b := range rule.B {
+					if w, ok := b.(Variable); ok && inComponent[w] {
+						position = i
+					}
+				}
+				switch {
+				case position < 0:
+					constants[v] = constants[v].or(sequence(rule.B))
+				case right:
+					add(v, rule.B[position].(Variable), sequence(rule.B[:position]))
+				default:
+					add(v, rule.B[position].(Variable), sequence(rule.B[position+1:]))
+				}
+			}
+		}
+	default:
+		// Every variable A gets a variable A' that derives what can follow A within the component. A production
+		// `A → α0 B1 α1 … Bm αm` becomes `A → α0 B1`, `B1' → α1 B2`, …, `Bm' → αm A'`.
+		used := make(map[Variable]bool)
+		for _, v := range g.Variables {
+			used[v] = true
+		}
+		primes := make(map[Variable]Variable)
+		for _, v := range component {
+			p := fresh(string(v)+"'", used)
+			primes[v] = p
+			variables = append(variables, p)
+			coefficients[p] = make(map[Variable]regex)
+			constants[p] = regex{}
+		}
+		for _, v := range component {
+			for _, rule := range g.mappedRules[v] {
+				from, start := v, 0
+				for i, b := range rule.B {
+					if w, ok := b.(Variable); ok && inComponent[w] {
+						add(from, w, sequence(rule.B[start:i]))
+						from, start = primes[w], i+1
+					}
+				}
+				add(from, primes[v], sequence(rule.B[start:]))
+			}
+		}
+		right = true
+	}
+
+	// Gauss-Jordan elimination, using Arden's lemma to resolve self references.
+	for _, k := range variables {
+		self, ok := coefficients[k][k]
+		delete(coefficients[k], k)
+		if ok {
+			star := self.star()
+			for j, c := range coefficients[k] {
+				coefficients[k][j] = star.concatSide(c, right)
+			}
+			constants[k] = star.concatSide(constants[k], right)
+		}
+		for _, i := range variables {
+			c, ok := coefficients[i][k]
+			if i == k || !ok {
+				continue
+			}
+			delete(coefficients[i], k)
+			for j, d := range coefficients[k] {
+				add(i, j, c.concatSide(d, right))
+			}
+			constants[i] = constants[i].or(c.concatSide(constants[k], right))
+		}
+	}
+	solution := make(map[Variable]regex)
+	for _, v := range component {
+		solution[v] = constants[v]
+	}
+	return solution
+}
+
+// regex is a regular expression, the zero value matches the empty string.
+type regex struct {
+	s string
+	// empty is true if the expression does not match any string.
+	empty bool
+	// precedence is the precedence of the outermost operator: 0 for alternations, 1 for concatenations and
+	// repetitions, and 2 for single characters and groups.
+	precedence int
+}
+
+func newRegex(t Terminal) regex {
+	r := regex{s: regexp.QuoteMeta(string(t)), precedence: 2}
+	if n := len([]rune(string(t))); n != 1 {
+		r.precedence = 1
+	}
+	return r
+}
+
+func (r regex) concat(other regex) regex {
+	switch {
+	case r.empty || other.empty:
+		return none
+	case r.s == "":
+		return other
+	case other.s == "":
+		return r
+	}
+	return regex{s: r.wrap(1) + other.wrap(1), precedence: 1}
+}
+
+// concatSide concatenates the expressions, or the other way around if right is false.
+func (r regex) concatSide(other regex, right bool) regex {
+	if right {
+		return r.concat(other)
+	}
+	return other.concat(r)
+}
+
+func (r regex) or(other regex) regex {
+	switch {
+	case r.empty:
+		return other
+	case other.empty || r == other:
+		return r
+	}
+	return regex{s: r.s + "|" + other.s}
+}
+
+func (r regex) star() regex {
+	if r.empty || r.s == "" {
+		return regex{}
+	}
+	return regex{s: r.wrap(2) + "*", precedence: 1}
+}
+
+func (r regex) String() string {
+	if r.empty {
+		return `[^\x00-\x{10FFFF}]`
+	}
+	return r.s
+}
+
+// wrap returns the expression, grouped if its precedence is lower than the given one.
+func (r regex) wrap(precedence int) string {
+	if r.empty || r.s == "" || precedence <= r.precedence {
+		return r.String()
+	}
+	return "(?:" + r.s + ")"
+}
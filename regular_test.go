@@ -0,0 +1,44 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_RegularApprox() {
+	g, _ := cfg.Parse("S → aSb | ε\n")
+	fmt.Println(g.RegularApprox())
+	// Output:
+	// ^a*b*$
+}
+
+func TestCFG_RegularApprox(t *testing.T) {
+	for _, test := range []struct {
+		grammar  string
+		matched  []string
+		rejected []string
+	}{
+		{"S → aS | b\n", []string{"b", "aab"}, []string{"", "ba", "aa"}},
+		{"S → Sa | b\n", []string{"b", "baa"}, []string{"", "ab"}},
+		{"S → (S)S | ε\n", []string{"", "()", "(()())", "(()"}, []string{"x)"}},
+		{"S → AB\nA → aA | a\nB → bBc | ε\n", []string{"a", "abc", "aabbc"}, []string{"", "ba", "ca"}},
+		{"S → aS\n", nil, []string{"", "a"}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := g.RegularApprox()
+		for _, s := range test.matched {
+			if !r.MatchString(s) {
+				t.Errorf("%s: expected %q to match", r, s)
+			}
+		}
+		for _, s := range test.rejected {
+			if r.MatchString(s) {
+				t.Errorf("%s: expected %q to not match", r, s)
+			}
+		}
+	}
+}
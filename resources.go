@@ -0,0 +1,65 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrResourceLimit is returned if a grammar or an evaluation exceeds a resource limit, see ResourceLimits.
+var ErrResourceLimit = errors.New("resource limit exceeded")
+
+// WithResourceLimits bounds the resources of the grammar, for grammars from untrusted sources. New returns an error
+// wrapping ErrResourceLimit if the grammar is too large.
+func WithResourceLimits(limits ResourceLimits) Option {
+	return func(o *options) {
+		o.resources = limits
+	}
+}
+
+// EvaluateLimited is Evaluate, but it returns an error wrapping ErrResourceLimit if the search was aborted by the step
+// limit (see Limits) or the timeout (see ResourceLimits), instead of rejecting the string.
+func (g *CFG) EvaluateLimited(s string) (Path, bool, error) {
+	e := g.newEvaluation(s)
+	p, ok := g.evaluateWith(s, e)
+	g.evaluated(s, e, ok)
+	if !ok && e.err != nil {
+		return nil, false, e.err
+	}
+	return p, ok, nil
+}
+
+// ResourceLimits returns the resource limits of the grammar.
+func (g *CFG) ResourceLimits() ResourceLimits {
+	return g.resources
+}
+
+// checkSize returns an error if the rules exceed the size limits.
+func (l ResourceLimits) checkSize(rules R) error {
+	if 0 < l.Rules && l.Rules < len(rules) {
+		return fmt.Errorf("%w: %d rules, at most %d are allowed", ErrResourceLimit, len(rules), l.Rules)
+	}
+	if 0 < l.Length {
+		for _, rule := range rules {
+			if l.Length < len(rule.B) {
+				return fmt.Errorf("%w: %s has %d symbols, at most %d are allowed", ErrResourceLimit, rule.describe(), len(rule.B), l.Length)
+			}
+		}
+	}
+	return nil
+}
+
+// ResourceLimits are hard limits on the size of a grammar and the resources of its evaluation, a limit of zero
+// disables it. Unlike Limits they are not meant to tune the search, but to protect a service from grammars and inputs
+// of its users.
+type ResourceLimits struct {
+	// Rules is the maximum number of rules.
+	Rules int
+	// Length is the maximum number of symbols of the right-hand side of a rule.
+	Length int
+	// ChartItems is the maximum number of items of the charts of DerivesSentential, EarleyChart, EvaluateShortest,
+	// Derive and KBest, which reject the string if it is exceeded.
+	ChartItems int
+	// Timeout is the maximum duration of Evaluate, EvaluateAll and EvaluateLimited.
+	Timeout time.Duration
+}
@@ -0,0 +1,79 @@
+package cfg_test
+
+import (
+	"errors"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithResourceLimits(t *testing.T) {
+	S := cfg.Variable("S")
+	a, b := cfg.Terminal("a"), cfg.Terminal("b")
+	rules := cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{a, S, a}),
+		cfg.NewProduction(S, []cfg.Beta{b, S, b}),
+		cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+	}
+	for _, limits := range []cfg.ResourceLimits{{Rules: 2}, {Length: 2}} {
+		if _, err := cfg.New(cfg.V{S}, cfg.Alphabet{a, b}, rules, S, cfg.WithResourceLimits(limits)); !errors.Is(err, cfg.ErrResourceLimit) {
+			t.Errorf("%+v: expected %v, got %v", limits, cfg.ErrResourceLimit, err)
+		}
+	}
+
+	g, err := cfg.New(cfg.V{S}, cfg.Alphabet{a, b}, rules, S, cfg.WithResourceLimits(cfg.ResourceLimits{
+		Rules:      3,
+		Length:     3,
+		ChartItems: 64,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.DerivesSentential([]cfg.Beta{a, a}) || !g.Derive(S, "aa") {
+		t.Error("expected the small input to be accepted")
+	}
+	long := strings.Repeat("ab", 8) + strings.Repeat("ba", 8)
+	var form []cfg.Beta
+	for _, r := range long {
+		form = append(form, cfg.Terminal(r))
+	}
+	if g.DerivesSentential(form) || g.Derive(S, long) {
+		t.Error("expected the chart limit to reject the long input")
+	}
+}
+
+func TestResourceLimits_Timeout(t *testing.T) {
+	S := cfg.Variable("S")
+	a, b := cfg.Terminal("a"), cfg.Terminal("b")
+	// Every composition of the a's is tried before the b is rejected.
+	g, err := cfg.New(cfg.V{S}, cfg.Alphabet{a, b}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{a, S}),
+		cfg.NewProduction(S, []cfg.Beta{a, a, S}),
+		cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon}),
+	}, S, cfg.WithResourceLimits(cfg.ResourceLimits{Timeout: time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetLimits(cfg.Limits{Depth: cfg.AutoDepth})
+	if _, ok, err := g.EvaluateLimited(strings.Repeat("a", 64) + "b"); ok || !errors.Is(err, cfg.ErrResourceLimit) {
+		t.Errorf("expected the timeout to be exceeded, got %v", err)
+	}
+}
+
+func TestCFG_EvaluateLimited(t *testing.T) {
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok, err := g.EvaluateLimited("abba"); !ok || err != nil || len(p) != 3 {
+		t.Errorf("expected a derivation, got %v %v", p, err)
+	}
+	if _, ok, err := g.EvaluateLimited("ab"); ok || err != nil {
+		t.Errorf("expected a rejection, got %v", err)
+	}
+	g.SetLimits(cfg.Limits{Depth: cfg.AutoDepth, Steps: 2})
+	if _, ok, err := g.EvaluateLimited("abba"); ok || !errors.Is(err, cfg.ErrResourceLimit) {
+		t.Errorf("expected the step limit to be exceeded, got %v", err)
+	}
+}
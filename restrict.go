@@ -0,0 +1,95 @@
+package cfg
+
+import "fmt"
+
+// checkpoint marks the end of a variable in the remaining symbols of the evaluation, it is used to check the
+// disambiguation filters of the variable once the substring it derives is known.
+type checkpoint struct {
+	variable Variable
+	// start is the remaining input at the start of the variable.
+	start string
+}
+
+func (c checkpoint) String() string {
+	return fmt.Sprintf("⟨%s⟩", c.variable)
+}
+
+func (checkpoint) b() {}
+
+// FollowRestriction forbids the variable to be directly followed by any of the given terminals, e.g. an identifier
+// can not be followed by a letter, so that the longest match is used.
+func (g *CFG) FollowRestriction(v Variable, terminals ...Terminal) error {
+	if _, ok := g.symbols.variable[v]; !ok {
+		return fmt.Errorf("variable %v not in variables", v)
+	}
+	if g.followRestrictions == nil {
+		g.followRestrictions = make(map[Variable][]Terminal)
+	}
+	g.followRestrictions[v] = append(g.followRestrictions[v], terminals...)
+	g.memo.reset()
+	return nil
+}
+
+// Reject adds a reject production: a substring derived by the variable is rejected if it can also be derived by the
+// given symbols, e.g. keywords can be rejected as identifiers.
+func (g *CFG) Reject(v Variable, beta []Beta) error {
+	if _, ok := g.symbols.variable[v]; !ok {
+		return fmt.Errorf("variable %v not in variables", v)
+	}
+	for _, b := range beta {
+		switch b := b.(type) {
+		case Terminal:
+			if _, ok := g.symbols.terminal[b]; !ok {
+				return fmt.Errorf("terminal %v not in alphabet", b)
+			}
+		case Category:
+			if err := b.valid(); err != nil {
+				return err
+			}
+		case Variable:
+			if _, ok := g.symbols.variable[b]; !ok {
+				return fmt.Errorf("variable %v not in variables", b)
+			}
+		}
+	}
+	if g.rejects == nil {
+		g.rejects = make(map[Variable][][]Beta)
+	}
+	g.rejects[v] = append(g.rejects[v], beta)
+	g.memo.reset()
+	return nil
+}
+
+// Filters returns the reject productions and the follow restrictions of the variable, see Reject and
+// FollowRestriction.
+func (g *CFG) Filters(v Variable) ([][]Beta, []Terminal) {
+	var rejects [][]Beta
+	for _, beta := range g.rejects[v] {
+		rejects = append(rejects, append([]Beta(nil), beta...))
+	}
+	return rejects, append([]Terminal(nil), g.followRestrictions[v]...)
+}
+
+// allowed checks the filters of the variable, given the substring it derived and the remaining input.
+func (g *CFG) allowed(v Variable, derived, rest string, e *evaluation) bool {
+	for _, t := range g.followRestrictions[v] {
+		if _, ok := g.match(rest, t, e); ok {
+			return false
+		}
+	}
+	// The derivations of the reject productions are not derivations of the string.
+	yield := e.yield
+	defer func() { e.yield = yield }()
+	e.yield = nil
+	for _, beta := range g.rejects[v] {
+		if _, _, ok := g.evaluate(derived, g.symbols.form(beta), 0, nil, e); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// filtered returns true if the variable has any disambiguation filters.
+func (g *CFG) filtered(v Variable) bool {
+	return len(g.followRestrictions[v]) != 0 || len(g.rejects[v]) != 0
+}
@@ -0,0 +1,73 @@
+package cfg_test
+
+import (
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func TestCFG_Reject(t *testing.T) {
+	S, K, I := cfg.Variable("S"), cfg.Variable("K"), cfg.Variable("I")
+	kw := cfg.Terminal("if")
+	i, f, x := cfg.Terminal("i"), cfg.Terminal("f"), cfg.Terminal("x")
+	g, err := cfg.New(
+		[]cfg.Variable{S, K, I},
+		[]cfg.Terminal{kw, i, f, x},
+		[]cfg.Production{
+			cfg.NewProduction(S, []cfg.Beta{I}),
+			cfg.NewProduction(S, []cfg.Beta{K}),
+			cfg.NewProduction(K, []cfg.Beta{kw}),
+			cfg.NewProduction(I, []cfg.Beta{i, I}),
+			cfg.NewProduction(I, []cfg.Beta{f, I}),
+			cfg.NewProduction(I, []cfg.Beta{x, I}),
+			cfg.NewProduction(I, []cfg.Beta{i}),
+			cfg.NewProduction(I, []cfg.Beta{f}),
+			cfg.NewProduction(I, []cfg.Beta{x}),
+		},
+		S,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := g.Evaluate("if"); p[1].A != I {
+		t.Errorf("expected the keyword to be derived as identifier, got %v", p)
+	}
+	if err := g.Reject(I, []cfg.Beta{kw}); err != nil {
+		t.Fatal(err)
+	}
+	if p, ok := g.Evaluate("if"); !ok || p[1].A != K {
+		t.Errorf("expected the keyword to be derived as keyword, got %v", p)
+	}
+	for _, s := range []string{"i", "fi", "iff", "xi"} {
+		if _, ok := g.Evaluate(s); !ok {
+			t.Errorf("expected %q to be accepted", s)
+		}
+	}
+	if err := g.Reject(I, []cfg.Beta{cfg.Terminal("y")}); err == nil {
+		t.Error("expected an error for an unknown terminal")
+	}
+}
+
+func TestCFG_FollowRestriction(t *testing.T) {
+	g, err := cfg.Parse(`
+		S → IsI | II
+		I → aI | a
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Evaluate("aa"); !ok {
+		t.Error("expected \"aa\" to be accepted")
+	}
+	if err := g.FollowRestriction("I", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Evaluate("aa"); ok {
+		t.Error("expected \"aa\" to be rejected")
+	}
+	if _, ok := g.Evaluate("aasa"); !ok {
+		t.Error("expected \"aasa\" to be accepted")
+	}
+	if err := g.FollowRestriction("X", "a"); err == nil {
+		t.Error("expected an error for an unknown variable")
+	}
+}
@@ -0,0 +1,119 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// AcceptsAll returns the samples that are not in the language, in their original order, each with the reason it was
+// rejected. The samples are checked in parallel and exactly, independent of the strategy and the limits of the grammar
+// (see EvaluateShortest), which makes it suitable for assertions in tests:
+//
+//	for _, f := range g.AcceptsAll(samples) {
+//		t.Error(f)
+//	}
+func (g *CFG) AcceptsAll(samples []string) []SampleFailure {
+	return g.failures(samples, true)
+}
+
+// RejectsAll returns the samples that are in the language, in their original order, each with its derivation, see
+// AcceptsAll.
+func (g *CFG) RejectsAll(samples []string) []SampleFailure {
+	return g.failures(samples, false)
+}
+
+// SampleFailure is a sample for which the grammar did not give the expected result, see AcceptsAll.
+type SampleFailure struct {
+	// Index is the index of the sample in the samples.
+	Index  int
+	Sample string
+	// Accepted is true if the grammar accepted the sample, Path is then its derivation.
+	Accepted bool
+	Path     Path
+	// Offset is the byte offset at which a rejected sample stops being a prefix of a string of the language, the length
+	// of the sample if it ends too early.
+	Offset int
+	// Reason describes why the sample was accepted or rejected.
+	Reason string
+}
+
+func (f SampleFailure) String() string {
+	return fmt.Sprintf("sample %d %q: %s", f.Index, f.Sample, f.Reason)
+}
+
+// failures returns the samples for which the grammar does not give the expected result.
+func (g *CFG) failures(samples []string, accept bool) []SampleFailure {
+	start := chartItem{variable: g.symbols.variable[g.StartVariable], rule: -1}
+	var mu sync.Mutex
+	var failures []SampleFailure
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				input := g.input(samples[i])
+				goal := start
+				goal.j = len(input)
+				search := g.spanSearch(input, func(int) float64 { return 1 }, &goal)
+				accepted := search.final[goal]
+				if accepted == accept {
+					continue
+				}
+				f := SampleFailure{Index: i, Sample: samples[i], Accepted: accepted}
+				if accepted {
+					f.Path = g.shortestPath(goal, search.back)
+					f.Reason = fmt.Sprintf("accepted by the derivation %v", f.Path)
+				} else {
+					f.Offset, f.Reason = g.rejection(samples[i])
+				}
+				mu.Lock()
+				failures = append(failures, f)
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range samples {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	sort.Slice(failures, func(i, j int) bool { return failures[i].Index < failures[j].Index })
+	return failures
+}
+
+// rejection returns the offset at which the rejected string stops being a prefix of the language, and why. The prefixes
+// of the language are closed under taking prefixes, so the longest sequence of terminals of the string that is one of
+// them is found by a binary search.
+func (g *CFG) rejection(s string) (int, string) {
+	ts, err := g.Tokenize(s)
+	if err != nil {
+		var foreign *ForeignSymbolError
+		if errors.As(err, &foreign) {
+			return foreign.Offset, err.Error()
+		}
+		return 0, err.Error()
+	}
+	p, err := g.prefixes.get(g)
+	if err != nil {
+		return 0, "rejected"
+	}
+	offsets := make([]int, len(ts)+1)
+	for i, t := range ts {
+		n, _ := g.symbols.match(s[offsets[i]:], t)
+		offsets[i+1] = offsets[i] + n
+	}
+	// The first count of terminals that is not a prefix of the language, the empty prefix always is one.
+	k := sort.Search(len(ts)+1, func(k int) bool {
+		_, ok := p.EvaluateShortest(s[:offsets[k]])
+		return !ok
+	})
+	if len(ts) < k {
+		return len(s), "rejected, the sample ends too early"
+	}
+	return offsets[k-1], fmt.Sprintf("rejected, unexpected %v at offset %d", ts[k-1], offsets[k-1])
+}
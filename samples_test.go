@@ -0,0 +1,58 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func ExampleCFG_AcceptsAll() {
+	g, _ := cfg.Parse("S → aSb | ε\n")
+	samples := []string{"", "ab", "aab", "aba", "abc"}
+	for _, f := range g.AcceptsAll(samples) {
+		fmt.Println(f)
+	}
+	for _, f := range g.RejectsAll(samples) {
+		fmt.Println(f)
+	}
+	// Output:
+	// sample 2 "aab": rejected, the sample ends too early
+	// sample 3 "aba": rejected, unexpected a at offset 2
+	// sample 4 "abc": foreign symbol at offset 2: 'c'
+	// sample 0 "": accepted by the derivation [ S → ε ]
+	// sample 1 "ab": accepted by the derivation [ S → aSb, S → ε ]
+}
+
+func TestCFG_AcceptsAll(t *testing.T) {
+	var samples, negative []string
+	for i := 0; i < 100; i++ {
+		s := strings.Repeat("ab", i%10)
+		samples = append(samples, s+strings.Repeat("ba", i%10))
+		negative = append(negative, s+"a"+s)
+	}
+	if failures := g.AcceptsAll(samples); len(failures) != 0 {
+		t.Errorf("rejected: %v", failures)
+	}
+	if failures := g.RejectsAll(negative); len(failures) != 0 {
+		t.Errorf("accepted: %v", failures)
+	}
+	failures := g.RejectsAll(samples)
+	if len(failures) != len(samples) {
+		t.Fatalf("expected all samples to fail, got %d", len(failures))
+	}
+	for i, f := range failures {
+		if f.Index != i || f.Sample != samples[i] || !f.Accepted {
+			t.Errorf("unexpected failure %v", f)
+		}
+		if tree, err := f.Path.Tree(); err != nil {
+			t.Error(err)
+		} else if u, err := tree.Unparse(g); err != nil || u != samples[i] {
+			t.Errorf("expected a derivation of %q, got %v", samples[i], f.Path)
+		}
+	}
+	failures = g.AcceptsAll([]string{"ab\u00e4", "abbaab"})
+	if len(failures) != 2 || failures[0].Offset != 2 || failures[1].Offset != 6 || failures[1].Accepted {
+		t.Errorf("unexpected failures %v", failures)
+	}
+}
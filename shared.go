@@ -0,0 +1,201 @@
+package cfg
+
+import "fmt"
+
+// Factor moves a sub-grammar that two grammars have in common into a grammar of its own, a module that both import
+// again, see Import. The module has the variables of the first grammar and the shared variable as start variable. The
+// shared variables lose their productions in both grammars, and are renamed to the variables of the first grammar in
+// the second one.
+func Factor(g, h *CFG, s SharedGrammar) (module, first, second *CFG, err error) {
+	if len(s.Variables) == 0 || len(s.Variables) != len(s.Renamed) {
+		return nil, nil, nil, fmt.Errorf("invalid shared grammar")
+	}
+	if r, ok := shared(g, h, s.Variables[0], s.Renamed[0]); !ok || len(r.Variables) != len(s.Variables) {
+		return nil, nil, nil, fmt.Errorf("%v and %v do not derive the same sub-grammar", s.Variables[0], s.Renamed[0])
+	}
+	inShared := make(map[Variable]bool)
+	renaming := make(map[Variable]Variable)
+	for i, v := range s.Variables {
+		inShared[v] = true
+		renaming[s.Renamed[i]] = v
+	}
+
+	var rules, rest R
+	used := make(map[Terminal]bool)
+	for _, rule := range g.Rules {
+		if !inShared[rule.A.(Variable)] {
+			rest = append(rest, rule)
+			continue
+		}
+		rules = append(rules, rule)
+		for _, b := range rule.B {
+			if t, ok := b.(Terminal); ok {
+				used[t] = true
+			}
+		}
+	}
+	var alphabet Alphabet
+	for _, t := range g.Alphabet {
+		if used[t] {
+			alphabet = append(alphabet, t)
+		}
+	}
+	if module, err = New(append(V(nil), s.Variables...), alphabet, rules, s.Variables[0]); err != nil {
+		return nil, nil, nil, err
+	}
+	if first, err = New(g.Variables, g.Alphabet, rest, g.StartVariable); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rename := func(b Beta) Beta {
+		if v, ok := b.(Variable); ok {
+			if w, ok := renaming[v]; ok {
+				return w
+			}
+		}
+		return b
+	}
+	var variables V
+	for _, v := range h.Variables {
+		w := rename(v).(Variable)
+		if _, ok := renaming[v]; !ok && inShared[v] {
+			return nil, nil, nil, fmt.Errorf("variable %v of the second grammar collides with a shared variable", v)
+		}
+		variables = append(variables, w)
+	}
+	rest = nil
+	for _, rule := range h.Rules {
+		if _, ok := renaming[rule.A.(Variable)]; ok {
+			continue
+		}
+		b := make([]Beta, len(rule.B))
+		for i, beta := range rule.B {
+			b[i] = rename(beta)
+		}
+		rule.B = b
+		rest = append(rest, rule)
+	}
+	if second, err = New(variables, h.Alphabet, rest, rename(h.StartVariable).(Variable)); err != nil {
+		return nil, nil, nil, err
+	}
+	return module, first, second, nil
+}
+
+// Shared returns the sub-grammars that both grammars have in common: a variable of the first grammar and one of the
+// second grammar whose productions, and the productions of all variables they derive, are the same up to the names of
+// the variables, in the same order. Only the largest sub-grammars are returned, in the order of the variables of the
+// first grammar.
+func Shared(g, h *CFG) []SharedGrammar {
+	var found []SharedGrammar
+	for _, a := range g.Variables {
+		for _, b := range h.Variables {
+			if s, ok := shared(g, h, a, b); ok && len(g.mappedRules[a]) != 0 {
+				found = append(found, s)
+			}
+		}
+	}
+	// A sub-grammar is not returned if its variables are part of a larger one.
+	var largest []SharedGrammar
+	for i, s := range found {
+		contained := false
+		for j, t := range found {
+			if i != j && len(s.Variables) < len(t.Variables) && t.contains(s.Variables[0], s.Renamed[0]) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			largest = append(largest, s)
+		}
+	}
+	return largest
+}
+
+// shared returns the sub-grammar of a and b, if their productions are the same up to the names of the variables.
+func shared(g, h *CFG, a, b Variable) (SharedGrammar, bool) {
+	var s SharedGrammar
+	renaming := make(map[Variable]Variable)
+	inverse := make(map[Variable]Variable)
+	var match func(a, b Variable) bool
+	match = func(a, b Variable) bool {
+		if w, ok := renaming[a]; ok {
+			return w == b
+		}
+		if _, ok := inverse[b]; ok {
+			return false
+		}
+		renaming[a], inverse[b] = b, a
+		s.Variables = append(s.Variables, a)
+		s.Renamed = append(s.Renamed, b)
+		ra, rb := g.mappedRules[a], h.mappedRules[b]
+		if len(ra) != len(rb) {
+			return false
+		}
+		for i := range ra {
+			if len(ra[i].B) != len(rb[i].B) {
+				return false
+			}
+			for j, x := range ra[i].B {
+				y := rb[i].B[j]
+				v, isVariable := x.(Variable)
+				w, ok := y.(Variable)
+				if isVariable != ok || !isVariable && x != y || isVariable && !match(v, w) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return s, match(a, b)
+}
+
+// Import adds the variables, terminals and productions of the module to the grammar, e.g. of a module returned by
+// Factor. The variables of the module that are also variables of the grammar must not have productions in the
+// grammar, they refer to the productions of the module.
+func (g *CFG) Import(m *CFG) (*CFG, error) {
+	variables := append(V(nil), g.Variables...)
+	for _, v := range m.Variables {
+		if _, ok := g.symbols.variable[v]; !ok {
+			variables = append(variables, v)
+			continue
+		}
+		if len(g.mappedRules[v]) != 0 && len(m.mappedRules[v]) != 0 {
+			return nil, fmt.Errorf("variable %v is defined by both grammars", v)
+		}
+	}
+	alphabet := append(Alphabet(nil), g.Alphabet...)
+	for _, t := range m.Alphabet {
+		if _, ok := g.symbols.terminal[t]; !ok {
+			alphabet = append(alphabet, t)
+		}
+	}
+	rules := append(append(R(nil), g.Rules...), m.Rules...)
+	i, err := New(variables, alphabet, rules, g.StartVariable)
+	if err != nil {
+		return nil, err
+	}
+	i.limits = g.limits
+	i.strategy = g.strategy
+	i.fields = g.fields
+	i.logger = g.logger
+	i.metrics = g.metrics
+	i.resources = g.resources
+	return i, nil
+}
+
+// SharedGrammar is a sub-grammar that two grammars have in common, see Shared. The i-th variable of the first grammar
+// corresponds to the i-th renamed variable of the second grammar, the first ones derive all others.
+type SharedGrammar struct {
+	Variables V
+	Renamed   V
+}
+
+// contains returns true if a corresponds to b in the sub-grammar.
+func (s SharedGrammar) contains(a, b Variable) bool {
+	for i, v := range s.Variables {
+		if v == a && s.Renamed[i] == b {
+			return true
+		}
+	}
+	return false
+}
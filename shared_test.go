@@ -0,0 +1,70 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleShared() {
+	g, _ := cfg.Parse("S → \\(E\\)\nE → NE | N\nN → a | b\n")
+	h, _ := cfg.Parse("T → X\\;T | X\nX → YX | Y\nY → a | b\n")
+	shared := cfg.Shared(g, h)
+	fmt.Println(shared)
+	module, first, second, _ := cfg.Factor(g, h, shared[0])
+	fmt.Println(module.Rules)
+	fmt.Println(first.Rules)
+	fmt.Println(second.Rules)
+	// Output:
+	// [{[E N] [X Y]}]
+	// E → NE, E → N, N → a, N → b
+	// S → (E)
+	// T → E;T, T → E
+}
+
+func TestFactor(t *testing.T) {
+	g, err := cfg.Parse("S → aAb | B\nA → cA | d\nB → e\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfg.Parse("T → CC | e\nC → cC | d\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shared := cfg.Shared(g, h)
+	if fmt.Sprint(shared) != "[{[A] [C]}]" {
+		t.Fatalf("unexpected shared grammars %v", shared)
+	}
+	module, first, second, err := cfg.Factor(g, h, shared[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err = first.Import(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err = second.Import(module)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		g, h *cfg.CFG
+	}{{g, first}, {h, second}} {
+		for _, s := range []string{"adb", "accdb", "e", "dd", "cdccd", "ab", "d"} {
+			_, a := test.g.EvaluateShortest(s)
+			_, b := test.h.EvaluateShortest(s)
+			if a != b {
+				t.Errorf("%q: expected %v, got %v", s, a, b)
+			}
+		}
+	}
+	if _, err := g.Import(module); err == nil {
+		t.Error("expected an error for a variable defined by both grammars")
+	}
+	if _, _, _, err := cfg.Factor(g, h, cfg.SharedGrammar{Variables: cfg.V{"S"}, Renamed: cfg.V{"T"}}); err == nil {
+		t.Error("expected an error for variables that are not shared")
+	}
+	if shared := cfg.Shared(g, g); len(shared) != 1 || len(shared[0].Variables) != 3 {
+		t.Errorf("expected the whole grammar to be shared, got %v", shared)
+	}
+}
@@ -0,0 +1,170 @@
+package cfg
+
+import (
+	"container/heap"
+	"math"
+)
+
+// EvaluateShortest returns a leftmost derivation of the string with the fewest steps, independent of the strategy and
+// the limits of the grammar. It is Knuth's generalization of Dijkstra's algorithm over the spans of the string: the
+// cost of a variable deriving a span is one plus the costs of the symbols of the production, and spans are finalized
+// in the order of their costs, so cycles of unit and ε productions never improve a derivation.
+func (g *CFG) EvaluateShortest(s string) (Path, bool) {
+	s = g.input(s)
+	goal := chartItem{variable: g.symbols.variable[g.StartVariable], rule: -1, i: 0, j: len(s)}
+	search := g.spanSearch(s, func(int) float64 { return 1 }, &goal)
+	if !search.final[goal] {
+		return nil, false
+	}
+	return g.shortestPath(goal, search.back), true
+}
+
+// spanSearch runs Knuth's algorithm over the spans of the string, with the given cost of every rule. It stops once the
+// goal is finalized, or runs until all spans are finalized if the goal is nil. In fields mode the string must be the
+// input of g.input.
+func (g *CFG) spanSearch(s string, cost func(rule int) float64, goal *chartItem) *shortestSearch {
+	t := g.symbols
+	n := len(s)
+	e := g.newEvaluation(s)
+	search := &shortestSearch{
+		best:     make(map[chartItem]float64),
+		back:     make(map[chartItem]backpointer),
+		final:    make(map[chartItem]bool),
+		waiting:  make([][][]chartItem, len(t.variables)),
+		complete: make([][][]chartItem, len(t.variables)),
+	}
+	for v := range t.variables {
+		search.waiting[v] = make([][]chartItem, n+1)
+		search.complete[v] = make([][]chartItem, n+1)
+	}
+	for r := range t.rules {
+		for i := 0; i <= n; i++ {
+			search.push(chartItem{variable: -1, rule: r, i: i, j: i}, 0, backpointer{})
+		}
+	}
+	allowed := make(map[chartItem]bool)
+	for search.Len() != 0 {
+		entry := heap.Pop(search).(queued)
+		x, c := entry.item, entry.cost
+		if search.final[x] {
+			continue
+		}
+		search.final[x] = true
+		if goal != nil && x == *goal {
+			break
+		}
+		if limit := g.resources.ChartItems; 0 < limit && limit < len(search.best) {
+			// Nothing is derived from an incomplete chart.
+			search.final = make(map[chartItem]bool)
+			search.exceeded = true
+			break
+		}
+		if 0 <= x.variable {
+			for _, w := range search.waiting[x.variable][x.i] {
+				next := chartItem{variable: -1, rule: w.rule, dot: w.dot + 1, i: w.i, j: x.j}
+				search.push(next, search.best[w]+c, backpointer{prev: w, child: x, ok: true})
+			}
+			search.complete[x.variable][x.i] = append(search.complete[x.variable][x.i], x)
+			continue
+		}
+		r := t.rules[x.rule]
+		if x.dot == len(r.b) {
+			v := chartItem{variable: r.a, rule: -1, i: x.i, j: x.j}
+			if a := t.variables[r.a]; g.filtered(a) {
+				ok, seen := allowed[v]
+				if !seen {
+					ok = g.allowed(a, s[x.i:x.j], s[x.j:], e)
+					allowed[v] = ok
+				}
+				if !ok {
+					continue
+				}
+			}
+			search.push(v, c+cost(x.rule), backpointer{prev: x})
+			continue
+		}
+		next := chartItem{variable: -1, rule: x.rule, dot: x.dot + 1, i: x.i}
+		b := r.b[x.dot]
+		if b < 0 {
+			if m, ok := g.match(s[x.j:], t.terminals[-b-1], e); ok {
+				next.j = x.j + m
+				search.push(next, c, backpointer{prev: x})
+			}
+			continue
+		}
+		search.waiting[b][x.j] = append(search.waiting[b][x.j], x)
+		for _, y := range search.complete[b][x.j] {
+			next.j = y.j
+			search.push(next, c+search.best[y], backpointer{prev: x, child: y, ok: true})
+		}
+	}
+	return search
+}
+
+// shortestPath reconstructs the leftmost derivation of a finalized variable span from the backpointers.
+func (g *CFG) shortestPath(x chartItem, back map[chartItem]backpointer) Path {
+	complete := back[x].prev
+	var children []chartItem
+	for p := complete; p.dot != 0; p = back[p].prev {
+		if b := back[p]; b.ok {
+			children = append(children, b.child)
+		}
+	}
+	path := Path{g.Rules[complete.rule]}
+	for i := len(children) - 1; 0 <= i; i-- {
+		path = append(path, g.shortestPath(children[i], back)...)
+	}
+	return path
+}
+
+// backpointer records how an item was derived: from the previous item of the same production, and the span of the
+// variable that was matched (if ok).
+type backpointer struct {
+	prev, child chartItem
+	ok          bool
+}
+
+// chartItem is either the span (i, j) derived by a variable (with rule -1), or the span derived by the first dot
+// symbols of a rule (with variable -1).
+type chartItem struct {
+	variable  int
+	rule, dot int
+	i, j      int
+}
+
+type queued struct {
+	item chartItem
+	cost float64
+}
+
+// shortestSearch is the state of spanSearch, best are the costs of the items, it implements heap.Interface as the priority queue of the items.
+type shortestSearch struct {
+	queue []queued
+	best  map[chartItem]float64
+	back  map[chartItem]backpointer
+	final map[chartItem]bool
+	// waiting are the rule items that wait for a variable at a position, complete the spans of a variable by start.
+	waiting, complete [][][]chartItem
+	// exceeded is true if the search was aborted by the resource limits.
+	exceeded bool
+}
+
+func (s *shortestSearch) Len() int           { return len(s.queue) }
+func (s *shortestSearch) Less(i, j int) bool { return s.queue[i].cost < s.queue[j].cost }
+func (s *shortestSearch) Pop() any {
+	q := s.queue[len(s.queue)-1]
+	s.queue = s.queue[:len(s.queue)-1]
+	return q
+}
+func (s *shortestSearch) Push(x any)    { s.queue = append(s.queue, x.(queued)) }
+func (s *shortestSearch) Swap(i, j int) { s.queue[i], s.queue[j] = s.queue[j], s.queue[i] }
+
+// push queues the item if its cost is finite and improves the best known cost.
+func (s *shortestSearch) push(x chartItem, cost float64, b backpointer) {
+	if best, ok := s.best[x]; (ok && best <= cost) || s.final[x] || math.IsInf(cost, 1) {
+		return
+	}
+	s.best[x] = cost
+	s.back[x] = b
+	heap.Push(s, queued{item: x, cost: cost})
+}
@@ -0,0 +1,57 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_EvaluateShortest() {
+	g, _ := cfg.Parse("S → A | SS | a\nA → B\nB → a\n")
+	p, _ := g.Evaluate("aa")
+	fmt.Println(p)
+	p, _ = g.EvaluateShortest("aa")
+	fmt.Println(p)
+	// Output:
+	// [ S → SS, S → A, A → B, B → a, S → A, A → B, B → a ]
+	// [ S → SS, S → a, S → a ]
+}
+
+func TestCFG_EvaluateShortest(t *testing.T) {
+	// Left recursion, ε and unit cycles do not affect the search.
+	lr, err := cfg.Parse("S → SA | A | ε\nA → B | a\nB → A | b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		g        *cfg.CFG
+		s        string
+		expected int
+	}{
+		{g, "", 1},
+		{g, "abba", 3},
+		{g, "abab", -1},
+		{lr, "", 1},
+		{lr, "a", 2},
+		{lr, "ab", 5},
+		{lr, "c", -1},
+	} {
+		p, ok := test.g.EvaluateShortest(test.s)
+		if !ok {
+			if test.expected != -1 {
+				t.Errorf("%q: expected a derivation", test.s)
+			}
+			continue
+		}
+		if len(p) != test.expected {
+			t.Errorf("%q: expected %d steps, got %v", test.s, test.expected, p)
+		}
+		tree, err := p.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := tree.Unparse(test.g); err != nil || u != test.s {
+			t.Errorf("%q: invalid derivation %v", test.s, p)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package cfg
+
+import "sort"
+
+// Spans returns the spans (byte offsets [start, end)) of the string that are derived by the variable in any derivation
+// of the string, ordered by start and end, e.g. to extract all expressions of a document. All derivations are
+// considered, independent of the strategy and the limits of the grammar. If the string is not accepted, or v is not a
+// variable of the grammar, there are no spans. In fields mode (see SetFields) the offsets are those of the fields
+// separated by single spaces.
+func (g *CFG) Spans(s string, v Variable) [][2]int {
+	id, ok := g.symbols.variable[v]
+	if !ok {
+		return nil
+	}
+	var spans [][2]int
+	for x := range g.derivedItems(s) {
+		if x.variable == id {
+			spans = append(spans, [2]int{x.i, x.j})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i][0] != spans[j][0] {
+			return spans[i][0] < spans[j][0]
+		}
+		return spans[i][1] < spans[j][1]
+	})
+	return spans
+}
@@ -0,0 +1,50 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_Spans() {
+	g, _ := cfg.Parse("E → E\\+T | T\nT → (E) | x\n")
+	s := "x+(x+x)"
+	for _, span := range g.Spans(s, "T") {
+		fmt.Println(s[span[0]:span[1]])
+	}
+	// Output:
+	// x
+	// (x+x)
+	// x
+	// x
+}
+
+func TestCFG_Spans(t *testing.T) {
+	// Both derivations of the ambiguous string are considered.
+	g, err := cfg.Parse("S → SS | a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spans := fmt.Sprint(g.Spans("aaa", "S")); spans != "[[0 1] [0 2] [0 3] [1 2] [1 3] [2 3]]" {
+		t.Errorf("unexpected spans %s", spans)
+	}
+	for _, test := range []struct {
+		s string
+		v cfg.Variable
+	}{
+		{"aab", "S"},
+		{"aaa", "X"},
+	} {
+		if spans := g.Spans(test.s, test.v); spans != nil {
+			t.Errorf("%q %v: expected no spans, got %v", test.s, test.v, spans)
+		}
+	}
+	// Spans of a variable that is not part of any derivation of the string are not returned.
+	h, err := cfg.Parse("S → aA | aB\nA → b\nB → c\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spans := h.Spans("ab", "B"); len(spans) != 0 {
+		t.Errorf("expected no spans of B, got %v", spans)
+	}
+}
@@ -0,0 +1,167 @@
+package cfg
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DepthFirst tries the productions of a variable in order and backtracks, it returns the first derivation found.
+	DepthFirst Strategy = iota
+	// BreadthFirst expands all sentential forms one production at a time, it returns a derivation with the fewest
+	// steps. It needs more memory than DepthFirst, the step limit bounds the number of expanded forms.
+	BreadthFirst
+	// Brackets evaluates bracket-matching grammars (see CFG.Dyck) with a stack in linear time, independent of the
+	// limits. Other grammars, grammars with disambiguation filters, and EvaluateAll are evaluated depth-first.
+	Brackets
+	// Parallel searches the derivations of every production of the start variable depth-first in its own goroutine,
+	// and returns the same derivation as DepthFirst: once a production derives the string, the searches of the
+	// productions after it are cancelled. The step limit applies to the search of each production. EvaluateAll, and
+	// start variables of which every production is a single rune, are evaluated depth-first.
+	Parallel
+)
+
+// SetStrategy sets the search strategy of Evaluate.
+func (g *CFG) SetStrategy(strategy Strategy) {
+	g.strategy = strategy
+}
+
+// Strategy returns the search strategy of Evaluate.
+func (g *CFG) Strategy() Strategy {
+	return g.strategy
+}
+
+// evaluateBreadthFirst searches the derivations of the string in the order of their number of steps. Leading
+// terminals of a form are matched before the form is queued, so every form starts with a variable or is empty.
+func (g *CFG) evaluateBreadthFirst(s string, e *evaluation) (Path, bool) {
+	// match consumes the leading terminals and checkpoints of the form, or returns false if they do not match.
+	match := func(c candidate) (candidate, bool) {
+		for len(c.form) != 0 {
+			switch beta := c.form[0].beta.(type) {
+			case Terminal, Category:
+				n, ok := g.match(c.rest, beta, e)
+				if !ok {
+					return c, false
+				}
+				c.rest = c.rest[n:]
+				c.matched++
+			case checkpoint:
+				if !g.allowed(beta.variable, beta.start[:len(beta.start)-len(c.rest)], c.rest, e) {
+					return c, false
+				}
+			case Variable:
+				return c, true
+			}
+			c.form = c.form[1:]
+		}
+		return c, true
+	}
+
+	queue := []candidate{{rest: s, form: g.symbols.form([]Beta{g.StartVariable})}}
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if len(c.form) == 0 {
+			if c.rest != "" {
+				continue
+			}
+			if e.yield == nil {
+				return c.path, true
+			}
+			e.yield(c.path)
+			continue
+		}
+		v := c.form[0]
+		depth := v.depth + 1
+		if 0 < e.depth && e.depth < depth {
+			continue
+		}
+		for _, alternative := range g.symbols.alternatives[v.id] {
+			if !g.step(e) {
+				return nil, false
+			}
+			next := make([]symbol, 0, len(alternative.form)+1+len(c.form)-1)
+			for _, s := range alternative.form {
+				s.depth = depth
+				next = append(next, s)
+			}
+			if beta := v.beta.(Variable); g.filtered(beta) {
+				next = append(next, symbol{beta: checkpoint{variable: beta, start: c.rest}})
+			}
+			next = append(next, c.form[1:]...)
+			if 0 < g.limits.Length && g.limits.Length < c.matched+formLength(next) {
+				continue
+			}
+			if n, ok := g.minimumLength(next); !ok || len(c.rest) < n {
+				continue
+			}
+			// The paths of the queued forms share their prefix, so the path is copied on append.
+			n, ok := match(candidate{rest: c.rest, form: next, matched: c.matched, path: append(c.path[:len(c.path):len(c.path)], alternative.production)})
+			if ok {
+				queue = append(queue, n)
+			}
+		}
+	}
+	return nil, false
+}
+
+// evaluateParallel searches the derivations of the productions of the start variable concurrently, see Parallel.
+func (g *CFG) evaluateParallel(s string, e *evaluation) (Path, bool) {
+	form := g.symbols.form([]Beta{g.StartVariable})
+	alternatives := g.symbols.alternatives[form[0].id]
+	evaluations := make([]*evaluation, len(alternatives))
+	paths := make([]Path, len(alternatives))
+	cancelled := make([]atomic.Bool, len(alternatives))
+	var wg sync.WaitGroup
+	for i, alternative := range alternatives {
+		if !g.step(e) {
+			break
+		}
+		next, ok := g.expand(s, form, 0, alternative, e)
+		if !ok {
+			continue
+		}
+		evaluations[i] = &evaluation{depth: e.depth, fields: e.fields, deadline: e.deadline, cancelled: &cancelled[i]}
+		wg.Add(1)
+		go func(i int, next []symbol, p Production) {
+			defer wg.Done()
+			_, path, ok := g.evaluate(s, next, 0, Path{p}, evaluations[i])
+			if !ok {
+				return
+			}
+			paths[i] = path
+			// The derivations of the productions after this one are never returned.
+			for j := i + 1; j < len(cancelled); j++ {
+				cancelled[j].Store(true)
+			}
+		}(i, next, alternative.production)
+	}
+	wg.Wait()
+	for _, ei := range evaluations {
+		if ei != nil {
+			e.steps += ei.steps
+		}
+	}
+	for i, ei := range evaluations {
+		if paths[i] != nil {
+			return paths[i], true
+		}
+		if ei != nil && ei.err != nil {
+			// The depth-first search would have been aborted before the productions after this one.
+			e.err = ei.err
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// Strategy is the order in which Evaluate searches the derivations of a string.
+type Strategy int
+
+// candidate is a sentential form of the breadth-first search, together with the remaining string.
+type candidate struct {
+	rest    string
+	form    []symbol
+	matched int
+	path    Path
+}
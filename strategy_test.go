@@ -0,0 +1,97 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_SetStrategy() {
+	g, _ := cfg.Parse("S → A | a\nA → B\nB → a\n")
+	p, _ := g.Evaluate("a")
+	fmt.Println(p)
+	g.SetStrategy(cfg.BreadthFirst)
+	p, _ = g.Evaluate("a")
+	fmt.Println(p)
+	// Output:
+	// [ S → A, A → B, B → a ]
+	// [ S → a ]
+}
+
+func TestCFG_SetStrategy(t *testing.T) {
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetStrategy(cfg.BreadthFirst)
+	for _, test := range []struct {
+		s        string
+		expected bool
+	}{
+		{"", true},
+		{"aa", true},
+		{"abba", true},
+		{"abab", false},
+		{"a", false},
+	} {
+		p, ok := g.Evaluate(test.s)
+		if ok != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.s, test.expected, ok)
+		}
+		if !ok {
+			continue
+		}
+		tree, err := p.Tree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u, err := tree.Unparse(g); err != nil || u != test.s {
+			t.Errorf("%q: unexpected derivation %v", test.s, p)
+		}
+	}
+
+	// The derivations of an ambiguous grammar are found in the order of their length.
+	amb, err := cfg.Parse("S → SS | a | A\nA → a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	amb.SetStrategy(cfg.BreadthFirst)
+	paths := amb.EvaluateAll("aa")
+	if len(paths) == 0 {
+		t.Fatal("expected derivations")
+	}
+	for i := 1; i < len(paths); i++ {
+		if len(paths[i]) < len(paths[i-1]) {
+			t.Errorf("expected %v before %v", paths[i], paths[i-1])
+		}
+	}
+	if len(paths[0]) != 3 {
+		t.Errorf("expected a shortest derivation of 3 steps, got %v", paths[0])
+	}
+}
+
+func TestParallel(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		strings []string
+	}{
+		{"S → aSa | bSb | ε\n", []string{"", "aa", "abba", "abab", "a"}},
+		{"S → A | B | C\nA → aA | a\nB → bB | b\nC → aC | b\n", []string{"aaa", "bb", "aab", "c", ""}},
+		{"S → SS | a | A\nA → a\n", []string{"a", "aa", "aaa", "b"}},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g.Depth(cfg.AutoDepth)
+		for _, s := range test.strings {
+			g.SetStrategy(cfg.DepthFirst)
+			expected, expectedOk := g.Evaluate(s)
+			g.SetStrategy(cfg.Parallel)
+			p, ok := g.Evaluate(s)
+			if ok != expectedOk || fmt.Sprint(p) != fmt.Sprint(expected) {
+				t.Errorf("%q: expected %v %v, got %v %v", s, expected, expectedOk, p, ok)
+			}
+		}
+	}
+}
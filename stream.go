@@ -0,0 +1,175 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// ErrNotLL1 is returned by StreamRecognizer if the predictive table of the grammar has conflicts.
+	ErrNotLL1 = errors.New("not LL(1)")
+	// ErrUnexpected is returned by a StreamRecognizer for a terminal that can not follow the input read so far.
+	ErrUnexpected = errors.New("unexpected symbol")
+)
+
+// StreamRecognizer returns a recognizer for an LL(1) grammar that reads the input from a stream, see
+// StreamRecognizer.Recognize. An error wrapping ErrNotLL1 is returned if the predictive table has conflicts.
+func (g *CFG) StreamRecognizer() (*StreamRecognizer, error) {
+	table := g.PredictiveTable()
+	if conflicts := table.Conflicts(); len(conflicts) != 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNotLL1, conflicts[0])
+	}
+	t := g.symbols
+	index := g.ruleIndices()
+	r := &StreamRecognizer{
+		g:        g,
+		table:    make([][]int, len(t.variables)),
+		literals: make(map[string]int),
+	}
+	for v := range r.table {
+		cells := make([]int, len(t.terminals)+1)
+		for i := range cells {
+			cells[i] = -1
+			terminal := EndOfInput
+			if i < len(t.terminals) {
+				terminal = t.name(i)
+			}
+			if ps := table.Cells[t.variables[v]][terminal]; len(ps) != 0 {
+				cells[i] = index[ps[0].key()]
+			}
+		}
+		r.table[v] = cells
+	}
+	for i, a := range t.terminals {
+		if _, ok := a.(Category); ok {
+			r.categories = append(r.categories, i)
+			continue
+		}
+		r.literals[a.String()] = i
+		if n := utf8.RuneCountInString(a.String()); r.longest < n {
+			r.longest = n
+		}
+	}
+	return r, nil
+}
+
+// StreamRecognizer recognizes the strings of an LL(1) grammar without backtracking, see CFG.StreamRecognizer.
+type StreamRecognizer struct {
+	g *CFG
+	// table is the index of the rule of every variable and terminal, the last column is EndOfInput, -1 if there is
+	// none.
+	table [][]int
+	// literals are the IDs of the terminals that are not a Unicode category, longest the length of the longest one in
+	// runes, and categories the IDs of the other terminals.
+	literals   map[string]int
+	longest    int
+	categories []int
+}
+
+// Recognize reads the stream until its end, and returns nil if it is a string of the language. The terminals are read
+// greedily, the longest terminal is taken at every step. Only a buffer of the length of the longest terminal and the
+// stack of the predicted symbols are kept in memory, so the stream can be validated while it arrives. If the stream
+// contains a symbol that is not part of the alphabet, a *ForeignSymbolError is returned, if it contains a terminal
+// that can not follow the terminals read before, an error wrapping ErrUnexpected.
+func (r *StreamRecognizer) Recognize(in io.RuneReader) error {
+	t := r.g.symbols
+	l := &streamLexer{r: r, in: in}
+	token, err := l.next()
+	if err != nil {
+		return err
+	}
+	end := len(t.terminals)
+	// The predicted symbols, the leftmost one last. A variable with ID v is encoded as v, a terminal with ID t as -t-1.
+	stack := []int{t.variable[r.g.StartVariable]}
+	for len(stack) != 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top < 0 {
+			if -top-1 != token {
+				return l.unexpected(token)
+			}
+			if token, err = l.next(); err != nil {
+				return err
+			}
+			continue
+		}
+		i := r.table[top][token]
+		if i < 0 {
+			return l.unexpected(token)
+		}
+		b := t.rules[i].b
+		for j := len(b) - 1; 0 <= j; j-- {
+			stack = append(stack, b[j])
+		}
+	}
+	if token != end {
+		return l.unexpected(token)
+	}
+	return nil
+}
+
+// streamLexer reads the terminals of a StreamRecognizer from a stream.
+type streamLexer struct {
+	r  *StreamRecognizer
+	in io.RuneReader
+	// buffer are the runes that are read but not yet consumed, sizes their lengths in bytes.
+	buffer []rune
+	sizes  []int
+	eof    bool
+	// offset is the offset of the first rune of the buffer, start the one of the last terminal.
+	offset, start int
+}
+
+// next returns the ID of the next terminal, or the ID of EndOfInput at the end of the stream.
+func (l *streamLexer) next() (int, error) {
+	for !l.eof && (len(l.buffer) < l.r.longest || len(l.buffer) == 0) {
+		c, n, err := l.in.ReadRune()
+		if err == io.EOF {
+			l.eof = true
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		l.buffer = append(l.buffer, c)
+		l.sizes = append(l.sizes, n)
+	}
+	l.start = l.offset
+	if len(l.buffer) == 0 {
+		return len(l.r.g.symbols.terminals), nil
+	}
+	for k := len(l.buffer); 0 < k; k-- {
+		if i, ok := l.r.literals[string(l.buffer[:k])]; ok {
+			l.consume(k)
+			return i, nil
+		}
+	}
+	t := l.r.g.symbols
+	for _, i := range l.r.categories {
+		if unicode.Is(t.category(t.terminals[i].(Category)).table, l.buffer[0]) {
+			l.consume(1)
+			return i, nil
+		}
+	}
+	return 0, &ForeignSymbolError{Offset: l.offset, Symbol: l.buffer[0]}
+}
+
+func (l *streamLexer) consume(k int) {
+	for _, n := range l.sizes[:k] {
+		l.offset += n
+	}
+	l.buffer = append(l.buffer[:0], l.buffer[k:]...)
+	l.sizes = append(l.sizes[:0], l.sizes[k:]...)
+}
+
+// unexpected returns the error of an unexpected terminal.
+func (l *streamLexer) unexpected(token int) error {
+	t := l.r.g.symbols
+	if token == len(t.terminals) {
+		return fmt.Errorf("%w: end of input at offset %d", ErrUnexpected, l.start)
+	}
+	return fmt.Errorf("%w %q at offset %d", ErrUnexpected, t.name(token), l.start)
+}
@@ -0,0 +1,64 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func ExampleStreamRecognizer_Recognize() {
+	g, _ := cfg.Parse("S → \\{M\\}\nM → P\\,M | ε\nP → k\\:v\n")
+	r, _ := g.StreamRecognizer()
+	for _, s := range []string{"{}", "{k:v,k:v,}", "{k:v", "{k:x}"} {
+		fmt.Println(r.Recognize(strings.NewReader(s)))
+	}
+	// Output:
+	// <nil>
+	// <nil>
+	// unexpected symbol: end of input at offset 4
+	// foreign symbol at offset 3: 'x'
+}
+
+func TestStreamRecognizer(t *testing.T) {
+	if _, err := g.StreamRecognizer(); !errors.Is(err, cfg.ErrNotLL1) {
+		t.Errorf("expected the test grammar not to be LL(1), got %v", err)
+	}
+	// Multi-rune terminals and Unicode categories.
+	h, err := cfg.New(
+		cfg.V{"S", "L"},
+		cfg.Alphabet{"let", "=", ";"},
+		cfg.R{
+			cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Terminal("let"), cfg.Category("Ll"), cfg.Terminal("="), cfg.Variable("L"), cfg.Terminal(";"), cfg.Variable("S")}),
+			cfg.NewProduction(cfg.Variable("S"), []cfg.Beta{cfg.Epsilon}),
+			cfg.NewProduction(cfg.Variable("L"), []cfg.Beta{cfg.Category("Ll"), cfg.Variable("L")}),
+			cfg.NewProduction(cfg.Variable("L"), []cfg.Beta{cfg.Epsilon}),
+		},
+		"S",
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := h.StreamRecognizer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input string
+		err   string
+	}{
+		{input: ""},
+		{input: "letx=abc;"},
+		{input: "letä=öü;letb=;"},
+		{input: "letx=abc", err: "unexpected symbol: end of input at offset 8"},
+		{input: "letx=aB;", err: "foreign symbol at offset 6: 'B'"},
+		{input: "let=a;", err: "unexpected symbol \"=\" at offset 3"},
+		{input: "letx=a;;", err: "unexpected symbol \";\" at offset 7"},
+	} {
+		err := r.Recognize(strings.NewReader(test.input))
+		if test.err == "" && err != nil || test.err != "" && (err == nil || err.Error() != test.err) {
+			t.Errorf("%q: expected %q, got %v", test.input, test.err, err)
+		}
+	}
+}
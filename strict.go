@@ -0,0 +1,68 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrAmbiguous is returned by New in strict mode if the grammar contains an obvious source of ambiguity.
+var ErrAmbiguous = errors.New("ambiguous grammar")
+
+// WithStrict makes New reject grammars with obvious ambiguities: identical alternatives of the same variable, a
+// variable with more than one alternative that derives ε, and cycles of unit productions (`A ⇒+ A`).
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// strict checks the grammar for the ambiguities rejected by WithStrict.
+func (g *CFG) strict() error {
+	seen := make(map[string]Production)
+	for _, rule := range g.Rules {
+		if first, ok := seen[rule.key()]; ok {
+			return fmt.Errorf("%w: %s is a duplicate of %s", ErrAmbiguous, rule.describe(), first.describe())
+		}
+		seen[rule.key()] = rule
+	}
+
+	nullable := g.nullable()
+	empty := make(map[Alpha]Production)
+	for _, rule := range g.Rules {
+		if !nullableForm(rule.B, nullable) {
+			continue
+		}
+		if first, ok := empty[rule.A]; ok {
+			return fmt.Errorf("%w: both %s and %s derive ε", ErrAmbiguous, first.describe(), rule.describe())
+		}
+		empty[rule.A] = rule
+	}
+
+	closure := unitClosure(g.Rules)
+	for _, rule := range g.Rules {
+		if !isUnit(rule) {
+			continue
+		}
+		for _, v := range closure[rule.B[0].(Variable)] {
+			if v == rule.A {
+				return fmt.Errorf("%w: %s is part of a cycle of unit productions", ErrAmbiguous, rule.describe())
+			}
+		}
+	}
+	return nil
+}
+
+// nullableForm returns true if all symbols of beta derive ε.
+func nullableForm(beta []Beta, nullable map[Variable]bool) bool {
+	for _, b := range beta {
+		switch b := b.(type) {
+		case Terminal, Category:
+			return false
+		case Variable:
+			if !nullable[b] {
+				return false
+			}
+		}
+	}
+	return true
+}
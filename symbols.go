@@ -0,0 +1,471 @@
+package cfg
+
+import (
+	"sort"
+	"strings"
+)
+
+// containsBeta returns true if the symbols contain b.
+func containsBeta(beta []Beta, b Beta) bool {
+	for _, c := range beta {
+		if c == b {
+			return true
+		}
+	}
+	return false
+}
+
+// propagate adds the sets to the sets of their successors until nothing changes. The worklist starts in topological
+// order, so an acyclic graph is done after a single pass.
+func propagate(sets []bitset, edges [][]int) {
+	queue := topologicalOrder(edges)
+	queued := newBitset(len(sets))
+	for _, v := range queue {
+		queued.set(v)
+	}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		queued.unset(v)
+		for _, w := range edges[v] {
+			if sets[w].union(sets[v]) && !queued.has(w) {
+				queued.set(w)
+				queue = append(queue, w)
+			}
+		}
+	}
+}
+
+// topologicalOrder returns the reversed post-order of a depth-first search, which is a topological order if the graph
+// is acyclic.
+func topologicalOrder(edges [][]int) []int {
+	order := make([]int, len(edges))
+	n := len(edges)
+	visited := newBitset(len(edges))
+	type frame struct{ v, next int }
+	for root := range edges {
+		if visited.has(root) {
+			continue
+		}
+		visited.set(root)
+		stack := []frame{{v: root}}
+		for len(stack) != 0 {
+			f := &stack[len(stack)-1]
+			if f.next == len(edges[f.v]) {
+				n--
+				order[n] = f.v
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			w := edges[f.v][f.next]
+			f.next++
+			if !visited.has(w) {
+				visited.set(w)
+				stack = append(stack, frame{v: w})
+			}
+		}
+	}
+	return order
+}
+
+// bitset is a set of small non-negative integers.
+type bitset []uint64
+
+func newBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+func (b bitset) intersects(other bitset) bool {
+	for i := range b {
+		if b[i]&other[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+func (b bitset) unset(i int) {
+	b[i/64] &^= 1 << (uint(i) % 64)
+}
+
+// union adds all elements of the other set, returns true if any element was added.
+func (b bitset) union(other bitset) bool {
+	var changed bool
+	for i, w := range other {
+		if b[i]|w != b[i] {
+			b[i] |= w
+			changed = true
+		}
+	}
+	return changed
+}
+
+// internedAlternative is a production together with its right-hand side as symbols of a sentential form.
+type internedAlternative struct {
+	production Production
+	form       []symbol
+}
+
+// internedRule is a production with interned symbols, without ε. A variable with ID v is encoded as v, a terminal with
+// ID t as -t-1.
+type internedRule struct {
+	a int
+	b []int
+}
+
+// symbolTable interns the symbols of a grammar as dense integer IDs, in the order of the variables and the alphabet,
+// so the analyses can use bitsets instead of maps of strings. The categories of the productions are interned as
+// terminals after the alphabet.
+type symbolTable struct {
+	start     Variable
+	variables V
+	// terminals are Terminal or Category symbols.
+	terminals []Beta
+	variable  map[Variable]int
+	terminal  map[Beta]int
+	rules     []internedRule
+
+	// alternatives are the productions of the variables in the order in which they are evaluated.
+	alternatives [][]internedAlternative
+	// minLengths are the lengths (in bytes) of the shortest strings derived by the variables, -1 if a variable does not
+	// derive any string of terminals. They are used to prune the search.
+	minLengths []int
+	// shortest are the indices of the rules that derive the shortest strings of the variables, -1 if there is none.
+	shortest []int
+	// sorted are the IDs of the terminals in lexical order.
+	sorted []int
+	// producing are the indices of the rules whose right-hand side contains a symbol, see RulesProducing.
+	producing map[Beta][]int
+	// classes are the ranges of runes of the variables whose productions are single runes, nil for other variables.
+	classes []*runeClass
+	// categories are the runes of the categories, see Category.
+	categories map[Category]*category
+}
+
+func newSymbolTable(variables V, alphabet Alphabet, rules R, start Variable, mappedRules map[Alpha][]Production) *symbolTable {
+	t := &symbolTable{
+		start:    start,
+		variable: make(map[Variable]int, len(variables)),
+		terminal: make(map[Beta]int, len(alphabet)),
+		rules:    make([]internedRule, len(rules)),
+
+		producing: make(map[Beta][]int),
+	}
+	for _, v := range variables {
+		t.internVariable(v)
+	}
+	for _, a := range alphabet {
+		t.internTerminal(a)
+	}
+	for i, rule := range rules {
+		r := internedRule{a: t.internVariable(rule.A.(Variable))}
+		for j, b := range rule.B {
+			if !containsBeta(rule.B[:j], b) {
+				t.producing[b] = append(t.producing[b], i)
+			}
+			switch b := b.(type) {
+			case Terminal, Category:
+				r.b = append(r.b, -t.internTerminal(b)-1)
+			case Variable:
+				r.b = append(r.b, t.internVariable(b))
+			}
+		}
+		t.rules[i] = r
+	}
+	t.alternatives = make([][]internedAlternative, len(t.variables))
+	for i, v := range t.variables {
+		for _, p := range mappedRules[v] {
+			t.alternatives[i] = append(t.alternatives[i], internedAlternative{production: p, form: t.form(p.B)})
+		}
+	}
+	byVariable := make([][]int, len(t.variables))
+	for i, r := range t.rules {
+		byVariable[r.a] = append(byVariable[r.a], i)
+	}
+	t.classes = make([]*runeClass, len(t.variables))
+	for i := range t.variables {
+		t.classes[i] = newRuneClass(t.alternatives[i], byVariable[i], rules)
+	}
+	t.minLengths, t.shortest = t.minimumLengths()
+	t.sorted = make([]int, len(t.terminals))
+	for i := range t.sorted {
+		t.sorted[i] = i
+	}
+	sort.Slice(t.sorted, func(i, j int) bool { return t.name(t.sorted[i]) < t.name(t.sorted[j]) })
+	return t
+}
+
+// alphabet returns the terminals of the set, sorted, with the extra terminal (e.g. Epsilon or EndOfInput) if requested.
+func (t *symbolTable) alphabet(set bitset, withExtra bool, extra Terminal) Alphabet {
+	a := make(Alphabet, 0)
+	for _, i := range t.sorted {
+		if withExtra && extra < t.name(i) {
+			a = append(a, extra)
+			withExtra = false
+		}
+		if set.has(i) {
+			a = append(a, t.name(i))
+		}
+	}
+	if withExtra {
+		a = append(a, extra)
+	}
+	return a
+}
+
+// first computes the FIRST sets of the variables, without ε.
+func (t *symbolTable) first(nullable bitset) []bitset {
+	first := make([]bitset, len(t.variables))
+	for i := range first {
+		first[i] = newBitset(len(t.terminals))
+	}
+	// The FIRST set of a contains the FIRST sets of the variables at the start of its productions.
+	edges := make([][]int, len(t.variables))
+	for _, r := range t.rules {
+		for _, s := range r.b {
+			if s < 0 {
+				first[r.a].set(-s - 1)
+				break
+			}
+			edges[s] = append(edges[s], r.a)
+			if !nullable.has(s) {
+				break
+			}
+		}
+	}
+	propagate(first, edges)
+	return first
+}
+
+// firstOf adds the FIRST set of the symbols, without ε, to the set.
+func (t *symbolTable) firstOf(b []int, first []bitset, nullable bitset, set bitset) {
+	for _, s := range b {
+		if s < 0 {
+			set.set(-s - 1)
+			return
+		}
+		set.union(first[s])
+		if !nullable.has(s) {
+			return
+		}
+	}
+}
+
+// follow computes the FOLLOW sets of the variables, EndOfInput has the ID after the last terminal.
+func (t *symbolTable) follow(first []bitset, nullable bitset) []bitset {
+	end := len(t.terminals)
+	follow := make([]bitset, len(t.variables))
+	for i := range follow {
+		follow[i] = newBitset(end + 1)
+	}
+	if start, ok := t.variable[t.start]; ok {
+		follow[start].set(end)
+	}
+	// The FIRST sets are widened to the size of the FOLLOW sets.
+	wide := make([]bitset, len(first))
+	for i, f := range first {
+		wide[i] = newBitset(end + 1)
+		wide[i].union(f)
+	}
+	// The FOLLOW set of a variable contains the FOLLOW set of a if the rest of the production of a is nullable.
+	edges := make([][]int, len(t.variables))
+	for _, r := range t.rules {
+		for i, s := range r.b {
+			if s < 0 {
+				continue
+			}
+			t.firstOf(r.b[i+1:], wide, nullable, follow[s])
+			if t.nullableOf(r.b[i+1:], nullable) {
+				edges[r.a] = append(edges[r.a], s)
+			}
+		}
+	}
+	propagate(follow, edges)
+	return follow
+}
+
+// form returns the symbols as a sentential form without ε, the variables are annotated with their IDs and must be part
+// of the table.
+func (t *symbolTable) form(beta []Beta) []symbol {
+	form := make([]symbol, 0, len(beta))
+	for _, b := range beta {
+		// ε is the empty sequence of symbols.
+		if b == Epsilon {
+			continue
+		}
+		s := symbol{beta: b}
+		if v, ok := b.(Variable); ok {
+			s.id = t.variable[v]
+		}
+		form = append(form, s)
+	}
+	return form
+}
+
+// internTerminal interns a Terminal or Category.
+func (t *symbolTable) internTerminal(a Beta) int {
+	if i, ok := t.terminal[a]; ok {
+		return i
+	}
+	if c, ok := a.(Category); ok {
+		if t.categories == nil {
+			t.categories = make(map[Category]*category)
+		}
+		t.categories[c] = newCategory(c)
+	}
+	t.terminal[a] = len(t.terminals)
+	t.terminals = append(t.terminals, a)
+	return len(t.terminals) - 1
+}
+
+func (t *symbolTable) internVariable(v Variable) int {
+	if i, ok := t.variable[v]; ok {
+		return i
+	}
+	t.variable[v] = len(t.variables)
+	t.variables = append(t.variables, v)
+	return len(t.variables) - 1
+}
+
+// name returns the terminal of the ID, the terminal `\p{L}` for a category in sets of terminals, e.g. FIRST sets.
+func (t *symbolTable) name(i int) Terminal {
+	if a, ok := t.terminals[i].(Terminal); ok {
+		return a
+	}
+	return Terminal(t.terminals[i].String())
+}
+
+// length returns the length (in bytes) of the shortest string matched by the terminal or category.
+func (t *symbolTable) length(a Beta) int {
+	switch a := a.(type) {
+	case Terminal:
+		return len(a)
+	case Category:
+		return t.category(a).minLength
+	}
+	return 0
+}
+
+// match returns the length of the prefix of the string that is matched by the terminal or category.
+func (t *symbolTable) match(s string, a Beta) (int, bool) {
+	switch a := a.(type) {
+	case Terminal:
+		if strings.HasPrefix(s, string(a)) {
+			return len(a), true
+		}
+	case Category:
+		return t.category(a).match(s)
+	}
+	return 0, false
+}
+
+// category returns the runes of the category, also if it is not part of the grammar, e.g. of a reject production.
+func (t *symbolTable) category(c Category) *category {
+	if r, ok := t.categories[c]; ok {
+		return r
+	}
+	return newCategory(c)
+}
+
+// minimumLengths computes the length (in bytes) of the shortest string derived by every variable, together with the
+// rule of the variable that derives it, -1 if there is none. A production is only reconsidered if the length of one of
+// its variables got shorter. Since a rule is only recorded if it is strictly shorter, always applying the recorded
+// rules terminates.
+func (t *symbolTable) minimumLengths() ([]int, []int) {
+	lengths := make([]int, len(t.variables))
+	shortest := make([]int, len(t.variables))
+	for i := range lengths {
+		lengths[i] = -1
+		shortest[i] = -1
+	}
+	occurrences := make([][]int, len(t.variables))
+	queue := make([]int, len(t.rules))
+	for i, r := range t.rules {
+		queue[i] = i
+		for _, s := range r.b {
+			if 0 <= s {
+				occurrences[s] = append(occurrences[s], i)
+			}
+		}
+	}
+	for len(queue) != 0 {
+		i := queue[0]
+		r := t.rules[i]
+		queue = queue[1:]
+		var n int
+		for _, s := range r.b {
+			if s < 0 {
+				n += t.length(t.terminals[-s-1])
+				continue
+			}
+			if lengths[s] < 0 {
+				n = -1
+				break
+			}
+			n += lengths[s]
+		}
+		if 0 <= n && (lengths[r.a] < 0 || n < lengths[r.a]) {
+			lengths[r.a], shortest[r.a] = n, i
+			queue = append(queue, occurrences[r.a]...)
+		}
+	}
+	return lengths, shortest
+}
+
+// nullable computes the nullable variables in linear time: every production counts its symbols that are not known to
+// be nullable, a variable becomes nullable once the count of any of its productions drops to zero.
+func (t *symbolTable) nullable() bitset {
+	nullable := newBitset(len(t.variables))
+	remaining := make([]int, len(t.rules))
+	// occurrences are the productions in which a variable occurs, once per occurrence.
+	occurrences := make([][]int, len(t.variables))
+	var queue []int
+	for i, r := range t.rules {
+		remaining[i] = len(r.b)
+		for _, s := range r.b {
+			if s < 0 {
+				// A production with a terminal is never nullable.
+				remaining[i] = -1
+				break
+			}
+		}
+		if remaining[i] < 0 {
+			continue
+		}
+		for _, s := range r.b {
+			occurrences[s] = append(occurrences[s], i)
+		}
+		if remaining[i] == 0 && !nullable.has(r.a) {
+			nullable.set(r.a)
+			queue = append(queue, r.a)
+		}
+	}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, i := range occurrences[v] {
+			if remaining[i]--; remaining[i] == 0 && !nullable.has(t.rules[i].a) {
+				nullable.set(t.rules[i].a)
+				queue = append(queue, t.rules[i].a)
+			}
+		}
+	}
+	return nullable
+}
+
+func (t *symbolTable) nullableOf(b []int, nullable bitset) bool {
+	for _, s := range b {
+		if s < 0 || !nullable.has(s) {
+			return false
+		}
+	}
+	return true
+}
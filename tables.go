@@ -0,0 +1,163 @@
+package cfg
+
+import (
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrStaleTables is returned if stored tables were computed for a different grammar.
+var ErrStaleTables = errors.New("tables of a different grammar")
+
+// ReadTables decodes tables written by Tables.Write, without checking the grammar they belong to, see UseTables.
+func ReadTables(r io.Reader) (*Tables, error) {
+	t := new(Tables)
+	if err := gob.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// CachedTables returns the tables of the grammar from the directory, in a file named after the hash of the grammar. If
+// the file does not exist the tables are computed and written to it. The tables are used by the grammar afterwards.
+func (g *CFG) CachedTables(dir string) (*Tables, error) {
+	path := filepath.Join(dir, g.Hash()+".tables")
+	f, err := os.Open(path)
+	if err == nil {
+		defer f.Close()
+		t, err := ReadTables(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if err := g.UseTables(t); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return t, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	t := g.Tables()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	// The tables are written to a temporary file first, so a concurrent reader never sees a partial file.
+	tmp, err := os.CreateTemp(dir, ".tables-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	if err := t.Write(tmp); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	g.tables = t.clone()
+	return t, nil
+}
+
+// Tables computes the analyses of the grammar that are worth storing: the nullable variables, the FIRST and FOLLOW
+// sets, the predictive table and the LR(0) automaton.
+func (g *CFG) Tables() *Tables {
+	if g.tables != nil {
+		return g.tables.clone()
+	}
+	return &Tables{
+		Hash:       g.Hash(),
+		Nullable:   g.Nullable(),
+		First:      g.First(),
+		Follow:     g.Follow(),
+		Predictive: g.PredictiveTable(),
+		LR0:        g.LR0(),
+	}
+}
+
+// UseTables makes the grammar return the stored tables from Nullable, First, Follow, PredictiveTable and LR0 instead of
+// computing them. It returns an error wrapping ErrStaleTables if they were computed for a different grammar. The
+// grammar keeps a copy of the tables, and returns copies of them, so neither side can change the tables of the other.
+func (g *CFG) UseTables(t *Tables) error {
+	if hash := g.Hash(); t.Hash != hash {
+		return fmt.Errorf("%w: expected %s, got %s", ErrStaleTables, hash, t.Hash)
+	}
+	g.tables = t.clone()
+	return nil
+}
+
+// Tables are the analyses of a grammar, see CFG.Tables. They are keyed by the hash of the grammar (see CFG.Hash).
+type Tables struct {
+	Hash       string
+	Nullable   V
+	First      map[Variable]Alphabet
+	Follow     map[Variable]Alphabet
+	Predictive *PredictiveTable
+	LR0        *LR0
+}
+
+// Write encodes the tables with gob.
+func (t *Tables) Write(w io.Writer) error {
+	return gob.NewEncoder(w).Encode(t)
+}
+
+func (t *Tables) clone() *Tables {
+	return &Tables{
+		Hash:       t.Hash,
+		Nullable:   append(V(nil), t.Nullable...),
+		First:      copySets(t.First),
+		Follow:     copySets(t.Follow),
+		Predictive: t.Predictive.clone(),
+		LR0:        t.LR0.clone(),
+	}
+}
+
+// copySets returns a copy of the FIRST or FOLLOW sets.
+func copySets(sets map[Variable]Alphabet) map[Variable]Alphabet {
+	c := make(map[Variable]Alphabet, len(sets))
+	for v, a := range sets {
+		c[v] = append(Alphabet(nil), a...)
+	}
+	return c
+}
+
+func (t *PredictiveTable) clone() *PredictiveTable {
+	if t == nil {
+		return nil
+	}
+	c := &PredictiveTable{
+		Variables: append(V(nil), t.Variables...),
+		Terminals: append(Alphabet(nil), t.Terminals...),
+		Cells:     make(map[Variable]map[Terminal][]Production, len(t.Cells)),
+	}
+	for v, row := range t.Cells {
+		c.Cells[v] = make(map[Terminal][]Production, len(row))
+		for a, ps := range row {
+			c.Cells[v][a] = append([]Production(nil), ps...)
+		}
+	}
+	return c
+}
+
+func (a *LR0) clone() *LR0 {
+	if a == nil {
+		return nil
+	}
+	c := &LR0{States: make([][]Item, len(a.States)), Transitions: make([]map[Beta]int, len(a.Transitions))}
+	for i, items := range a.States {
+		c.States[i] = append([]Item(nil), items...)
+	}
+	for i, ts := range a.Transitions {
+		c.Transitions[i] = make(map[Beta]int, len(ts))
+		for b, j := range ts {
+			c.Transitions[i][b] = j
+		}
+	}
+	return c
+}
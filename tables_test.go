@@ -0,0 +1,105 @@
+package cfg_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/0x51-dev/cfg"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCFG_Tables(t *testing.T) {
+	g, err := cfg.Parse("S → AB | c\nA → aA | ε\nB → bB | d\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := g.Tables()
+	var b bytes.Buffer
+	if err := tables.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := cfg.ReadTables(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfg.Parse("S → AB | c\nA → aA | ε\nB → bB | d\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.UseTables(stored); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g.First(), h.First()) || !reflect.DeepEqual(g.Follow(), h.Follow()) || !reflect.DeepEqual(g.Nullable(), h.Nullable()) {
+		t.Error("expected the same sets")
+	}
+	if !reflect.DeepEqual(g.PredictiveTable().Conflicts(), h.PredictiveTable().Conflicts()) || len(g.LR0().States) != len(h.LR0().States) {
+		t.Error("expected the same tables")
+	}
+
+	other, err := cfg.Parse("S → a\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := other.UseTables(stored); !errors.Is(err, cfg.ErrStaleTables) {
+		t.Errorf("expected %v, got %v", cfg.ErrStaleTables, err)
+	}
+}
+
+func TestCFG_UseTables_copies(t *testing.T) {
+	g, err := cfg.Parse("S → AB | c\nA → aA | ε\nB → bB | d\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := g.Tables()
+	if err := g.UseTables(tables); err != nil {
+		t.Fatal(err)
+	}
+	first, follow, nullable := g.First(), g.Follow(), g.Nullable()
+	table, lr0 := g.PredictiveTable(), g.LR0()
+
+	// Neither the results nor the tables that were passed to UseTables share memory with the stored tables.
+	g.First()["S"][0] = "x"
+	delete(g.Follow(), "A")
+	g.Nullable()[0] = "B"
+	delete(g.PredictiveTable().Cells, "S")
+	g.LR0().States[0][0].Dot = 42
+	tables.First["S"] = nil
+	tables.LR0.Transitions[0] = nil
+	if !reflect.DeepEqual(first, g.First()) || !reflect.DeepEqual(follow, g.Follow()) || !reflect.DeepEqual(nullable, g.Nullable()) {
+		t.Error("expected the sets to be unchanged")
+	}
+	if !reflect.DeepEqual(table, g.PredictiveTable()) || !reflect.DeepEqual(lr0, g.LR0()) {
+		t.Error("expected the tables to be unchanged")
+	}
+}
+
+func TestCFG_CachedTables(t *testing.T) {
+	dir := t.TempDir()
+	g, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	computed, err := g.CachedTables(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, g.Hash()+".tables")); err != nil {
+		t.Fatal(err)
+	}
+	h, err := cfg.Parse("S → aSa | bSb | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := h.CachedTables(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(computed.First, loaded.First) || !reflect.DeepEqual(h.Follow(), g.Follow()) {
+		t.Error("expected the same tables")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected a single file, got %d", len(entries))
+	}
+}
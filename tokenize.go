@@ -0,0 +1,78 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrForeignSymbol is returned if the input contains a symbol that is not part of the alphabet of the grammar.
+var ErrForeignSymbol = errors.New("foreign symbol")
+
+// CheckAlphabet returns a *ForeignSymbolError if the string can not be written as a sequence of terminals of the
+// alphabet. Evaluate rejects such a string without telling why, so this can be used to report the offending symbol
+// before evaluating.
+func (g *CFG) CheckAlphabet(s string) error {
+	_, err := g.Tokenize(s)
+	return err
+}
+
+// Tokenize splits the string into terminals of the alphabet. The split is chosen from the end of the string, preferring
+// the longest terminal at every step. A rune matched by a category of the productions is a terminal of its own. If the
+// string can not be split, a *ForeignSymbolError at the furthest offset that can be reached is returned.
+func (g *CFG) Tokenize(s string) ([]Terminal, error) {
+	// last is the terminal that ends at an offset, or -1 if the offset can not be reached, and size the length of the
+	// string it matched.
+	last := make([]int, len(s)+1)
+	size := make([]int, len(s)+1)
+	for i := range last {
+		last[i] = -1
+	}
+	furthest := 0
+	for i := 0; i < len(s); i++ {
+		if i != 0 && last[i] < 0 {
+			continue
+		}
+		furthest = i
+		for j, t := range g.symbols.terminals {
+			n, ok := g.symbols.match(s[i:], t)
+			if !ok || n == 0 {
+				continue
+			}
+			if last[i+n] < 0 || size[i+n] < n {
+				last[i+n], size[i+n] = j, n
+			}
+		}
+	}
+	if len(s) != 0 && last[len(s)] < 0 {
+		r, _ := utf8.DecodeRuneInString(s[furthest:])
+		return nil, &ForeignSymbolError{Offset: furthest, Symbol: r}
+	}
+	var ts []Terminal
+	for i := len(s); i != 0; i -= size[i] {
+		t, ok := g.symbols.terminals[last[i]].(Terminal)
+		if !ok {
+			t = Terminal(s[i-size[i] : i])
+		}
+		ts = append(ts, t)
+	}
+	for i, j := 0, len(ts)-1; i < j; i, j = i+1, j-1 {
+		ts[i], ts[j] = ts[j], ts[i]
+	}
+	return ts, nil
+}
+
+// ForeignSymbolError is the position of a symbol that is not part of the alphabet.
+type ForeignSymbolError struct {
+	// Offset is the byte offset of the symbol in the input.
+	Offset int
+	Symbol rune
+}
+
+func (e *ForeignSymbolError) Error() string {
+	return fmt.Sprintf("%v at offset %d: %q", ErrForeignSymbol, e.Offset, e.Symbol)
+}
+
+func (e *ForeignSymbolError) Unwrap() error {
+	return ErrForeignSymbol
+}
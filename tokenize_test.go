@@ -0,0 +1,59 @@
+package cfg_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_CheckAlphabet() {
+	fmt.Println(g.CheckAlphabet("abba"))
+	fmt.Println(g.CheckAlphabet("abxba"))
+	// Output:
+	// <nil>
+	// foreign symbol at offset 2: 'x'
+}
+
+func TestCFG_Tokenize(t *testing.T) {
+	S := cfg.Variable("S")
+	alphabet := cfg.Alphabet{"a", "ab", "bc", "c"}
+	rules := cfg.R{cfg.NewProduction(S, []cfg.Beta{cfg.Epsilon})}
+	for _, a := range alphabet {
+		rules = append(rules, cfg.NewProduction(S, []cfg.Beta{a, S}))
+	}
+	g, err := cfg.New(cfg.V{S}, alphabet, rules, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		input  string
+		tokens string
+		offset int
+	}{
+		{"", "[]", -1},
+		// Both "ab c" and "a bc" are possible, the longer terminal at the end is preferred.
+		{"abc", "[a bc]", -1},
+		{"abbc", "[ab bc]", -1},
+		{"abca", "[a bc a]", -1},
+		{"abcx", "", 3},
+		{"abbd", "", 2},
+		{"é", "", 0},
+	} {
+		ts, err := g.Tokenize(test.input)
+		if test.offset < 0 {
+			if err != nil {
+				t.Errorf("%q: %v", test.input, err)
+			} else if s := fmt.Sprint(ts); s != test.tokens {
+				t.Errorf("%q: expected %s, got %s", test.input, test.tokens, s)
+			}
+			continue
+		}
+		var foreign *cfg.ForeignSymbolError
+		if !errors.As(err, &foreign) || !errors.Is(err, cfg.ErrForeignSymbol) {
+			t.Errorf("%q: expected a foreign symbol, got %v", test.input, err)
+		} else if foreign.Offset != test.offset {
+			t.Errorf("%q: expected offset %d, got %d", test.input, test.offset, foreign.Offset)
+		}
+	}
+}
@@ -0,0 +1,646 @@
+package cfg
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	// RemoveEpsilon removes the ε-productions: every production is replaced by all combinations of omitting its
+	// nullable variables. The empty string is no longer part of the language.
+	RemoveEpsilon = Transform{Name: "RemoveEpsilon", apply: removeEpsilon}
+	// RemoveUnits removes the unit productions `A → B`: every variable gets the other productions of all variables in
+	// its unit closure. The rules are sorted afterwards.
+	RemoveUnits = Transform{Name: "RemoveUnits", apply: removeUnits}
+	// Binarize replaces the productions with more than two symbols, e.g. `S → ABCD` becomes `S → AV0`, `V0 → BV1` and
+	// `V1 → CD`. The fresh variables of a suffix of the right-hand side are shared by all productions ending with it.
+	Binarize = Transform{Name: "Binarize", apply: binarize}
+	// LiftTerminals replaces the terminals of productions with more than one symbol by variables. A variable whose
+	// only production is the terminal is reused, otherwise a fresh variable `T<i>` is introduced for the i-th
+	// terminal of the alphabet, with primes appended if the grammar already uses the name.
+	LiftTerminals = Transform{Name: "LiftTerminals", apply: liftTerminals}
+	// LiftTokenClasses replaces the terminals of every class of TokenClasses by a fresh variable `C<i>` that derives
+	// the terminals of the i-th class, and removes the productions that became duplicates. The language does not change.
+	LiftTokenClasses = Transform{Name: "LiftTokenClasses", apply: liftTokenClasses}
+)
+
+// Transform applies the transforms in order, every one of them to the grammar returned by the previous one. The
+// grammar itself is not changed. Next to the resulting grammar, a report of the changes of every transform is
+// returned. For example, CNF is the pipeline of RemoveEpsilon, RemoveUnits, Binarize and LiftTerminals.
+func (g *CFG) Transform(transforms ...Transform) (*CFG, []TransformReport, error) {
+	original, past, derived := g, []TransformReport(nil), []*CFG(nil)
+	if g.history != nil {
+		original, past, derived = g.history.Original, g.history.Reports, g.history.derived
+	}
+	var reports []TransformReport
+	for _, t := range transforms {
+		rules, fresh, origins := t.apply(g)
+		variables := append(append(V(nil), g.Variables...), fresh...)
+		h, err := New(variables, g.Alphabet, rules, g.StartVariable)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", t.Name, err)
+		}
+		h.limits = g.limits
+		h.strategy = g.strategy
+		h.fields = g.fields
+		h.logger = g.logger
+		h.metrics = g.metrics
+		h.resources = g.resources
+		report := newTransformReport(t.Name, g.Rules, rules, fresh, origins)
+		g.debug("transformed grammar", "transform", t.Name, "added", len(report.Added), "removed", len(report.Removed), "rules", len(rules))
+		reports = append(reports, report)
+		past = append(past[:len(past):len(past)], report)
+		derived = append(derived[:len(derived):len(derived)], h)
+		h.history = &History{Original: original, Reports: past, derived: derived}
+		g = h
+	}
+	return g, reports, nil
+}
+
+// TokenClasses partitions the terminals used in the productions by the contexts in which they appear, and returns the
+// classes with more than one terminal, in the order of the alphabet. The terminals of a class are interchangeable: for
+// every production with one of them, the same production with any other one of them instead exists as well. This is
+// common for grammars generated from examples or logs, e.g. with a production for every digit.
+func (g *CFG) TokenClasses() []Alphabet {
+	contexts := make(map[Terminal]map[string]bool)
+	for _, rule := range g.Rules {
+		for i, b := range rule.B {
+			t, ok := b.(Terminal)
+			if !ok {
+				continue
+			}
+			// The context is the production with a hole instead of the terminal.
+			c := formKey([]Beta{rule.A.(Variable)}) + formKey(rule.B[:i]) + "\x01" + formKey(rule.B[i+1:])
+			if contexts[t] == nil {
+				contexts[t] = make(map[string]bool)
+			}
+			contexts[t][c] = true
+		}
+	}
+	var classes []Alphabet
+	index := make(map[string]int)
+	for _, t := range g.Alphabet {
+		if contexts[t] == nil {
+			continue
+		}
+		var c []string
+		for context := range contexts[t] {
+			c = append(c, context)
+		}
+		sort.Strings(c)
+		key := strings.Join(c, "\x02")
+		if i, ok := index[key]; ok {
+			classes[i] = append(classes[i], t)
+		} else {
+			index[key] = len(classes)
+			classes = append(classes, Alphabet{t})
+		}
+	}
+	var partition []Alphabet
+	for _, class := range classes {
+		if 1 < len(class) {
+			partition = append(partition, class)
+		}
+	}
+	return partition
+}
+
+// History returns how the grammar was derived by Transform, or nil if it was not.
+func (g *CFG) History() *History {
+	return g.history
+}
+
+func binarize(g *CFG) (R, V, []int) {
+	used, index := g.names(), 0
+	var binary R
+	var fresh V
+	var origins []int
+	suffixes := make(map[string]Variable)
+	for i, rule := range g.Rules {
+		p := rule // The first production keeps the metadata of the original rule.
+		for offset := 0; 2 < len(p.B); offset++ {
+			r := p.B
+			// The capture names of a suffix are part of the production of its variable.
+			suffix := make([]int, len(r)-1)
+			for j := range suffix {
+				suffix[j] = offset + 1 + j
+			}
+			captures := rule.capturesAt(suffix)
+			key := formKey(r[1:]) + "\x00" + strings.Join(captures, "\x00")
+			if v, ok := suffixes[key]; ok {
+				p.B, p.Captures = []Beta{r[0], v}, rule.capturesAt([]int{offset})
+				break
+			}
+			v := numbered("V", &index, used)
+			fresh = append(fresh, v)
+			suffixes[key] = v
+			p.B, p.Captures = []Beta{r[0], v}, rule.capturesAt([]int{offset})
+			binary = append(binary, p)
+			origins = append(origins, i)
+			p = NewProduction(v, r[1:])
+			p.Captures = captures
+		}
+		binary = append(binary, p)
+		origins = append(origins, i)
+	}
+	return binary, fresh, origins
+}
+
+func liftTerminals(g *CFG) (R, V, []int) {
+	count := make(map[string]int)
+	for _, rule := range g.Rules {
+		count[rule.A.String()]++
+	}
+	// Categories are lifted like terminals.
+	lifted := make(map[Beta]Variable)
+	for _, rule := range g.Rules {
+		if len(rule.B) != 1 || count[rule.A.String()] != 1 {
+			continue
+		}
+		if t := rule.B[0]; isTerminal(t) {
+			if _, ok := lifted[t]; !ok {
+				lifted[t] = rule.A.(Variable)
+			}
+		}
+	}
+	used := g.names()
+	var rules R
+	var variables V
+	var origins, terminalOrigins []int
+	var terminals []Production
+	for i, rule := range g.Rules {
+		origins = append(origins, i)
+		if len(rule.B) < 2 {
+			rules = append(rules, rule)
+			continue
+		}
+		b := make([]Beta, len(rule.B))
+		for j, beta := range rule.B {
+			if isTerminal(beta) {
+				v, ok := lifted[beta]
+				if !ok {
+					v = fresh(fmt.Sprintf("T%d", g.symbols.terminal[beta]), used)
+					variables = append(variables, v)
+					lifted[beta] = v
+					terminals = append(terminals, NewProduction(v, []Beta{beta}))
+					terminalOrigins = append(terminalOrigins, i)
+				}
+				beta = v
+			}
+			b[j] = beta
+		}
+		rule.B = b
+		rules = append(rules, rule)
+	}
+	return append(rules, terminals...), variables, append(origins, terminalOrigins...)
+}
+
+func liftTokenClasses(g *CFG) (R, V, []int) {
+	class := make(map[Terminal]Variable)
+	var fresh V
+	var terminals R
+	used, i := g.names(), 0
+	for _, c := range g.TokenClasses() {
+		v := numbered("C", &i, used)
+		fresh = append(fresh, v)
+		for _, t := range c {
+			class[t] = v
+			terminals = append(terminals, NewProduction(v, []Beta{t}))
+		}
+	}
+	var rules R
+	var origins []int
+	first := make(map[Variable]int)
+	unique := make(map[string]bool)
+	for i, rule := range g.Rules {
+		b := make([]Beta, len(rule.B))
+		for j, beta := range rule.B {
+			if t, ok := beta.(Terminal); ok {
+				if v, ok := class[t]; ok {
+					if _, ok := first[v]; !ok {
+						first[v] = i
+					}
+					beta = v
+				}
+			}
+			b[j] = beta
+		}
+		rule.B = b
+		if !unique[rule.key()] {
+			unique[rule.key()] = true
+			rules = append(rules, rule)
+			origins = append(origins, i)
+		}
+	}
+	for _, rule := range terminals {
+		rules = append(rules, rule)
+		origins = append(origins, first[rule.A.(Variable)])
+	}
+	return rules, fresh, origins
+}
+
+// newTransformReport compares the rules before and after a transform.
+func newTransformReport(name string, before, after R, fresh V, origins []int) TransformReport {
+	r := TransformReport{Transform: name, Fresh: fresh, Origins: origins}
+	old := make(map[string]bool)
+	for _, rule := range before {
+		old[rule.key()] = true
+	}
+	kept := make(map[string]bool)
+	for i, rule := range after {
+		kept[rule.key()] = true
+		if !old[rule.key()] {
+			r.Added = append(r.Added, rule)
+			r.Rewritten = append(r.Rewritten, Rewrite{From: before[origins[i]], To: rule})
+		}
+	}
+	for _, rule := range before {
+		if !kept[rule.key()] {
+			r.Removed = append(r.Removed, rule)
+		}
+	}
+	return r
+}
+
+func removeEpsilon(g *CFG) (R, V, []int) {
+	nullable := g.nullable()
+	var epsilonFree R
+	var origins []int
+	unique := make(map[string]bool)
+	for j, rule := range g.Rules {
+		var positions []int
+		for i, b := range rule.B {
+			if v, ok := b.(Variable); ok && nullable[v] {
+				positions = append(positions, i)
+			}
+		}
+		for _, subset := range append([][]int{{}}, powerSet(positions)...) {
+			omit := make(map[int]bool)
+			for _, i := range subset {
+				omit[i] = true
+			}
+			var r []Beta
+			var kept []int
+			for i, b := range rule.B {
+				if b == Epsilon || omit[i] {
+					continue
+				}
+				r = append(r, b)
+				kept = append(kept, i)
+			}
+			if len(r) == 0 {
+				continue
+			}
+			p := rule
+			p.B, p.Captures = r, rule.capturesAt(kept)
+			if s := p.key(); !unique[s] {
+				unique[s] = true
+				epsilonFree = append(epsilonFree, p)
+				origins = append(origins, j)
+			}
+		}
+	}
+	// Variables that only derived ε have no productions left, so productions referring to them are removed.
+	for removed := true; removed; {
+		removed = false
+		defined := make(map[Beta]bool)
+		for _, rule := range epsilonFree {
+			defined[rule.A.(Variable)] = true
+		}
+		var r R
+		var o []int
+		for i, rule := range epsilonFree {
+			ok := true
+			for _, b := range rule.B {
+				if _, isVariable := b.(Variable); isVariable && !defined[b] {
+					ok = false
+				}
+			}
+			if ok {
+				r = append(r, rule)
+				o = append(o, origins[i])
+			} else {
+				removed = true
+			}
+		}
+		epsilonFree, origins = r, o
+	}
+	return epsilonFree, nil, origins
+}
+
+func removeUnits(g *CFG) (R, V, []int) {
+	closure := unitClosure(g.Rules)
+	var nonUnit R
+	var origins []int
+	done := make(map[Alpha]bool)
+	seen := make(map[string]bool)
+	for _, rule := range g.Rules {
+		a := rule.A.(Variable)
+		if done[a] {
+			continue
+		}
+		done[a] = true
+		for _, b := range closure[a] {
+			for j, rule := range g.Rules {
+				if rule.A != b || isUnit(rule) {
+					continue
+				}
+				p := rule
+				p.A = a
+				if s := p.key(); !seen[s] {
+					seen[s] = true
+					nonUnit = append(nonUnit, p)
+					origins = append(origins, j)
+				}
+			}
+		}
+	}
+	// The rules are sorted together with their origins.
+	order := make([]int, len(nonUnit))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return nonUnit.less(order[i], order[j]) })
+	sorted := make(R, len(nonUnit))
+	sortedOrigins := make([]int, len(nonUnit))
+	for i, j := range order {
+		sorted[i], sortedOrigins[i] = nonUnit[j], origins[j]
+	}
+	return sorted, nil, sortedOrigins
+}
+
+// unitChain returns a shortest chain of unit productions from a to b.
+func unitChain(rules R, a, b Variable) (R, bool) {
+	parent := map[Variable]Production{}
+	queue := []Variable{a}
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, rule := range rules {
+			if rule.A != v || !isUnit(rule) {
+				continue
+			}
+			w := rule.B[0].(Variable)
+			if _, ok := parent[w]; ok || w == a {
+				continue
+			}
+			parent[w] = rule
+			if w == b {
+				var chain R
+				for p := rule; ; p = parent[p.A.(Variable)] {
+					chain = append(R{p}, chain...)
+					if p.A == a {
+						return chain, true
+					}
+				}
+			}
+			queue = append(queue, w)
+		}
+	}
+	return nil, false
+}
+
+// Transform is a named rewriting of a grammar, see CFG.Transform.
+type Transform struct {
+	Name string
+	// apply returns the rewritten rules, the fresh variables they introduce, and the index of the rule of g from which
+	// every rule originates.
+	apply func(g *CFG) (R, V, []int)
+}
+
+// TransformReport are the changes of a single transform.
+type TransformReport struct {
+	Transform string
+	// Added and Removed are the productions that are only part of the grammar after, or before, the transform.
+	Added, Removed R
+	// Rewritten are the added productions, together with the production they originate from.
+	Rewritten []Rewrite
+	// Fresh are the variables introduced by the transform.
+	Fresh V
+	// Origins are for every production of the resulting grammar the index of the production of the previous grammar
+	// it originates from: a production with ε omitted, the production of a variable in the unit closure, the
+	// production that was split or the production whose terminal was lifted.
+	Origins []int
+}
+
+// History is the chain of transforms from which a grammar was derived, see Transform. Chained calls of Transform
+// extend the history of the grammar they are called on.
+type History struct {
+	// Original is the grammar to which the first transform was applied.
+	Original *CFG
+	// Reports are the reports of all transforms, in the order in which they were applied.
+	Reports []TransformReport
+
+	// derived are the grammars returned by the transforms.
+	derived []*CFG
+}
+
+// Origin returns the production of the original grammar from which the production with the given index in the derived
+// grammar originates.
+func (h *History) Origin(rule int) (Production, bool) {
+	for i := len(h.Reports) - 1; 0 <= i; i-- {
+		origins := h.Reports[i].Origins
+		if rule < 0 || len(origins) <= rule {
+			return Production{}, false
+		}
+		rule = origins[rule]
+	}
+	if rule < 0 || len(h.Original.Rules) <= rule {
+		return Production{}, false
+	}
+	return h.Original.Rules[rule], true
+}
+
+// VariableOrigin returns the name of the transform that introduced the variable, and the production of the original
+// grammar it was introduced for. Variables of the original grammar are reported with an empty transform name and no
+// production.
+func (h *History) VariableOrigin(v Variable) (string, Production, bool) {
+	for _, w := range h.Original.Variables {
+		if w == v {
+			return "", Production{}, true
+		}
+	}
+	for i, report := range h.Reports {
+		for _, w := range report.Fresh {
+			if w != v {
+				continue
+			}
+			sub := &History{Original: h.Original, Reports: h.Reports[:i+1]}
+			for j, rule := range h.derived[i].Rules {
+				if rule.A == v {
+					p, ok := sub.Origin(j)
+					return report.Transform, p, ok
+				}
+			}
+			return report.Transform, Production{}, false
+		}
+	}
+	return "", Production{}, false
+}
+
+// TranslatePath converts a leftmost derivation in the derived grammar into a leftmost derivation of the same string in
+// the original grammar: the productions of fresh variables are merged into the production they were split from, the
+// variables omitted by RemoveEpsilon derive ε again, and the unit productions removed by RemoveUnits are applied again.
+// This way a derivation in e.g. the CNF of a grammar can be replayed in the grammar itself.
+func (h *History) TranslatePath(p Path) (Path, error) {
+	t, err := p.Tree()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(h.Reports) - 1; 0 <= i; i-- {
+		trees, err := h.translation(i).translate(t)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", h.Reports[i].Transform, err)
+		}
+		if len(trees) != 1 {
+			return nil, fmt.Errorf("%s: derivation of fresh variable %v", h.Reports[i].Transform, t.Symbol)
+		}
+		t = trees[0]
+	}
+	return t.Path(), nil
+}
+
+// translation returns the translation of the trees of the grammar returned by the i-th transform.
+func (h *History) translation(i int) *translation {
+	before := h.Original
+	if 0 < i {
+		before = h.derived[i-1]
+	}
+	t := &translation{
+		before:  before,
+		index:   make(map[string]int),
+		origins: h.Reports[i].Origins,
+		fresh:   make(map[Beta]bool),
+		epsilon: make(map[Variable]Production),
+	}
+	for j, rule := range h.derived[i].Rules {
+		if _, ok := t.index[rule.key()]; !ok {
+			t.index[rule.key()] = j
+		}
+	}
+	for _, v := range h.Reports[i].Fresh {
+		t.fresh[v] = true
+	}
+	// Every nullable variable gets the first production with which it was found to derive ε, so the trees that derive
+	// ε are finite.
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range before.Rules {
+			a := rule.A.(Variable)
+			if _, ok := t.epsilon[a]; ok {
+				continue
+			}
+			ok := true
+			for _, b := range rule.B {
+				if v, isVariable := b.(Variable); b != Epsilon && (!isVariable || !t.hasEpsilon(v)) {
+					ok = false
+				}
+			}
+			if ok {
+				t.epsilon[a] = rule
+				changed = true
+			}
+		}
+	}
+	return t
+}
+
+// Rewrite is a production and the production it originates from, see TransformReport.
+type Rewrite struct {
+	From, To Production
+}
+
+// translation converts derivation trees of the grammar returned by a transform into derivation trees of the grammar it
+// was applied to, see TranslatePath.
+type translation struct {
+	before *CFG
+	// index is the index of every production of the returned grammar.
+	index   map[string]int
+	origins []int
+	fresh   map[Beta]bool
+	// epsilon is a production of every nullable variable.
+	epsilon map[Variable]Production
+}
+
+// align inserts trees that derive ε between the children, so that their symbols match the given ones.
+func (t *translation) align(children []*Tree, beta []Beta) ([]*Tree, bool) {
+	if len(beta) == 0 {
+		return nil, len(children) == 0
+	}
+	if len(children) != 0 && children[0].Symbol == beta[0] {
+		if rest, ok := t.align(children[1:], beta[1:]); ok {
+			return append([]*Tree{children[0]}, rest...), true
+		}
+	}
+	var omitted *Tree
+	if beta[0] == Epsilon {
+		omitted = &Tree{Symbol: Epsilon}
+	} else if v, ok := beta[0].(Variable); ok && t.hasEpsilon(v) {
+		omitted = t.epsilonTree(v)
+	} else {
+		return nil, false
+	}
+	rest, ok := t.align(children, beta[1:])
+	if !ok {
+		return nil, false
+	}
+	return append([]*Tree{omitted}, rest...), true
+}
+
+func (t *translation) epsilonTree(v Variable) *Tree {
+	rule := t.epsilon[v]
+	n := &Tree{Symbol: v, Production: &rule}
+	for _, b := range rule.B {
+		if b == Epsilon {
+			n.Children = append(n.Children, &Tree{Symbol: Epsilon})
+		} else {
+			n.Children = append(n.Children, t.epsilonTree(b.(Variable)))
+		}
+	}
+	return n
+}
+
+func (t *translation) hasEpsilon(v Variable) bool {
+	_, ok := t.epsilon[v]
+	return ok
+}
+
+// translate returns the translated tree, or the translated children if the tree derives a fresh variable.
+func (t *translation) translate(n *Tree) ([]*Tree, error) {
+	if n.Production == nil {
+		return []*Tree{n}, nil
+	}
+	i, ok := t.index[n.Production.key()]
+	if !ok {
+		return nil, fmt.Errorf("%v is not a production of the grammar", n.Production)
+	}
+	var children []*Tree
+	for _, c := range n.Children {
+		cs, err := t.translate(c)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, cs...)
+	}
+	if t.fresh[n.Symbol] {
+		return children, nil
+	}
+	origin := t.before.Rules[t.origins[i]]
+	aligned, ok := t.align(children, origin.B)
+	if !ok {
+		return nil, fmt.Errorf("%v does not originate from %v", n.Production, origin)
+	}
+	m := &Tree{Symbol: origin.A.(Variable), Production: &origin, Children: aligned}
+	if origin.A.(Variable) != n.Symbol {
+		chain, ok := unitChain(t.before.Rules, n.Symbol.(Variable), origin.A.(Variable))
+		if !ok {
+			return nil, fmt.Errorf("%v does not derive %v", n.Symbol, origin.A)
+		}
+		for j := len(chain) - 1; 0 <= j; j-- {
+			rule := chain[j]
+			m = &Tree{Symbol: rule.A.(Variable), Production: &rule, Children: []*Tree{m}}
+		}
+	}
+	return []*Tree{m}, nil
+}
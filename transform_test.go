@@ -0,0 +1,193 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"reflect"
+	"testing"
+)
+
+func ExampleCFG_Transform() {
+	g, _ := cfg.Parse("S → aSb | ab | ε\n")
+	h, reports, _ := g.Transform(cfg.RemoveEpsilon, cfg.LiftTerminals)
+	fmt.Println(h.Rules)
+	for _, r := range reports {
+		fmt.Printf("%s: +[%v] -[%v] %v\n", r.Transform, r.Added, r.Removed, r.Fresh)
+	}
+	// Output:
+	// S → T0ST1, S → T0T1, T0 → a, T1 → b
+	// RemoveEpsilon: +[] -[S → ε] []
+	// LiftTerminals: +[S → T0ST1, S → T0T1, T0 → a, T1 → b] -[S → aSb, S → ab] [T0 T1]
+}
+
+func TestCFG_Transform(t *testing.T) {
+	g, err := cfg.Parse("S → ASA | aB\nA → B | S\nB → b | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, reports, err := g.Transform(cfg.RemoveEpsilon, cfg.RemoveUnits, cfg.Binarize, cfg.LiftTerminals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 4 {
+		t.Fatalf("expected 4 reports, got %d", len(reports))
+	}
+	rules := append(cfg.R(nil), h.Rules...)
+	rules.Sort()
+	if cnf := g.CNF(); !reflect.DeepEqual(rules, cnf) {
+		t.Errorf("expected %v, got %v", cnf, rules)
+	}
+	if len(g.Rules) != 6 {
+		t.Error("the grammar was changed")
+	}
+}
+
+func ExampleHistory_Origin() {
+	g, _ := cfg.Parse("S → aSb | ab | ε\n")
+	h, _, _ := g.Transform(cfg.RemoveEpsilon)
+	h, _, _ = h.Transform(cfg.LiftTerminals)
+	for i, rule := range h.Rules {
+		p, _ := h.History().Origin(i)
+		fmt.Printf("%v ← %v\n", rule, p)
+	}
+	transform, p, _ := h.History().VariableOrigin("T1")
+	fmt.Println(transform, p)
+	// Output:
+	// S → T0ST1 ← S → aSb
+	// S → T0T1 ← S → aSb
+	// T0 → a ← S → aSb
+	// T1 → b ← S → aSb
+	// LiftTerminals S → aSb
+}
+
+func TestHistory(t *testing.T) {
+	g, err := cfg.Parse("S → ASA | aB\nA → B | S\nB → b | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.History() != nil {
+		t.Error("expected no history for a parsed grammar")
+	}
+	h, reports, err := g.Transform(cfg.RemoveEpsilon, cfg.RemoveUnits, cfg.Binarize, cfg.LiftTerminals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := h.History()
+	if history.Original != g || len(history.Reports) != len(reports) {
+		t.Fatalf("unexpected history %v", history)
+	}
+	for _, r := range reports {
+		for _, rw := range r.Rewritten {
+			if rw.From.A == nil {
+				t.Errorf("%s: no origin for %v", r.Transform, rw.To)
+			}
+		}
+	}
+	for i, rule := range h.Rules {
+		if _, ok := history.Origin(i); !ok {
+			t.Errorf("no origin for %v", rule)
+		}
+	}
+	for _, r := range reports {
+		for _, v := range r.Fresh {
+			if transform, _, ok := history.VariableOrigin(v); !ok || transform != r.Transform {
+				t.Errorf("expected %s to be introduced by %s, got %q", v, r.Transform, transform)
+			}
+		}
+	}
+	if _, ok := history.Origin(len(h.Rules)); ok {
+		t.Error("expected no origin for an out of range index")
+	}
+	if transform, _, ok := history.VariableOrigin("S"); !ok || transform != "" {
+		t.Errorf("expected S to be an original variable, got %q", transform)
+	}
+	if _, _, ok := history.VariableOrigin("X"); ok {
+		t.Error("expected no origin for an unknown variable")
+	}
+}
+
+func ExampleHistory_TranslatePath() {
+	g, _ := cfg.Parse("S → aSa | A\nA → b | ε\n")
+	h, _, _ := g.Transform(cfg.RemoveEpsilon, cfg.RemoveUnits, cfg.Binarize, cfg.LiftTerminals)
+	p, _ := h.EvaluateShortest("aa")
+	fmt.Println(p)
+	q, _ := h.History().TranslatePath(p)
+	fmt.Println(q)
+	// Output:
+	// [ S → T0T0, T0 → a, T0 → a ]
+	// [ S → aSa, S → A, A → ε ]
+}
+
+func TestHistory_TranslatePath(t *testing.T) {
+	g, err := cfg.Parse("S → ASA | aB\nA → B | S\nB → b | ε\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := g.Transform(cfg.RemoveEpsilon, cfg.RemoveUnits, cfg.Binarize, cfg.LiftTerminals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"a", "ab", "aa", "aba", "abab", "baab", "abaab"} {
+		p, ok := h.EvaluateShortest(s)
+		if !ok {
+			t.Fatalf("%q: not accepted by the derived grammar", s)
+		}
+		q, err := h.History().TranslatePath(p)
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		tree, err := q.Tree()
+		if err != nil {
+			t.Fatalf("%q: %v", s, err)
+		}
+		// Unparse checks that every production is one of the original grammar.
+		if u, err := tree.Unparse(g); err != nil || u != s {
+			t.Errorf("%q: expected the translated path to derive the string, got %q (%v)", s, u, err)
+		}
+	}
+	if _, err := h.History().TranslatePath(cfg.Path{g.Rules[0]}); err == nil {
+		t.Error("expected an error for a production of the original grammar")
+	}
+}
+
+func ExampleCFG_TokenClasses() {
+	g, _ := cfg.Parse("S → aS | bS | cS | d | e\n")
+	fmt.Println(g.TokenClasses())
+	h, _, _ := g.Transform(cfg.LiftTokenClasses)
+	fmt.Println(h.Rules)
+	// Output:
+	// [[a b c] [d e]]
+	// S → C0S, S → C1, C0 → a, C0 → b, C0 → c, C1 → d, C1 → e
+}
+
+func TestCFG_TokenClasses(t *testing.T) {
+	for _, test := range []struct {
+		grammar string
+		classes string
+	}{
+		{grammar: "S → aSa | bSb | ε\n", classes: "[]"},
+		{grammar: "S → aSa | aSb | bSa | bSb | ε\n", classes: "[[a b]]"},
+		{grammar: "S → aa | bb\n", classes: "[]"},
+		{grammar: "S → ab | ba | aa | bb\n", classes: "[[a b]]"},
+		{grammar: "S → aS | bS | a\n", classes: "[]"},
+	} {
+		g, err := cfg.Parse(test.grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if classes := fmt.Sprint(g.TokenClasses()); classes != test.classes {
+			t.Errorf("%q: expected %s, got %s", test.grammar, test.classes, classes)
+		}
+		h, _, err := g.Transform(cfg.LiftTokenClasses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{"", "a", "b", "aa", "ab", "ba", "bb", "abba", "abab", "aab"} {
+			_, a := g.EvaluateShortest(s)
+			_, b := h.EvaluateShortest(s)
+			if a != b {
+				t.Errorf("%q: expected %v for %q, got %v", test.grammar, a, s, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package cfg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Forest is a set of derivation trees of the same string, e.g. the trees of the derivations of EvaluateAll.
+type Forest []*Tree
+
+// Unparse returns the string derived by the trees, after verifying every tree against the grammar (see Tree.Unparse)
+// and that all trees derive the same string.
+func (f Forest) Unparse(g *CFG) (string, error) {
+	if len(f) == 0 {
+		return "", fmt.Errorf("empty forest")
+	}
+	var s string
+	for i, t := range f {
+		u, err := t.Unparse(g)
+		if err != nil {
+			return "", err
+		}
+		if i != 0 && u != s {
+			return "", fmt.Errorf("trees derive different strings: %q and %q", s, u)
+		}
+		s = u
+	}
+	return s, nil
+}
+
+// Rewriter rewrites derivation trees by the labels of their productions: the function of a label builds the
+// replacement of a node to which a production with that label was applied.
+type Rewriter map[string]func(t *Tree) *Tree
+
+// Rewrite rewrites the tree bottom-up, so the functions see the rewritten children of a node. A function that returns
+// nil removes the node from its parent. The given tree is not modified, unchanged nodes are shared.
+func (r Rewriter) Rewrite(t *Tree) *Tree {
+	if len(t.Children) != 0 {
+		n := *t
+		n.Children = make([]*Tree, 0, len(t.Children))
+		for _, c := range t.Children {
+			if c := r.Rewrite(c); c != nil {
+				n.Children = append(n.Children, c)
+			}
+		}
+		t = &n
+	}
+	if t.Production == nil {
+		return t
+	}
+	if f, ok := r[t.Production.Label]; ok && t.Production.Label != "" {
+		return f(t)
+	}
+	return t
+}
+
+// Tree is a derivation tree. Inner nodes are variables together with the production that was applied to them, leaves
+// are terminals (or ε).
+type Tree struct {
+	Symbol     Beta
+	Production *Production
+	Children   []*Tree
+}
+
+// Tree builds the derivation tree of the leftmost derivation.
+func (p Path) Tree() (*Tree, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	root := &Tree{Symbol: p[0].A.(Variable)}
+	pending := []*Tree{root} // Unexpanded variables, leftmost first.
+	for _, production := range p {
+		if len(pending) == 0 {
+			return nil, fmt.Errorf("no variable left to apply %v to", production)
+		}
+		n := pending[0]
+		if n.Symbol != production.A.(Variable) {
+			return nil, fmt.Errorf("expected production for %v, got %v", n.Symbol, production)
+		}
+		production := production
+		n.Production = &production
+		var variables []*Tree
+		for _, b := range production.B {
+			c := &Tree{Symbol: b}
+			n.Children = append(n.Children, c)
+			if _, ok := b.(Variable); ok {
+				variables = append(variables, c)
+			}
+		}
+		pending = append(variables, pending[1:]...)
+	}
+	if len(pending) != 0 {
+		return nil, fmt.Errorf("variable %v was not expanded", pending[0].Symbol)
+	}
+	return root, nil
+}
+
+// Path returns the leftmost derivation of the tree.
+func (t *Tree) Path() Path {
+	if t.Production == nil {
+		return nil
+	}
+	p := Path{*t.Production}
+	for _, c := range t.Children {
+		p = append(p, c.Path()...)
+	}
+	return p
+}
+
+// String returns the tree in bracketed notation, e.g. `S(a S(ε) a)`.
+func (t *Tree) String() string {
+	if len(t.Children) == 0 {
+		return t.Symbol.String()
+	}
+	var s []string
+	for _, c := range t.Children {
+		s = append(s, c.String())
+	}
+	return fmt.Sprintf("%v(%s)", t.Symbol, strings.Join(s, " "))
+}
+
+// Walk traverses the tree depth-first, from left to right.
+func (t *Tree) Walk(v Visitor) {
+	if v.Enter != nil && !v.Enter(t) {
+		return
+	}
+	for _, c := range t.Children {
+		c.Walk(v)
+	}
+	if v.Exit != nil {
+		v.Exit(t)
+	}
+}
+
+// Unparse returns the string of terminals derived by the tree, e.g. after it was rewritten. Every node with children
+// must apply a production of the grammar to its symbol, the children must be the symbols of the production, and every
+// leaf must be a terminal of the grammar or ε. The root does not need to be the start variable.
+func (t *Tree) Unparse(g *CFG) (string, error) {
+	var b strings.Builder
+	if err := t.unparse(g, &b); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (t *Tree) unparse(g *CFG, b *strings.Builder) error {
+	switch symbol := t.Symbol.(type) {
+	case Terminal:
+		if len(t.Children) != 0 {
+			return fmt.Errorf("terminal %v has children", symbol)
+		}
+		if _, ok := g.symbols.terminal[symbol]; !ok {
+			return fmt.Errorf("terminal %v not in alphabet", symbol)
+		}
+		b.WriteString(string(symbol))
+		return nil
+	case Category:
+		return fmt.Errorf("category %v does not derive a single string", symbol)
+	case emptyString:
+		if len(t.Children) != 0 {
+			return fmt.Errorf("ε has children")
+		}
+		return nil
+	case Variable:
+		if t.Production == nil {
+			return fmt.Errorf("variable %v was not expanded", symbol)
+		}
+		if t.Production.A != symbol {
+			return fmt.Errorf("production %v applied to %v", t.Production, symbol)
+		}
+		var defined bool
+		for _, rule := range g.mappedRules[symbol] {
+			defined = defined || rule.Equal(*t.Production)
+		}
+		if !defined {
+			return fmt.Errorf("production %v not in rules", t.Production)
+		}
+		if len(t.Children) != len(t.Production.B) {
+			return fmt.Errorf("expected %d children of %v, got %d", len(t.Production.B), t.Production, len(t.Children))
+		}
+		for i, c := range t.Children {
+			if c.Symbol != t.Production.B[i] {
+				return fmt.Errorf("expected child %v of %v, got %v", t.Production.B[i], t.Production, c.Symbol)
+			}
+			if err := c.unparse(g, b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unexpected symbol %v", t.Symbol)
+}
+
+// Visitor contains the hooks of Walk, both are optional.
+type Visitor struct {
+	// Enter is called before the children of a node are visited, if it returns false the children and the exit of the
+	// node are skipped.
+	Enter func(t *Tree) bool
+	// Exit is called after the children of a node are visited.
+	Exit func(t *Tree)
+}
@@ -0,0 +1,121 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"strings"
+	"testing"
+)
+
+func ExamplePath_Tree() {
+	p, _ := g.Evaluate("abba")
+	t, _ := p.Tree()
+	fmt.Println(t)
+	fmt.Println(t.Path())
+	// Output:
+	// S(a S(b S(ε) b) a)
+	// [ S → aSa, S → bSb, S → ε ]
+}
+
+func TestPath_HTML(t *testing.T) {
+	p, _ := g.Evaluate("abba")
+	html, err := p.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"<title>Derivation of abba</title>",
+		`max="3"`,
+		`data-step="2"`,
+		"S → bSb",
+	} {
+		if !strings.Contains(html, s) {
+			t.Errorf("expected %q in HTML output", s)
+		}
+	}
+
+	if _, err := (cfg.Path{g.Rules[0], g.Rules[0]}).HTML(); err == nil {
+		t.Error("expected an error for an incomplete derivation")
+	}
+}
+
+func ExampleRewriter() {
+	g, _ := cfg.Parse("E → TpE #add | T #term\nT → x #x | y #y\n")
+	p, _ := g.Evaluate("xpy")
+	t, _ := p.Tree()
+	// Replace the variables and operators by the labels of their productions, and the terms by their value.
+	t = cfg.Rewriter{
+		"add": func(t *cfg.Tree) *cfg.Tree {
+			return &cfg.Tree{Symbol: cfg.Variable("Add"), Children: []*cfg.Tree{t.Children[0], t.Children[2]}}
+		},
+		"term": func(t *cfg.Tree) *cfg.Tree { return t.Children[0] },
+		"x":    func(t *cfg.Tree) *cfg.Tree { return t.Children[0] },
+		"y":    func(t *cfg.Tree) *cfg.Tree { return t.Children[0] },
+	}.Rewrite(t)
+	fmt.Println(t)
+	// Output:
+	// Add(x y)
+}
+
+func TestTree_Walk(t *testing.T) {
+	p, _ := g.Evaluate("abba")
+	tree, _ := p.Tree()
+	var events []string
+	tree.Walk(cfg.Visitor{
+		Enter: func(t *cfg.Tree) bool {
+			events = append(events, "+"+t.Symbol.String())
+			// Skip the inner derivation.
+			return t.Production == nil || t.Production.B[0] != cfg.Terminal("b")
+		},
+		Exit: func(t *cfg.Tree) {
+			events = append(events, "-"+t.Symbol.String())
+		},
+	})
+	expected := "[+S +a -a +S +a -a -S]"
+	if fmt.Sprint(events) != expected {
+		t.Errorf("expected %s, got %v", expected, events)
+	}
+}
+
+func TestTree_Unparse(t *testing.T) {
+	p, _ := g.Evaluate("abba")
+	tree, _ := p.Tree()
+	if s, err := tree.Unparse(g); err != nil || s != "abba" {
+		t.Errorf("expected abba, got %q (%v)", s, err)
+	}
+
+	// Swap the inner derivation for another production of the grammar.
+	inner, _ := (cfg.Path{g.Rules[0], g.Rules[2]}).Tree()
+	tree.Children[1] = inner
+	if s, err := tree.Unparse(g); err != nil || s != "aaaa" {
+		t.Errorf("expected aaaa, got %q (%v)", s, err)
+	}
+
+	tree.Children[1] = &cfg.Tree{Symbol: cfg.Terminal("c")}
+	if _, err := tree.Unparse(g); err == nil {
+		t.Error("expected an error for a child that does not match the production")
+	}
+	tree.Children[1] = &cfg.Tree{Symbol: cfg.Variable("S")}
+	if _, err := tree.Unparse(g); err == nil {
+		t.Error("expected an error for a variable that was not expanded")
+	}
+}
+
+func TestForest_Unparse(t *testing.T) {
+	e, err := cfg.Parse("E → EpE | x\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forest cfg.Forest
+	for _, p := range e.EvaluateAll("xpxpx") {
+		tree, _ := p.Tree()
+		forest = append(forest, tree)
+	}
+	if s, err := forest.Unparse(e); err != nil || s != "xpxpx" {
+		t.Errorf("expected xpxpx, got %q (%v)", s, err)
+	}
+	x, _ := (cfg.Path{e.Rules[1]}).Tree()
+	if _, err := append(forest, x).Unparse(e); err == nil {
+		t.Error("expected an error for trees that derive different strings")
+	}
+}
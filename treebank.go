@@ -0,0 +1,171 @@
+package cfg
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// treebankEscapes are the escapes of the Penn Treebank for terminals that are brackets, and of the terminal ε, which
+// would otherwise be read as the empty string.
+var treebankEscapes = map[string]string{"(": "-LRB-", ")": "-RRB-", "ε": "-EPS-"}
+
+// ReadTreebank reads the trees of a bracketed treebank, e.g. `(S (NP (D the) (N dog)) (V barks))`. Every bracketed
+// node is a variable with the production of its children, every other token is a terminal. An empty node or the token
+// ε derives the empty string, the brackets are escaped as -LRB- and -RRB-, the terminal ε as -EPS-, and the unlabeled
+// outer brackets of the Penn Treebank (`( (S …) )`) are removed.
+func ReadTreebank(r io.Reader) ([]*Tree, error) {
+	tokens, err := tokenizeTreebank(r)
+	if err != nil {
+		return nil, err
+	}
+	var trees []*Tree
+	for i := 0; i < len(tokens); {
+		if tokens[i].value != "(" {
+			return nil, fmt.Errorf("line %d: expected (, got %q", tokens[i].line, tokens[i].value)
+		}
+		t, n, err := readTreebankNode(tokens[i:])
+		if err != nil {
+			return nil, err
+		}
+		trees = append(trees, t)
+		i += n
+	}
+	return trees, nil
+}
+
+// WriteTreebank writes the trees in the bracketed format read by ReadTreebank, one tree per line.
+func WriteTreebank(w io.Writer, trees []*Tree) error {
+	for _, t := range trees {
+		s, err := t.bracketed()
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintln(w, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readTreebankNode reads the node starting at the first token, which is an opening bracket. It returns the node and the
+// number of tokens read.
+func readTreebankNode(tokens []treebankToken) (*Tree, int, error) {
+	start := tokens[0]
+	i := 1
+	var label string
+	if i < len(tokens) && tokens[i].value != "(" && tokens[i].value != ")" {
+		label = tokens[i].value
+		i++
+	}
+	var children []*Tree
+	for {
+		if len(tokens) <= i {
+			return nil, 0, fmt.Errorf("line %d: unclosed (", start.line)
+		}
+		switch tokens[i].value {
+		case ")":
+			i++
+			if label == "" {
+				// The outer brackets of the Penn Treebank.
+				if len(children) != 1 || children[0].Production == nil {
+					return nil, 0, fmt.Errorf("line %d: missing label", start.line)
+				}
+				return children[0], i, nil
+			}
+			if len(children) == 0 {
+				children = []*Tree{{Symbol: Epsilon}}
+			}
+			t := &Tree{Symbol: Variable(label), Children: children}
+			p := NewProduction(Variable(label), make([]Beta, len(children)))
+			for j, c := range children {
+				p.B[j] = c.Symbol
+			}
+			t.Production = &p
+			return t, i, nil
+		case "(":
+			c, n, err := readTreebankNode(tokens[i:])
+			if err != nil {
+				return nil, 0, err
+			}
+			children = append(children, c)
+			i += n
+		default:
+			terminal := tokens[i].value
+			if terminal == Epsilon.String() {
+				children = append(children, &Tree{Symbol: Epsilon})
+				i++
+				continue
+			}
+			for unescaped, escaped := range treebankEscapes {
+				if terminal == escaped {
+					terminal = unescaped
+				}
+			}
+			children = append(children, &Tree{Symbol: Terminal(terminal)})
+			i++
+		}
+	}
+}
+
+func tokenizeTreebank(r io.Reader) ([]treebankToken, error) {
+	var tokens []treebankToken
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	for line := 1; scanner.Scan(); line++ {
+		s := scanner.Text()
+		for len(s) != 0 {
+			switch {
+			case unicode.IsSpace(rune(s[0])):
+				s = s[1:]
+			case s[0] == '(' || s[0] == ')':
+				tokens = append(tokens, treebankToken{value: s[:1], line: line})
+				s = s[1:]
+			default:
+				end := strings.IndexFunc(s, func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ')' })
+				if end < 0 {
+					end = len(s)
+				}
+				tokens = append(tokens, treebankToken{value: s[:end], line: line})
+				s = s[end:]
+			}
+		}
+	}
+	return tokens, scanner.Err()
+}
+
+// bracketed returns the tree in the format of a treebank.
+func (t *Tree) bracketed() (string, error) {
+	switch symbol := t.Symbol.(type) {
+	case Terminal:
+		if escaped, ok := treebankEscapes[string(symbol)]; ok {
+			return escaped, nil
+		}
+		if strings.IndexFunc(string(symbol), func(r rune) bool { return unicode.IsSpace(r) || r == '(' || r == ')' }) >= 0 {
+			return "", fmt.Errorf("terminal %q can not be written to a treebank", symbol)
+		}
+		return string(symbol), nil
+	case Category:
+		return "", fmt.Errorf("category %v can not be written to a treebank", symbol)
+	case emptyString:
+		return symbol.String(), nil
+	case Variable:
+		ss := []string{"(" + string(symbol)}
+		for _, c := range t.Children {
+			s, err := c.bracketed()
+			if err != nil {
+				return "", err
+			}
+			ss = append(ss, s)
+		}
+		return strings.Join(ss, " ") + ")", nil
+	}
+	return "", fmt.Errorf("unexpected symbol %v", t.Symbol)
+}
+
+type treebankToken struct {
+	value string
+	line  int
+}
@@ -0,0 +1,94 @@
+package cfg_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ExampleReadTreebank() {
+	trees, _ := cfg.ReadTreebank(strings.NewReader(`
+		( (S (NP (D the) (N dog)) (V barks)) )
+		(S (A -LRB-) (B))
+	`))
+	for _, t := range trees {
+		fmt.Println(t, t.Path())
+	}
+	var b bytes.Buffer
+	_ = cfg.WriteTreebank(&b, trees)
+	fmt.Print(b.String())
+	// Output:
+	// S(NP(D(the) N(dog)) V(barks)) [ S → NPV, NP → DN, D → the, N → dog, V → barks ]
+	// S(A(() B(ε)) [ S → AB, A → (, B → ε ]
+	// (S (NP (D the) (N dog)) (V barks))
+	// (S (A -LRB-) (B ε))
+}
+
+func TestReadTreebank(t *testing.T) {
+	for _, test := range []string{
+		"S",
+		"(S a",
+		"(S a))",
+		"((S a) (S b))",
+	} {
+		if _, err := cfg.ReadTreebank(strings.NewReader(test)); err == nil {
+			t.Errorf("%q: expected an error", test)
+		}
+	}
+
+	// A derivation of a grammar survives a round trip.
+	p, _ := g.Evaluate("abba")
+	tree, _ := p.Tree()
+	var b bytes.Buffer
+	if err := cfg.WriteTreebank(&b, []*cfg.Tree{tree}); err != nil {
+		t.Fatal(err)
+	}
+	trees, err := cfg.ReadTreebank(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 1 || trees[0].String() != tree.String() {
+		t.Errorf("expected %v, got %v", tree, trees)
+	}
+	if p.String() != trees[0].Path().String() {
+		t.Errorf("expected %v, got %v", p, trees[0].Path())
+	}
+}
+
+func TestWriteTreebank_epsilon(t *testing.T) {
+	S, A := cfg.Variable("S"), cfg.Variable("A")
+	eps := cfg.Terminal("ε")
+	g, err := cfg.New(cfg.V{S, A}, cfg.Alphabet{"a", eps}, cfg.R{
+		cfg.NewProduction(S, []cfg.Beta{A, eps, A}),
+		cfg.NewProduction(A, []cfg.Beta{cfg.Terminal("a")}),
+		cfg.NewProduction(A, []cfg.Beta{cfg.Epsilon}),
+	}, S)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := g.Evaluate("εa")
+	if !ok {
+		t.Fatal("expected εa to be accepted")
+	}
+	tree, err := p.Tree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := cfg.WriteTreebank(&b, []*cfg.Tree{tree}); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.String(); s != "(S (A ε) -EPS- (A a))\n" {
+		t.Errorf("unexpected treebank %q", s)
+	}
+	trees, err := cfg.ReadTreebank(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trees) != 1 || !reflect.DeepEqual(trees[0].Path(), p) {
+		t.Errorf("expected %v, got %v", p, trees)
+	}
+}
@@ -0,0 +1,123 @@
+package cfg
+
+// UnusedAlternatives returns the productions that are not used by any derivation of the strings of the corpus that are
+// accepted, in the order of the rules. All derivations of a string are considered, independent of the strategy and the
+// limits of the grammar. A production that no string of the language can use at all, e.g. of an unreachable variable,
+// is marked as unreachable, the others are merely not exercised by the corpus.
+func (g *CFG) UnusedAlternatives(corpus []string) []UnusedAlternative {
+	used := make([]bool, len(g.Rules))
+	for _, s := range corpus {
+		g.markUsed(s, used)
+	}
+	usable := g.usable()
+	var unused []UnusedAlternative
+	for i, rule := range g.Rules {
+		if !used[i] {
+			unused = append(unused, UnusedAlternative{Production: rule, Unreachable: !usable[i]})
+		}
+	}
+	return unused
+}
+
+// markUsed marks the rules that are used by a derivation of the string.
+func (g *CFG) markUsed(s string, used []bool) {
+	for x := range g.derivedItems(s) {
+		if x.variable < 0 && x.dot == len(g.symbols.rules[x.rule].b) {
+			used[x.rule] = true
+		}
+	}
+}
+
+// derivedItems returns the items of the chart of the string that are part of a complete derivation, nil if the string
+// is not accepted. The spans of the string that are derived are traversed from the start variable down, so only the
+// spans that are part of a complete derivation are visited.
+func (g *CFG) derivedItems(s string) map[chartItem]bool {
+	t := g.symbols
+	s = g.input(s)
+	search := g.spanSearch(s, func(int) float64 { return 1 }, nil)
+	goal := chartItem{variable: t.variable[g.StartVariable], rule: -1, i: 0, j: len(s)}
+	if !search.final[goal] {
+		return nil
+	}
+	visited := make(map[chartItem]bool)
+	var visit func(x chartItem)
+	visit = func(x chartItem) {
+		if visited[x] {
+			return
+		}
+		visited[x] = true
+		if 0 <= x.variable {
+			for i, r := range t.rules {
+				if y := (chartItem{variable: -1, rule: i, dot: len(r.b), i: x.i, j: x.j}); r.a == x.variable && search.final[y] {
+					visit(y)
+				}
+			}
+			return
+		}
+		if x.dot == 0 {
+			return
+		}
+		b := t.rules[x.rule].b[x.dot-1]
+		for k := x.i; k <= x.j; k++ {
+			prev := chartItem{variable: -1, rule: x.rule, dot: x.dot - 1, i: x.i, j: k}
+			if !search.final[prev] {
+				continue
+			}
+			if b < 0 {
+				if n, ok := t.match(s[k:x.j], t.terminals[-b-1]); !ok || n != x.j-k {
+					continue
+				}
+			} else {
+				child := chartItem{variable: b, rule: -1, i: k, j: x.j}
+				if !search.final[child] {
+					continue
+				}
+				visit(child)
+			}
+			visit(prev)
+		}
+	}
+	visit(goal)
+	return visited
+}
+
+// usable returns for every rule whether it is used by a derivation of any string of terminals: its variable is
+// reachable from the start variable through such rules, and all of its variables derive a string of terminals.
+func (g *CFG) usable() []bool {
+	productive := g.productive()
+	usable := make([]bool, len(g.Rules))
+	reachable := map[Variable]bool{g.StartVariable: true}
+	for changed := true; changed; {
+		changed = false
+		for i, rule := range g.Rules {
+			if usable[i] || !reachable[rule.A.(Variable)] {
+				continue
+			}
+			ok := true
+			for _, b := range rule.B {
+				if v, isVariable := b.(Variable); isVariable {
+					if _, p := productive[v]; !p {
+						ok = false
+					}
+				}
+			}
+			if !ok {
+				continue
+			}
+			usable[i], changed = true, true
+			for _, b := range rule.B {
+				if v, isVariable := b.(Variable); isVariable {
+					reachable[v] = true
+				}
+			}
+		}
+	}
+	return usable
+}
+
+// UnusedAlternative is a production that is not used by a corpus, see UnusedAlternatives.
+type UnusedAlternative struct {
+	Production Production
+	// Unreachable is true if no string of terminals has a derivation that uses the production.
+	Unreachable bool
+}
@@ -0,0 +1,41 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleCFG_UnusedAlternatives() {
+	g, _ := cfg.Parse("S → aSa | bSb | c | D\nD → dD\nE → e\n")
+	for _, u := range g.UnusedAlternatives([]string{"aca", "c", "x"}) {
+		fmt.Println(u.Production, u.Unreachable)
+	}
+	// Output:
+	// S → bSb false
+	// S → D true
+	// D → dD true
+	// E → e true
+}
+
+func TestCFG_UnusedAlternatives(t *testing.T) {
+	// The grammar is ambiguous and left-recursive, every derivation counts.
+	g, err := cfg.Parse("S → SS | a | Ab | B\nA → a | ε\nB → b\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		corpus []string
+		unused string
+	}{
+		{corpus: nil, unused: "[{S → SS false} {S → a false} {S → Ab false} {S → B false} {A → a false} {A → ε false} {B → b false}]"},
+		{corpus: []string{"a"}, unused: "[{S → SS false} {S → Ab false} {S → B false} {A → a false} {A → ε false} {B → b false}]"},
+		{corpus: []string{"aa"}, unused: "[{S → Ab false} {S → B false} {A → a false} {A → ε false} {B → b false}]"},
+		{corpus: []string{"b"}, unused: "[{S → SS false} {S → a false} {A → a false}]"},
+		{corpus: []string{"ab", "ba"}, unused: "[]"},
+	} {
+		if unused := fmt.Sprint(g.UnusedAlternatives(test.corpus)); unused != test.unused {
+			t.Errorf("%q: expected %s, got %s", test.corpus, test.unused, unused)
+		}
+	}
+}
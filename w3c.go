@@ -0,0 +1,341 @@
+package cfg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxClassSize is the maximum number of characters a character class can be expanded to.
+const maxClassSize = 1024
+
+// ParseW3CEBNF parses a grammar in the EBNF notation of the W3C XML specification (e.g. `S ::= 'a' S 'a' | 'b'`).
+// Literals become terminals, and optional, repeated and grouped expressions, as well as character classes, are
+// desugared to productions of fresh variables named after their rule (e.g. `S_1`). The first rule defines the start
+// variable. Negated character classes and the difference operator are not context-free and thus not supported.
+func ParseW3CEBNF(input string) (*CFG, error) {
+	tokens, err := tokenizeW3C(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &w3cParser{
+		tokens:    tokens,
+		names:     make(map[string]bool),
+		defined:   make(map[string]bool),
+		terminals: make(map[Terminal]bool),
+	}
+	for _, t := range tokens {
+		if t.kind == w3cDefine {
+			p.names[t.value] = true
+		}
+	}
+	return p.parse()
+}
+
+func tokenizeW3C(input string) ([]w3cToken, error) {
+	var tokens []w3cToken
+	rs := []rune(input)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.HasPrefix(string(rs[i:]), "/*"):
+			end := strings.Index(string(rs[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += 2 + len([]rune(string(rs[i+2:])[:end])) + 2
+		case strings.HasPrefix(string(rs[i:]), "::="):
+			if len(tokens) == 0 || tokens[len(tokens)-1].kind != w3cName {
+				return nil, fmt.Errorf("expected name before ::=")
+			}
+			tokens[len(tokens)-1].kind = w3cDefine
+			i += 3
+		case r == '\'' || r == '"':
+			j := i + 1
+			for j < len(rs) && rs[j] != r {
+				j++
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("unterminated literal")
+			}
+			tokens = append(tokens, w3cToken{kind: w3cLiteral, value: string(rs[i+1 : j])})
+			i = j + 1
+		case r == '[':
+			j := i + 1
+			for j < len(rs) && rs[j] != ']' {
+				j++
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			tokens = append(tokens, w3cToken{kind: w3cClass, value: string(rs[i+1 : j])})
+			i = j + 1
+		case r == '#':
+			c, n, err := parseW3CChar(rs[i:])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, w3cToken{kind: w3cLiteral, value: string(c)})
+			i += n
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || rs[j] == '_' || rs[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, w3cToken{kind: w3cName, value: string(rs[i:j])})
+			i = j
+		case strings.ContainsRune("|()?*+-", r):
+			tokens = append(tokens, w3cToken{kind: w3cOperator, value: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+// parseW3CChar parses a character code (e.g. `#x41`), it returns the character and the number of runes read.
+func parseW3CChar(rs []rune) (rune, int, error) {
+	if len(rs) < 3 || rs[0] != '#' || rs[1] != 'x' {
+		return 0, 0, fmt.Errorf("expected character code")
+	}
+	j := 2
+	for j < len(rs) && strings.ContainsRune("0123456789abcdefABCDEF", rs[j]) {
+		j++
+	}
+	c, err := strconv.ParseInt(string(rs[2:j]), 16, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid character code %q", string(rs[:j]))
+	}
+	return rune(c), j, nil
+}
+
+type w3cParser struct {
+	tokens []w3cToken
+	i      int
+	// names are the names of all rules, fresh variables must not collide with them.
+	names map[string]bool
+	// rule is the name of the rule that is currently parsed, fresh variables are named after it.
+	rule  string
+	fresh int
+
+	variables V
+	defined   map[string]bool
+	alphabet  Alphabet
+	terminals map[Terminal]bool
+	rules     R
+}
+
+// alternatives parses alternatives separated by `|`.
+func (p *w3cParser) alternatives() ([][]Beta, error) {
+	var alternatives [][]Beta
+	for {
+		s, err := p.sequence()
+		if err != nil {
+			return nil, err
+		}
+		alternatives = append(alternatives, s)
+		if !p.next(w3cOperator, "|") {
+			return alternatives, nil
+		}
+	}
+}
+
+// class expands a character class into a fresh variable with one alternative per character.
+func (p *w3cParser) class(class string) (Beta, error) {
+	rs := []rune(class)
+	if len(rs) != 0 && rs[0] == '^' {
+		return nil, fmt.Errorf("negated character class [%s] is not supported", class)
+	}
+	char := func(i int) (rune, int, error) {
+		if rs[i] == '#' {
+			return parseW3CChar(rs[i:])
+		}
+		return rs[i], 1, nil
+	}
+	var alternatives [][]Beta
+	for i := 0; i < len(rs); {
+		lo, n, err := char(i)
+		if err != nil {
+			return nil, err
+		}
+		i += n
+		hi := lo
+		if i+1 < len(rs) && rs[i] == '-' {
+			if hi, n, err = char(i + 1); err != nil {
+				return nil, err
+			}
+			i += 1 + n
+		}
+		for c := lo; c <= hi; c++ {
+			if maxClassSize <= len(alternatives) {
+				return nil, fmt.Errorf("character class [%s] is too large", class)
+			}
+			alternatives = append(alternatives, []Beta{p.terminal(string(c))})
+		}
+	}
+	if len(alternatives) == 0 {
+		return nil, fmt.Errorf("empty character class")
+	}
+	return p.variable(alternatives), nil
+}
+
+func (p *w3cParser) freshVariable() Variable {
+	for {
+		p.fresh++
+		v := Variable(fmt.Sprintf("%s_%d", p.rule, p.fresh))
+		if !p.defined[v.String()] && !p.names[v.String()] {
+			p.defined[v.String()] = true
+			p.variables = append(p.variables, v)
+			return v
+		}
+	}
+}
+
+func (p *w3cParser) next(kind w3cKind, value string) bool {
+	if p.i < len(p.tokens) && p.tokens[p.i].kind == kind && p.tokens[p.i].value == value {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *w3cParser) parse() (*CFG, error) {
+	for p.i < len(p.tokens) {
+		t := p.tokens[p.i]
+		if t.kind != w3cDefine {
+			return nil, fmt.Errorf("expected rule, got %q", t.value)
+		}
+		p.i++
+		p.rule, p.fresh = t.value, 0
+		if !p.defined[t.value] {
+			p.defined[t.value] = true
+			p.variables = append(p.variables, Variable(t.value))
+		}
+		alternatives, err := p.alternatives()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", t.value, err)
+		}
+		p.production(Variable(t.value), alternatives)
+	}
+	if len(p.variables) == 0 {
+		return nil, fmt.Errorf("no rules")
+	}
+	for _, rule := range p.rules {
+		for _, b := range rule.B {
+			if v, ok := b.(Variable); ok && !p.defined[v.String()] {
+				return nil, fmt.Errorf("undefined symbol %s", v)
+			}
+		}
+	}
+	return New(p.variables, p.alphabet, p.rules, p.variables[0])
+}
+
+func (p *w3cParser) production(v Variable, alternatives [][]Beta) {
+	for _, a := range alternatives {
+		if len(a) == 0 {
+			a = []Beta{Epsilon}
+		}
+		p.rules = append(p.rules, NewProduction(v, a))
+	}
+}
+
+// sequence parses a sequence of (postfixed) primaries, until the end of an alternative.
+func (p *w3cParser) sequence() ([]Beta, error) {
+	var s []Beta
+	for p.i < len(p.tokens) {
+		t := p.tokens[p.i]
+		var alternatives [][]Beta
+		switch {
+		case t.kind == w3cDefine, t.kind == w3cOperator && (t.value == "|" || t.value == ")"):
+			return s, nil
+		case t.kind == w3cName:
+			alternatives = [][]Beta{{Variable(t.value)}}
+		case t.kind == w3cLiteral:
+			if t.value == "" {
+				alternatives = [][]Beta{{}}
+				break
+			}
+			alternatives = [][]Beta{{p.terminal(t.value)}}
+		case t.kind == w3cClass:
+			v, err := p.class(t.value)
+			if err != nil {
+				return nil, err
+			}
+			alternatives = [][]Beta{{v}}
+		case t.kind == w3cOperator && t.value == "(":
+			p.i++
+			var err error
+			if alternatives, err = p.alternatives(); err != nil {
+				return nil, err
+			}
+			if p.i == len(p.tokens) || p.tokens[p.i].value != ")" {
+				return nil, fmt.Errorf("expected )")
+			}
+		case t.kind == w3cOperator && t.value == "-":
+			return nil, fmt.Errorf("the difference operator is not supported")
+		default:
+			return nil, fmt.Errorf("unexpected %q", t.value)
+		}
+		p.i++
+
+		for p.i < len(p.tokens) && p.tokens[p.i].kind == w3cOperator && strings.Contains("?*+", p.tokens[p.i].value) {
+			x := p.variable(alternatives)
+			v := p.freshVariable()
+			switch p.tokens[p.i].value {
+			case "?":
+				p.production(v, [][]Beta{{x}, {}})
+			case "*":
+				p.production(v, [][]Beta{{x, v}, {}})
+			case "+":
+				p.production(v, [][]Beta{{x, v}, {x}})
+			}
+			alternatives = [][]Beta{{v}}
+			p.i++
+		}
+		if len(alternatives) == 1 {
+			s = append(s, alternatives[0]...)
+			continue
+		}
+		s = append(s, p.variable(alternatives))
+	}
+	return s, nil
+}
+
+func (p *w3cParser) terminal(s string) Terminal {
+	t := Terminal(s)
+	if !p.terminals[t] {
+		p.terminals[t] = true
+		p.alphabet = append(p.alphabet, t)
+	}
+	return t
+}
+
+// variable returns a fresh variable for the given alternatives. A single alternative of a single symbol is returned
+// as is.
+func (p *w3cParser) variable(alternatives [][]Beta) Beta {
+	if len(alternatives) == 1 && len(alternatives[0]) == 1 {
+		return alternatives[0][0]
+	}
+	v := p.freshVariable()
+	p.production(v, alternatives)
+	return v
+}
+
+type w3cKind int
+
+const (
+	w3cName w3cKind = iota
+	w3cDefine
+	w3cLiteral
+	w3cClass
+	w3cOperator
+)
+
+type w3cToken struct {
+	kind  w3cKind
+	value string
+}
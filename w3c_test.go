@@ -0,0 +1,52 @@
+package cfg_test
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"testing"
+)
+
+func ExampleParseW3CEBNF() {
+	g, _ := cfg.ParseW3CEBNF(`
+		/* A list of numbers. */
+		List   ::= '[' ( Number ( ',' Number )* )? ']'
+		Number ::= [0-2]+
+	`)
+	fmt.Print(g.Text())
+	_, ok := g.Evaluate("[1,20]")
+	fmt.Println(ok)
+	// Output:
+	// List → [List_4]
+	// List_1 → \,Number
+	// List_2 → List_1List_2 | ε
+	// List_3 → NumberList_2
+	// List_4 → List_3 | ε
+	// Number → Number_2
+	// Number_1 → \0 | \1 | \2
+	// Number_2 → Number_1Number_2 | Number_1
+	// true
+}
+
+func TestParseW3CEBNF(t *testing.T) {
+	for _, test := range []string{
+		"S ::= 'a' S 'b' | ''",
+		`S ::= "it's" | #x41 | [#x61-#x63]`,
+		"S ::= ('a' | 'b') 'c'?",
+	} {
+		if _, err := cfg.ParseW3CEBNF(test); err != nil {
+			t.Errorf("%s: %v", test, err)
+		}
+	}
+	for _, test := range []string{
+		"",
+		"S ::= A",
+		"S ::= [^a]",
+		"S ::= A - 'a'\nA ::= 'a'",
+		"S ::= ('a'",
+		"S ::= 'a",
+	} {
+		if _, err := cfg.ParseW3CEBNF(test); err == nil {
+			t.Errorf("%s: expected an error", test)
+		}
+	}
+}
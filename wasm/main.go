@@ -0,0 +1,149 @@
+//go:build js && wasm
+
+// Wasm exposes the grammars to JavaScript, e.g. for an in-browser playground. It registers the global object `cfg`
+// with the functions `parse(text)`, `evaluate(grammar, input)` and `replay(grammar, path)`. Grammars are referred to
+// by the handle returned by parse, failures are returned as an object with an `error` property.
+//
+//	$ GOOS=js GOARCH=wasm go build -o cfg.wasm ./wasm
+package main
+
+import (
+	"fmt"
+	"github.com/0x51-dev/cfg"
+	"syscall/js"
+)
+
+// grammars are the parsed grammars, indexed by their handle.
+var grammars []*cfg.CFG
+
+func main() {
+	js.Global().Set("cfg", js.ValueOf(map[string]any{
+		"parse":    function(parse),
+		"evaluate": function(evaluate),
+		"replay":   function(replay),
+	}))
+	// Keep the functions alive.
+	select {}
+}
+
+// function wraps a callback for JavaScript, errors and panics are returned as an object with an `error` property, so
+// a bad call does not bring down the module.
+func function(f func(args []js.Value) (any, error)) js.Func {
+	return js.FuncOf(func(_ js.Value, args []js.Value) (result any) {
+		defer func() {
+			if r := recover(); r != nil {
+				result = failure(fmt.Errorf("internal error: %v", r))
+			}
+		}()
+		v, err := f(args)
+		if err != nil {
+			return failure(err)
+		}
+		return v
+	})
+}
+
+// evaluate returns whether the grammar accepts the input, the indices of the productions of the derivation, and the
+// derivation as text.
+func evaluate(args []js.Value) (any, error) {
+	g, err := grammar(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	if args[1].Type() != js.TypeString {
+		return nil, fmt.Errorf("expected a string input, got %v", args[1].Type())
+	}
+	p, ok := g.Evaluate(args[1].String())
+	if !ok {
+		return map[string]any{"accepted": false}, nil
+	}
+	indices, err := rules(g, p)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"accepted": true, "path": indices, "derivation": p.Replay()}, nil
+}
+
+func failure(err error) any {
+	return map[string]any{"error": err.Error()}
+}
+
+// grammar returns the grammar of the handle in the first argument, after checking the number of arguments.
+func grammar(args []js.Value, n int) (*cfg.CFG, error) {
+	if len(args) != n {
+		return nil, fmt.Errorf("expected %d arguments, got %d", n, len(args))
+	}
+	if args[0].Type() != js.TypeNumber {
+		return nil, fmt.Errorf("invalid grammar handle")
+	}
+	i := args[0].Int()
+	if i < 0 || len(grammars) <= i {
+		return nil, fmt.Errorf("unknown grammar %d", i)
+	}
+	return grammars[i], nil
+}
+
+// parse parses a grammar in the text format and returns its handle, and the grammar in the text format.
+func parse(args []js.Value) (any, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+	if args[0].Type() != js.TypeString {
+		return nil, fmt.Errorf("expected a string grammar, got %v", args[0].Type())
+	}
+	g, err := cfg.Parse(args[0].String())
+	if err != nil {
+		return nil, err
+	}
+	grammars = append(grammars, g)
+	return map[string]any{"grammar": len(grammars) - 1, "text": g.Text()}, nil
+}
+
+// replay returns the sentential forms of the derivation given by the indices of its productions, which must form a
+// complete leftmost derivation from the start variable.
+func replay(args []js.Value) (any, error) {
+	g, err := grammar(args, 2)
+	if err != nil {
+		return nil, err
+	}
+	if !args[1].InstanceOf(js.Global().Get("Array")) {
+		return nil, fmt.Errorf("expected an array of production indices, got %v", args[1].Type())
+	}
+	var p cfg.Path
+	for i, n := 0, args[1].Length(); i < n; i++ {
+		index := args[1].Index(i)
+		if index.Type() != js.TypeNumber {
+			return nil, fmt.Errorf("expected a production index, got %v", index.Type())
+		}
+		j := index.Int()
+		if j < 0 || len(g.Rules) <= j {
+			return nil, fmt.Errorf("unknown production %d", j)
+		}
+		p = append(p, g.Rules[j])
+	}
+	if len(p) != 0 && p[0].A != g.StartVariable {
+		return nil, fmt.Errorf("expected a production of the start variable %v, got %v", g.StartVariable, p[0])
+	}
+	if _, err := p.Tree(); err != nil {
+		return nil, err
+	}
+	return map[string]any{"derivation": p.Replay()}, nil
+}
+
+// rules returns the indices of the productions of the path in the rules of the grammar.
+func rules(g *cfg.CFG, p cfg.Path) ([]any, error) {
+	indices := make([]any, len(p))
+	for i, production := range p {
+		indices[i] = -1
+		for j, rule := range g.Rules {
+			if rule.String() == production.String() {
+				indices[i] = j
+				break
+			}
+		}
+		if indices[i] == -1 {
+			return nil, fmt.Errorf("production %v not in the grammar", production)
+		}
+	}
+	return indices, nil
+}